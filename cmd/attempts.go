@@ -0,0 +1,160 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robertgumeny/doug/internal/git"
+	"github.com/robertgumeny/doug/internal/log"
+	"github.com/robertgumeny/doug/internal/state"
+)
+
+var attemptsFlags struct {
+	epic string
+}
+
+var attemptsCmd = &cobra.Command{
+	Use:   "attempts",
+	Short: "Inspect or restore rolled-back attempts",
+	Long:  "Every attempt doug rolls back is first saved under refs/doug/attempts/{epic}/{task}/{n}, or {n}-2, {n}-3, ... when attempt n was run more than once (after an interrupt or doug unblock). These commands list, show, diff and restore those snapshots without touching the branch; an attempt is named as listed, e.g. 3 or 3-2.",
+}
+
+var attemptsShowCmd = &cobra.Command{
+	Use:   "show <task-id> [attempt]",
+	Short: "List a task's saved attempts, or show one",
+	Args:  cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAttempts(func(projectRoot, epic string) error {
+			if len(args) == 1 {
+				return listAttempts(cmd.OutOrStdout(), projectRoot, epic, args[0])
+			}
+			ref, err := attemptRef(projectRoot, epic, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			out, err := git.ShowSnapshot(projectRoot, ref)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), out)
+			return err
+		})
+	},
+}
+
+var attemptsDiffCmd = &cobra.Command{
+	Use:   "diff <task-id> <attempt>",
+	Short: "Print the changes a saved attempt made",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAttempts(func(projectRoot, epic string) error {
+			ref, err := attemptRef(projectRoot, epic, args[0], args[1])
+			if err != nil {
+				return err
+			}
+			patch, err := git.DiffSnapshot(projectRoot, ref)
+			if err != nil {
+				return err
+			}
+			_, err = io.WriteString(cmd.OutOrStdout(), patch)
+			return err
+		})
+	},
+}
+
+var attemptsRestoreCmd = &cobra.Command{
+	Use:   "restore <task-id> <attempt>",
+	Short: "Apply a saved attempt's changes to the working tree",
+	Long:  "Apply the changes of a saved attempt to the working tree, uncommitted, so they can be finished and committed by hand. Nothing is written if the changes no longer apply cleanly.",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return withAttempts(func(projectRoot, epic string) error {
+			return restoreAttempt(projectRoot, epic, args[0], args[1])
+		})
+	},
+}
+
+func init() {
+	attemptsCmd.PersistentFlags().StringVar(&attemptsFlags.epic, "epic", "", "Epic the task belongs to (default: the current epic)")
+	attemptsCmd.AddCommand(attemptsShowCmd)
+	attemptsCmd.AddCommand(attemptsDiffCmd)
+	attemptsCmd.AddCommand(attemptsRestoreCmd)
+}
+
+// withAttempts resolves the project root and the epic (--epic, else the
+// current epic in project-state.yaml) and calls fn with them.
+func withAttempts(fn func(projectRoot, epic string) error) error {
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	epic := attemptsFlags.epic
+	if epic == "" {
+		st, err := state.LoadProjectState(filepath.Join(projectRoot, ".doug", "project-state.yaml"))
+		if err != nil {
+			return fmt.Errorf("load project state (or pass --epic): %w", err)
+		}
+		epic = st.CurrentEpic.ID
+	}
+	if epic == "" {
+		return errors.New("no current epic; pass --epic")
+	}
+	return fn(projectRoot, epic)
+}
+
+// listAttempts writes one line per saved attempt of taskID in epic.
+func listAttempts(w io.Writer, projectRoot, epic, taskID string) error {
+	snaps, err := git.ListSnapshots(projectRoot, epic, taskID)
+	if err != nil {
+		return err
+	}
+	if len(snaps) == 0 {
+		_, err := fmt.Fprintf(w, "no saved attempts for %s in epic %s\n", taskID, epic)
+		return err
+	}
+	for _, s := range snaps {
+		if _, err := fmt.Fprintf(w, "%s  %s  %s  %s\n", s.ID(), s.Hash[:12], s.Date, s.Subject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// attemptRef returns the ref of the attempt of taskID listed as n ("3" or
+// "3-2"), or an error when n is malformed or no such attempt was saved.
+func attemptRef(projectRoot, epic, taskID, n string) (string, error) {
+	attempt, _, _ := strings.Cut(n, "-")
+	if a, err := strconv.Atoi(attempt); err != nil || a < 1 {
+		return "", fmt.Errorf("invalid attempt %q: must be a positive number, optionally with a -N suffix", n)
+	}
+	snaps, err := git.ListSnapshots(projectRoot, epic, taskID)
+	if err != nil {
+		return "", err
+	}
+	for _, s := range snaps {
+		if s.ID() == n {
+			return s.Ref, nil
+		}
+	}
+	return "", fmt.Errorf("no saved attempt %s for task %s in epic %s (see doug attempts show %s)", n, taskID, epic, taskID)
+}
+
+// restoreAttempt applies attempt n of taskID to the working tree.
+func restoreAttempt(projectRoot, epic, taskID, n string) error {
+	ref, err := attemptRef(projectRoot, epic, taskID, n)
+	if err != nil {
+		return err
+	}
+	if err := git.RestoreSnapshot(projectRoot, ref); err != nil {
+		return err
+	}
+	log.Success(fmt.Sprintf("restored %s attempt %s into the working tree (uncommitted)", taskID, n))
+	return nil
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robertgumeny/doug/internal/git"
+)
+
+func TestAttempts_ListAndRestore(t *testing.T) {
+	dir, _ := setupBacklogProject(t)
+	if err := os.WriteFile(filepath.Join(dir, "feature.go"), []byte("package feature\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ref := git.AttemptRef("EPIC-1", "EPIC-1-001", 3)
+	if _, _, err := git.SnapshotWorkingTree(dir, ref, "doug: EPIC-1-001 attempt 3 rolled back: test verification failed"); err != nil {
+		t.Fatalf("SnapshotWorkingTree: %v", err)
+	}
+	if err := git.RollbackChanges(dir, nil, nil); err != nil {
+		t.Fatalf("RollbackChanges: %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := listAttempts(&buf, dir, "EPIC-1", "EPIC-1-001"); err != nil {
+		t.Fatalf("listAttempts: %v", err)
+	}
+	if !strings.HasPrefix(buf.String(), "3  ") || !strings.Contains(buf.String(), "test verification failed") {
+		t.Errorf("unexpected listing:\n%s", buf.String())
+	}
+
+	if err := restoreAttempt(dir, "EPIC-1", "EPIC-1-001", "2"); err == nil || !strings.Contains(err.Error(), "no saved attempt 2") {
+		t.Errorf("expected missing-attempt error, got: %v", err)
+	}
+	if err := restoreAttempt(dir, "EPIC-1", "EPIC-1-001", "3"); err != nil {
+		t.Fatalf("restoreAttempt: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "feature.go"))
+	if err != nil || string(data) != "package feature\n" {
+		t.Errorf("feature.go not restored: %q, %v", data, err)
+	}
+}
+
+func TestAttempts_ListEmpty(t *testing.T) {
+	dir, _ := setupBacklogProject(t)
+	var buf bytes.Buffer
+	if err := listAttempts(&buf, dir, "EPIC-1", "EPIC-1-002"); err != nil {
+		t.Fatalf("listAttempts: %v", err)
+	}
+	if !strings.Contains(buf.String(), "no saved attempts for EPIC-1-002") {
+		t.Errorf("unexpected listing: %q", buf.String())
+	}
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/robertgumeny/doug/internal/git"
+	"github.com/robertgumeny/doug/internal/log"
+	"github.com/robertgumeny/doug/internal/orchestrator"
+	"github.com/robertgumeny/doug/internal/state"
+	"github.com/robertgumeny/doug/internal/types"
+)
+
+// epicBacklog moves doug run on to the next epic queued in .doug/epics/ once
+// the current epic is complete, so a series of epics runs unattended.
+type epicBacklog struct {
+	dir         string // .doug/epics/
+	historyDir  string // .doug/history/
+	tasksPath   string
+	statePath   string
+	projectRoot string
+	kbEnabled   bool
+	branchTmpl  string // branch_template from doug.yaml
+}
+
+// advance rolls st over from the completed epic in current to the first epic
+// in the backlog and returns that epic's tasks, or nil when the backlog is
+// empty.
+//
+// Sequence:
+//  1. Pick the first backlog file, discarding files for an epic that already
+//     ran (the current epic, or one archived in history).
+//  2. Validate its task types and dependencies; a bad file stops the run and
+//     is left in place to be fixed.
+//  3. Archive the finished epic's metrics and task list to history, then
+//     reset st with PrepareForEpicRollover.
+//  4. Install the new epic as tasks.yaml and remove the backlog file.
+//  5. Bootstrap the new epic, align its task pointers, save state, and check
+//     out its branch.
+//
+// completed_at is set on the finished epic if it is still empty, since the
+// caller only advances once the epic has no remaining work.
+func (b epicBacklog) advance(st *types.ProjectState, current *types.Tasks) (*types.Tasks, error) {
+	path, next, err := b.peek(st, current)
+	if err != nil || next == nil {
+		return nil, err
+	}
+	if err := orchestrator.ValidateTaskTypes(next); err != nil {
+		return nil, fmt.Errorf("backlog epic %s: %w", path, err)
+	}
+	if err := orchestrator.ValidateTaskDependencies(next); err != nil {
+		return nil, fmt.Errorf("backlog epic %s: %w", path, err)
+	}
+
+	if st.CurrentEpic.CompletedAt == nil || *st.CurrentEpic.CompletedAt == "" {
+		now := time.Now().UTC().Format(time.RFC3339)
+		st.CurrentEpic.CompletedAt = &now
+	}
+	finished := types.EpicHistory{Epic: st.CurrentEpic, Metrics: st.Metrics}
+	if err := state.SaveEpicHistory(b.historyDir, &finished); err != nil {
+		return nil, fmt.Errorf("archive metrics for epic %s: %w", finished.Epic.ID, err)
+	}
+	if err := b.archiveTasks(current); err != nil {
+		return nil, err
+	}
+	if _, err := orchestrator.PrepareForEpicRollover(st, next); err != nil {
+		return nil, fmt.Errorf("epic rollover blocked: %w", err)
+	}
+
+	if err := state.SaveTasks(b.tasksPath, next); err != nil {
+		return nil, fmt.Errorf("install epic %s as tasks.yaml: %w", next.Epic.ID, err)
+	}
+	if err := os.Remove(path); err != nil {
+		return nil, fmt.Errorf("remove backlog file %s: %w", path, err)
+	}
+
+	if err := orchestrator.BootstrapFromTasks(st, next, b.branchTmpl); err != nil {
+		return nil, fmt.Errorf("bootstrap epic %s: %w", next.Epic.ID, err)
+	}
+	orchestrator.InitializeTaskPointers(st, next, b.kbEnabled)
+	if err := orchestrator.ValidateYAMLStructure(st, next); err != nil {
+		return nil, fmt.Errorf("YAML structure invalid: %w", err)
+	}
+	if err := state.SaveProjectState(b.statePath, st); err != nil {
+		return nil, fmt.Errorf("save project state for epic %s: %w", next.Epic.ID, err)
+	}
+	if err := git.EnsureEpicBranch(st.CurrentEpic.BranchName, b.projectRoot); err != nil {
+		return nil, fmt.Errorf("ensure epic branch: %w", err)
+	}
+
+	log.SetTaskContext(log.TaskContext{EpicID: st.CurrentEpic.ID})
+	log.Section(fmt.Sprintf("EPIC %s — %s", st.CurrentEpic.ID, st.CurrentEpic.Name))
+	log.Info(fmt.Sprintf("archived epic %s; starting epic %s from %s", finished.Epic.ID, next.Epic.ID, path))
+	return next, nil
+}
+
+// peek returns the first backlog file and its tasks, or an empty path and nil
+// tasks when nothing is queued. Files for an epic that already ran are left
+// over from an interrupted rollover; they are removed with a warning.
+func (b epicBacklog) peek(st *types.ProjectState, current *types.Tasks) (string, *types.Tasks, error) {
+	paths, err := state.ListBacklog(b.dir)
+	if err != nil {
+		return "", nil, fmt.Errorf("list epic backlog: %w", err)
+	}
+	history, err := state.LoadEpicHistory(b.historyDir)
+	if err != nil {
+		return "", nil, fmt.Errorf("load epic history: %w", err)
+	}
+	ran := map[string]bool{st.CurrentEpic.ID: true, current.Epic.ID: true}
+	for _, h := range history {
+		ran[h.Epic.ID] = true
+	}
+
+	for _, path := range paths {
+		next, err := state.LoadTasks(path)
+		if err != nil {
+			return "", nil, fmt.Errorf("load backlog epic: %w", err)
+		}
+		if next.Epic.ID == "" {
+			return "", nil, fmt.Errorf("backlog epic %s has no epic.id", path)
+		}
+		if ran[next.Epic.ID] {
+			log.Warning(fmt.Sprintf("removing backlog file %s: epic %s has already run", path, next.Epic.ID))
+			if err := os.Remove(path); err != nil {
+				return "", nil, fmt.Errorf("remove backlog file %s: %w", path, err)
+			}
+			continue
+		}
+		return path, next, nil
+	}
+	return "", nil, nil
+}
+
+// archiveTasks keeps the finished epic's task list as
+// {historyDir}/tasks/{epic}.yaml, since tasks.yaml is about to be replaced.
+func (b epicBacklog) archiveTasks(tasks *types.Tasks) error {
+	dir := filepath.Join(b.historyDir, "tasks")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create task archive directory %s: %w", dir, err)
+	}
+	if err := state.SaveTasks(filepath.Join(dir, tasks.Epic.ID+".yaml"), tasks); err != nil {
+		return fmt.Errorf("archive tasks for epic %s: %w", tasks.Epic.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robertgumeny/doug/internal/config"
+	"github.com/robertgumeny/doug/internal/state"
+	"github.com/robertgumeny/doug/internal/types"
+)
+
+const backlogStateYAML = `current_epic:
+  id: EPIC-1
+  name: First Epic
+  branch_name: feature/EPIC-1
+  started_at: "2026-03-01T00:00:00Z"
+  completed_at: "2026-03-02T00:00:00Z"
+active_task:
+  type: documentation
+  id: KB_UPDATE
+metrics:
+  total_tasks_completed: 1
+  total_duration_seconds: 60
+  tasks:
+    - task_id: EPIC-1-001
+      outcome: success
+      duration_seconds: 60
+      completed_at: "2026-03-01T00:01:00Z"
+`
+
+const backlogTasksYAML = `epic:
+  id: EPIC-1
+  name: First Epic
+  tasks:
+    - id: EPIC-1-001
+      type: feature
+      status: DONE
+`
+
+const backlogEpic2YAML = `epic:
+  id: EPIC-2
+  name: Second Epic
+  tasks:
+    - id: EPIC-2-001
+      type: feature
+      status: TODO
+    - id: EPIC-2-002
+      type: feature
+      status: TODO
+      depends_on: [EPIC-2-001]
+`
+
+// setupBacklogProject returns a git repository holding a completed EPIC-1 and
+// a backlog containing a stale copy of EPIC-1 followed by EPIC-2.
+func setupBacklogProject(t *testing.T) (string, epicBacklog) {
+	t.Helper()
+	dir := setupStatusProject(t, backlogStateYAML, backlogTasksYAML)
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "feature/EPIC-1"},
+		{"config", "user.email", "test@example.com"},
+		{"config", "user.name", "Test"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		c := exec.Command("git", args...)
+		c.Dir = dir
+		if out, err := c.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+
+	epicsDir := filepath.Join(dir, ".doug", "epics")
+	if err := os.MkdirAll(epicsDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(epicsDir, "01-first.yaml"), []byte(backlogTasksYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(epicsDir, "02-second.yaml"), []byte(backlogEpic2YAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir, epicBacklog{
+		dir:         epicsDir,
+		historyDir:  filepath.Join(dir, ".doug", "history"),
+		tasksPath:   filepath.Join(dir, ".doug", "tasks.yaml"),
+		statePath:   filepath.Join(dir, ".doug", "project-state.yaml"),
+		projectRoot: dir,
+		kbEnabled:   true,
+		branchTmpl:  config.DefaultBranchTemplate,
+	}
+}
+
+func TestEpicBacklogAdvance_RollsOverToNextEpic(t *testing.T) {
+	dir, backlog := setupBacklogProject(t)
+	st, err := state.LoadProjectState(backlog.statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := state.LoadTasks(backlog.tasksPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := backlog.advance(st, current)
+	if err != nil {
+		t.Fatalf("advance: %v", err)
+	}
+	if next == nil || next.Epic.ID != "EPIC-2" {
+		t.Fatalf("next = %+v, want EPIC-2", next)
+	}
+
+	// State is reset and bootstrapped for the new epic.
+	if st.CurrentEpic.ID != "EPIC-2" || st.CurrentEpic.BranchName != "feature/EPIC-2" || st.CurrentEpic.CompletedAt != nil {
+		t.Errorf("CurrentEpic = %+v, want a fresh EPIC-2", st.CurrentEpic)
+	}
+	if st.ActiveTask.ID != "EPIC-2-001" || st.NextTask.ID != "EPIC-2-002" {
+		t.Errorf("pointers = %+v / %+v, want EPIC-2-001 then EPIC-2-002", st.ActiveTask, st.NextTask)
+	}
+	if len(st.Metrics.Tasks) != 0 {
+		t.Errorf("Metrics = %+v, want reset", st.Metrics)
+	}
+	saved, err := state.LoadProjectState(backlog.statePath)
+	if err != nil || saved.CurrentEpic.ID != "EPIC-2" {
+		t.Errorf("saved state epic = %v (%v), want EPIC-2", saved, err)
+	}
+
+	// tasks.yaml now holds EPIC-2 and the backlog is drained.
+	installed, err := state.LoadTasks(backlog.tasksPath)
+	if err != nil || installed.Epic.ID != "EPIC-2" {
+		t.Errorf("tasks.yaml epic = %v (%v), want EPIC-2", installed, err)
+	}
+	if left, _ := state.ListBacklog(backlog.dir); len(left) != 0 {
+		t.Errorf("backlog still holds %v", left)
+	}
+
+	// The finished epic is archived with its metrics and task list.
+	history, err := state.LoadEpicHistory(backlog.historyDir)
+	if err != nil || len(history) != 1 || history[0].Epic.ID != "EPIC-1" || len(history[0].Metrics.Tasks) != 1 {
+		t.Errorf("history = %+v (%v), want archived EPIC-1 with its metrics", history, err)
+	}
+	if _, err := state.LoadTasks(filepath.Join(backlog.historyDir, "tasks", "EPIC-1.yaml")); err != nil {
+		t.Errorf("archived task list: %v", err)
+	}
+
+	out, err := exec.Command("git", "-C", dir, "branch", "--show-current").Output()
+	if err != nil || strings.TrimSpace(string(out)) != "feature/EPIC-2" {
+		t.Errorf("current branch = %q (%v), want feature/EPIC-2", out, err)
+	}
+}
+
+func TestEpicBacklogAdvance_EmptyBacklog(t *testing.T) {
+	_, backlog := setupBacklogProject(t)
+	backlog.dir = filepath.Join(t.TempDir(), "missing")
+	st, err := state.LoadProjectState(backlog.statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	next, err := backlog.advance(st, &types.Tasks{Epic: types.EpicDefinition{ID: "EPIC-1"}})
+	if err != nil || next != nil {
+		t.Fatalf("advance = %v, %v; want nil, nil", next, err)
+	}
+	if st.CurrentEpic.ID != "EPIC-1" {
+		t.Errorf("state changed with an empty backlog: %+v", st.CurrentEpic)
+	}
+}
+
+func TestEpicBacklogAdvance_InvalidEpicStopsRun(t *testing.T) {
+	_, backlog := setupBacklogProject(t)
+	bad := strings.Replace(backlogEpic2YAML, "depends_on: [EPIC-2-001]", "depends_on: [EPIC-9-999]", 1)
+	if err := os.WriteFile(filepath.Join(backlog.dir, "02-second.yaml"), []byte(bad), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	st, err := state.LoadProjectState(backlog.statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := state.LoadTasks(backlog.tasksPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := backlog.advance(st, current); err == nil {
+		t.Fatal("expected an error for an invalid backlog epic")
+	}
+	if st.CurrentEpic.ID != "EPIC-1" {
+		t.Errorf("state rolled over despite the invalid epic: %+v", st.CurrentEpic)
+	}
+	if _, err := os.Stat(filepath.Join(backlog.dir, "02-second.yaml")); err != nil {
+		t.Errorf("invalid backlog file should be left in place: %v", err)
+	}
+}
@@ -33,7 +33,7 @@ var initCmd = &cobra.Command{
 
 func init() {
 	initCmd.Flags().BoolVar(&initFlags.force, "force", false, "Overwrite existing files")
-	initCmd.Flags().StringVar(&initFlags.buildSystem, "build-system", "", "Build system to use (go|npm); auto-detected if not set")
+	initCmd.Flags().StringVar(&initFlags.buildSystem, "build-system", "", "Build system to use (go|npm|cargo|python|command); auto-detected if not set")
 	initCmd.Flags().StringVar(&initFlags.agents, "agents", "", "Comma-separated agent names to install skills for (e.g. claude,codex)")
 }
 
@@ -134,9 +134,9 @@ func initProject(dir string, force bool, buildSystem string, selectedAgents []st
 	// Validate explicit build system flag.
 	if buildSystem != "" {
 		switch bs {
-		case "go", "npm":
+		case "go", "npm", "cargo", "python", "command":
 		default:
-			return fmt.Errorf("unsupported build system %q: must be one of: go, npm", bs)
+			return fmt.Errorf("unsupported build system %q: must be one of: go, npm, cargo, python, command", bs)
 		}
 	}
 
@@ -579,19 +579,60 @@ func mergeCodexConfigTOML(existing string) string {
 }
 
 // dougYAMLContent returns the .doug/doug.yaml file content with inline YAML comments
-// and the detected (or specified) build system pre-filled.
+// and the detected (or specified) build system pre-filled. The "command" build
+// system also gets a build_commands block to fill in, and "python" gets an
+// optional python_type_check line.
 func dougYAMLContent(buildSystem string) string {
-	return fmt.Sprintf(`# doug.yaml — orchestrator configuration
+	content := fmt.Sprintf(`# doug.yaml — orchestrator configuration
 # See https://github.com/robertgumeny/doug for documentation.
 agent_command: 'claude -p "[DOUG_TASK_ID: {{task_id}}] Please activate {{skill_name}} and complete the task described in .doug/ACTIVE_TASK.md"' # Command used to invoke the agent (e.g. claude, codex, gemini, etc.)
 # agent_command: codex exec "[DOUG_TASK_ID: {{task_id}}] Please activate {{skill_name}} and complete the task described in .doug/ACTIVE_TASK.md"
 # agent_command: gemini --approval-mode auto_edit --output-format json --sandbox "[DOUG_TASK_ID: {{task_id}}] Please activate {{skill_name}} and complete the task described in .doug/ACTIVE_TASK.md"
-build_system: %s # Build system: go | npm (auto-detected by init; override here)
+build_system: %s # Build system: go | npm | cargo | python | command (auto-detected by init; override here)
 max_retries: 3 # Max FAILURE outcomes before a task is BLOCKED
 max_iterations: 10 # Max loop iterations before the run exits
 kb_enabled: true # If false, skip KB synthesis task after features complete
 agent_heartbeat_seconds: 30 # Periodic liveness log cadence while agent runs (0 disables)
+agent_timeout_seconds: 0 # Kill the agent after this many seconds and count a FAILURE (0 disables)
+transcript_strip_ansi: true # Remove color/escape codes from the per-attempt agent transcript
+transcript_max_bytes: 10485760 # Cap each agent transcript at this many bytes (0 disables the cap)
+lint: off # Lint stage after tests: off | warn (log failures) | enforce (roll back and retry)
+lint_command: "" # Optional linter replacing the build system default (e.g. golangci-lint run)
 `, buildSystem)
+	if buildSystem == "python" {
+		content += `python_type_check: "" # Optional type-check command run after byte-compilation (e.g. mypy src)
+`
+	}
+	if buildSystem == "command" {
+		content += `build_commands: # Command lines run without a shell (quotes respected; no pipes or globs)
+  install: make deps # Optional: install dependencies (skipped when empty)
+  build: make build # Required: compile the project
+  test: make test # Required: run the test suite
+  lint: "" # Optional: run a linter (skipped when empty)
+  initialized_when: "" # Optional: file that exists once dependencies are installed (empty = always initialized)
+`
+	}
+	content += `# hooks: # Commands run at lifecycle events with a JSON payload on stdin
+#   task_done: # pre_task | post_agent | task_done | task_retry | task_blocked | bug_scheduled | epic_complete
+#     - command: ./scripts/notify.sh
+#       timeout_seconds: 60 # Kill the hook after this many seconds
+#       on_failure: warn # warn (log and continue) | abort (stop the run)
+# webhook: # POST run events as signed JSON (secret read from $DOUG_WEBHOOK_SECRET)
+#   url: https://hooks.example.com/doug
+#   events: [task_done, task_blocked, epic_complete, run_aborted] # Empty = all
+#   max_attempts: 3 # Deliveries per event; 5xx, 429 and network errors are retried with backoff
+#   deadline_seconds: 10 # Never spend longer than this on one event
+# on_epic_complete: # Integrate feature/{epic} once the epic is finalized
+#   strategy: leave # leave | merge (--no-ff) | squash (message from CHANGELOG.md) | ff
+#   base_branch: main # Required unless strategy is leave
+# branch_template: "feature/{{.Epic}}" # text/template for epic branches (.Epic, .EpicName; lower, upper, slug)
+# commit_template: "{{.Kind}}: {{with .Task}}{{.}}{{else}}finalize {{.Epic}}{{end}}" # Also .Type, .Description, .Changelog, .Attempt, .Branch, .Base
+# rollback: # Extra paths kept when a rejected attempt is rolled back (.doug/, docs/kb/, .env, *.backup always are)
+#   protect: ["testdata/*.db"] # Globs of files (tracked or not) whose contents survive the reset
+#   preserve_untracked: [.envrc, .idea/] # gitignore-style patterns git clean leaves alone
+# result_sources: [frontmatter, json, stdout] # Where the agent's result is read from, first valid one wins
+`
+	return content
 }
 
 // tasksYAMLContent returns a starter tasks.yaml with one example epic and two tasks,
@@ -170,6 +170,40 @@ func TestInitProject_DetectsBuildSystem(t *testing.T) {
 		}
 	})
 
+	t.Run("Cargo.toml → build_system: cargo", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "Cargo.toml"), []byte("[package]\nname = \"example\"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := initProject(dir, false, "", []string{"claude"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, ".doug", "doug.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "build_system: cargo") {
+			t.Errorf(".doug/doug.yaml does not contain 'build_system: cargo'; content:\n%s", data)
+		}
+	})
+
+	t.Run("pyproject.toml → build_system: python", func(t *testing.T) {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "pyproject.toml"), []byte("[project]\nname = \"example\"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := initProject(dir, false, "", []string{"claude"}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, ".doug", "doug.yaml"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "build_system: python") || !strings.Contains(string(data), "python_type_check:") {
+			t.Errorf(".doug/doug.yaml does not configure the python build system; content:\n%s", data)
+		}
+	})
+
 	t.Run("no marker → default build_system: go", func(t *testing.T) {
 		dir := t.TempDir()
 		if err := initProject(dir, false, "", []string{"claude"}); err != nil {
@@ -362,6 +396,18 @@ func TestDougYAMLContent_HasInlineComments(t *testing.T) {
 	}
 }
 
+func TestDougYAMLContent_CommandBuildSystemHasBuildCommands(t *testing.T) {
+	content := dougYAMLContent("command")
+	for _, field := range []string{"build_system: command", "build_commands:", "build:", "test:", "initialized_when:"} {
+		if !strings.Contains(content, field) {
+			t.Errorf("doug.yaml content missing %q", field)
+		}
+	}
+	if strings.Contains(dougYAMLContent("go"), "build_commands:") {
+		t.Error("build_commands block should only be written for the command build system")
+	}
+}
+
 func TestDougYAMLContent_HasCommentedAgentExamples(t *testing.T) {
 	content := dougYAMLContent("go")
 
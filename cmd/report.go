@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robertgumeny/doug/internal/metrics"
+	"github.com/robertgumeny/doug/internal/state"
+)
+
+var reportFlags struct {
+	format string
+	epic   string
+}
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "Report task metrics across epics",
+	Long:  "Summarize per-task duration, attempts, outcome mix and retry rate for the current epic and every epic archived under .doug/history/. Never modifies state.",
+	Args:  cobra.NoArgs,
+	RunE:  runReport,
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&reportFlags.format, "format", metrics.FormatMarkdown, "Output format: json, csv or markdown")
+	reportCmd.Flags().StringVar(&reportFlags.epic, "epic", "", "Only report the epic with this ID")
+}
+
+func runReport(cmd *cobra.Command, args []string) error {
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+
+	report, err := buildMetricsReport(projectRoot, reportFlags.epic)
+	if err != nil {
+		return err
+	}
+	return metrics.WriteReport(cmd.OutOrStdout(), report, reportFlags.format)
+}
+
+// buildMetricsReport loads the epic archives and current project state from
+// projectRoot and summarizes them. A non-empty epicID restricts the report to
+// that epic and is an error when no such epic has metrics.
+func buildMetricsReport(projectRoot, epicID string) (metrics.Report, error) {
+	dougDir := filepath.Join(projectRoot, ".doug")
+
+	history, err := state.LoadEpicHistory(filepath.Join(dougDir, "history"))
+	if err != nil {
+		return metrics.Report{}, fmt.Errorf("load epic history: %w", err)
+	}
+	// Archives alone are still worth reporting, so a missing state file is
+	// treated as "no current epic".
+	projectState, err := state.LoadProjectState(filepath.Join(dougDir, "project-state.yaml"))
+	if err != nil && !errors.Is(err, state.ErrNotFound) {
+		return metrics.Report{}, fmt.Errorf("load project state: %w", err)
+	}
+
+	report := metrics.BuildReport(history, projectState)
+	if epicID == "" {
+		return report, nil
+	}
+	for _, e := range report.Epics {
+		if e.ID == epicID {
+			return metrics.Report{Epics: []metrics.EpicReport{e}}, nil
+		}
+	}
+	return metrics.Report{}, fmt.Errorf("no metrics recorded for epic %q", epicID)
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/robertgumeny/doug/internal/state"
+	"github.com/robertgumeny/doug/internal/types"
+)
+
+func TestBuildMetricsReport_IncludesArchivedEpics(t *testing.T) {
+	dir := setupStatusProject(t, statusStateYAML, statusTasksYAML)
+	completed := "2026-02-20T00:00:00Z"
+	archived := &types.EpicHistory{
+		Epic: types.EpicState{ID: "EPIC-0", StartedAt: "2026-02-01T00:00:00Z", CompletedAt: &completed},
+		Metrics: types.Metrics{Tasks: []types.TaskMetric{
+			{TaskID: "EPIC-0-001", Outcome: "success", DurationSeconds: 60},
+		}},
+	}
+	if err := state.SaveEpicHistory(filepath.Join(dir, ".doug", "history"), archived); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := buildMetricsReport(dir, "")
+	if err != nil {
+		t.Fatalf("buildMetricsReport: %v", err)
+	}
+	if len(report.Epics) != 2 || report.Epics[0].ID != "EPIC-0" || report.Epics[1].ID != "EPIC-1" {
+		t.Fatalf("epics = %+v, want EPIC-0 then EPIC-1", report.Epics)
+	}
+
+	report, err = buildMetricsReport(dir, "EPIC-1")
+	if err != nil {
+		t.Fatalf("buildMetricsReport(EPIC-1): %v", err)
+	}
+	if len(report.Epics) != 1 || report.Epics[0].Tasks[0].ID != "EPIC-1-001" {
+		t.Errorf("filtered epics = %+v, want only EPIC-1", report.Epics)
+	}
+
+	if _, err := buildMetricsReport(dir, "EPIC-9"); err == nil {
+		t.Error("expected an error for an epic with no metrics")
+	}
+}
+
+func TestBuildMetricsReport_MissingStateFile(t *testing.T) {
+	dir := setupStatusProject(t, statusStateYAML, statusTasksYAML)
+	if err := os.Remove(filepath.Join(dir, ".doug", "project-state.yaml")); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := buildMetricsReport(dir, "")
+	if err != nil {
+		t.Fatalf("buildMetricsReport: %v", err)
+	}
+	if len(report.Epics) != 0 {
+		t.Errorf("epics = %+v, want none", report.Epics)
+	}
+}
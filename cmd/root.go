@@ -1,23 +1,64 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"runtime/debug"
 
 	"github.com/spf13/cobra"
+
+	"github.com/robertgumeny/doug/internal/log"
 )
 
 var version = "dev"
 
+// exitCodeInterrupted is the process exit code when doug run stops because of
+// SIGINT or SIGTERM (128 + SIGINT, the shell convention for Ctrl-C).
+const exitCodeInterrupted = 130
+
+// errInterrupted marks errors caused by a signal so Execute can exit with
+// exitCodeInterrupted instead of 1.
+var errInterrupted = errors.New("interrupted")
+
+// logFormatEnv names the environment variable that selects the log format
+// when --log-format is not given.
+const logFormatEnv = "DOUG_LOG_FORMAT"
+
+// logFormat holds the --log-format flag value, shared by every subcommand.
+var logFormat string
+
 var rootCmd = &cobra.Command{
 	Use:   "doug",
 	Short: "doug is a task automation CLI",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return configureLogging(cmd)
+	},
+}
+
+// configureLogging applies --log-format (falling back to DOUG_LOG_FORMAT,
+// then text) and disables colors when stdout is not a terminal or NO_COLOR
+// is set.
+func configureLogging(cmd *cobra.Command) error {
+	value := os.Getenv(logFormatEnv)
+	if cmd.Flags().Changed("log-format") {
+		value = logFormat
+	}
+	format, err := log.ParseFormat(value)
+	if err != nil {
+		return err
+	}
+	log.SetFormat(format)
+	log.SetColor(log.ColorSupported())
+	return nil
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
+		if errors.Is(err, errInterrupted) {
+			os.Exit(exitCodeInterrupted)
+		}
 		os.Exit(1)
 	}
 }
@@ -34,7 +75,12 @@ func init() {
 	rootCmd.Version = version
 	rootCmd.InitDefaultVersionFlag()
 	rootCmd.Flags().Lookup("version").Shorthand = "v"
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "", "log output format: text or json (default $"+logFormatEnv+", else text)")
 	rootCmd.AddCommand(runCmd)
 	rootCmd.AddCommand(initCmd)
 	rootCmd.AddCommand(switchCmd)
+	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(unblockCmd)
+	rootCmd.AddCommand(attemptsCmd)
 }
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robertgumeny/doug/internal/log"
+)
+
+// loggingTestCmd returns a command carrying the --log-format flag, as
+// subcommands inherit it from rootCmd.
+func loggingTestCmd() *cobra.Command {
+	c := &cobra.Command{Use: "test"}
+	c.Flags().StringVar(&logFormat, "log-format", "", "")
+	return c
+}
+
+// captureLog runs log.Info(msg) and returns what it printed.
+func captureLog(t *testing.T, msg string) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("pipe: %v", err)
+	}
+	old := os.Stdout
+	os.Stdout = w
+	log.Info(msg)
+	w.Close()
+	os.Stdout = old
+	var buf bytes.Buffer
+	io.Copy(&buf, r) //nolint:errcheck
+	return buf.String()
+}
+
+func TestConfigureLogging(t *testing.T) {
+	defer log.SetFormat(log.FormatText)
+	defer log.SetColor(true)
+
+	t.Run("env selects json", func(t *testing.T) {
+		t.Setenv(logFormatEnv, "json")
+		if err := configureLogging(loggingTestCmd()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if out := captureLog(t, "hello"); !strings.HasPrefix(out, "{") {
+			t.Errorf("expected JSON output, got %q", out)
+		}
+	})
+
+	t.Run("flag overrides env", func(t *testing.T) {
+		t.Setenv(logFormatEnv, "json")
+		c := loggingTestCmd()
+		if err := c.Flags().Set("log-format", "text"); err != nil {
+			t.Fatal(err)
+		}
+		if err := configureLogging(c); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		// stdout is not a terminal under go test, so colors are off too.
+		if out := captureLog(t, "hello"); out != "[INFO] hello\n" {
+			t.Errorf("expected plain text output, got %q", out)
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		t.Setenv(logFormatEnv, "yaml")
+		if err := configureLogging(loggingTestCmd()); err == nil {
+			t.Error("expected error for invalid DOUG_LOG_FORMAT")
+		}
+	})
+}
@@ -3,9 +3,13 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -16,6 +20,7 @@ import (
 	"github.com/robertgumeny/doug/internal/git"
 	"github.com/robertgumeny/doug/internal/handlers"
 	"github.com/robertgumeny/doug/internal/log"
+	"github.com/robertgumeny/doug/internal/notify"
 	"github.com/robertgumeny/doug/internal/orchestrator"
 	"github.com/robertgumeny/doug/internal/state"
 	"github.com/robertgumeny/doug/internal/types"
@@ -30,6 +35,11 @@ var runFlags struct {
 	maxIterations         int
 	kbEnabled             bool
 	agentHeartbeatSeconds int
+	agentTimeoutSeconds   int
+	keepChanges           bool
+	lint                  string
+	stash                 bool
+	commitBaseline        bool
 }
 
 var runCmd = &cobra.Command{
@@ -41,36 +51,56 @@ var runCmd = &cobra.Command{
 
 func init() {
 	runCmd.Flags().StringVar(&runFlags.agentCommand, "agent", "", "override agent_command from doug.yaml")
-	runCmd.Flags().StringVar(&runFlags.buildSystem, "build-system", "", "override build_system from doug.yaml (go|npm)")
+	runCmd.Flags().StringVar(&runFlags.buildSystem, "build-system", "", "override build_system from doug.yaml (go|npm|cargo|python|command)")
 	runCmd.Flags().IntVar(&runFlags.maxRetries, "max-retries", 0, "override max_retries from doug.yaml")
 	runCmd.Flags().IntVar(&runFlags.maxIterations, "max-iterations", 0, "override max_iterations from doug.yaml")
 	runCmd.Flags().BoolVar(&runFlags.kbEnabled, "kb-enabled", false, "override kb_enabled from doug.yaml")
 	runCmd.Flags().IntVar(&runFlags.agentHeartbeatSeconds, "agent-heartbeat-seconds", 0, "override agent_heartbeat_seconds from doug.yaml (0 disables heartbeat)")
+	runCmd.Flags().StringVar(&runFlags.lint, "lint", "", "override lint from doug.yaml (off|warn|enforce)")
+	runCmd.Flags().BoolVar(&runFlags.keepChanges, "keep-changes", false, "on SIGINT/SIGTERM, leave the agent's uncommitted changes in place instead of rolling back")
+	runCmd.Flags().IntVar(&runFlags.agentTimeoutSeconds, "agent-timeout-seconds", 0, "override agent_timeout_seconds from doug.yaml and tasks.yaml (0 disables the timeout)")
+	runCmd.Flags().BoolVar(&runFlags.stash, "stash", false, "stash uncommitted changes outside .doug/ before the run and restore them when doug exits")
+	runCmd.Flags().BoolVar(&runFlags.commitBaseline, "commit-baseline", false, "commit uncommitted changes outside .doug/ as a baseline before the run")
+	runCmd.MarkFlagsMutuallyExclusive("stash", "commit-baseline")
 }
 
 // runOrchestrate implements the full orchestration loop for the "run" subcommand.
 //
 // Pre-loop sequence:
 //  1. Load config from .doug/doug.yaml; apply any CLI flag overrides.
-//  2. CheckDependencies — verify agent binary, git, and toolchain are on PATH.
+//  2. CheckDependencies — verify agent binary, git, and toolchain are on PATH;
+//     then GuardWorkingTree — refuse to start on uncommitted changes outside
+//     .doug/ unless --stash or --commit-baseline says what to do with them.
 //  3. Load .doug/project-state.yaml and .doug/tasks.yaml from the working directory.
 //  4. BootstrapFromTasks — no-op if already bootstrapped; initializes state on first run.
-//  5. IsEpicAlreadyComplete — exit 0 immediately if all work is done.
+//  5. IsEpicAlreadyComplete — move on to the next epic in .doug/epics/, or
+//     exit 0 immediately if all work is done and the backlog is empty.
 //  6. EnsureProjectReady — pre-flight build/test (skipped when project not initialized).
-//  7. ValidateYAMLStructure — fail fast on structurally corrupt state.
+//  7. ValidateYAMLStructure / ValidateTaskDependencies — fail fast on
+//     structurally corrupt state or an invalid depends_on graph.
 //  8. EnsureEpicBranch — check out the feature branch (create if needed).
-//  9. InitializeTaskPointers — align active/next task with task list status.
+//  9. InitializeTaskPointers — align active/next task with task list status;
+//     exit for manual review if nothing can run because tasks are BLOCKED.
 // 10. ValidateStateSync — catch state/task drift (skipped for synthetic tasks).
 // 11. Persist state before entering the loop.
 //
 // Main loop (up to cfg.MaxIterations):
 //   - IncrementAttempts at the START of each iteration (before agent invocation).
-//   - CreateSessionFile → WriteActiveTask → RunAgent → ParseSessionResult.
+//   - CreateSessionFile → WriteActiveTask → pre_task hook → RunAgent →
+//     ReadSessionResult (result_sources, in order) → post_agent hook.
 //   - Dispatch to HandleSuccess / HandleFailure / HandleBug / HandleEpicComplete.
+//   - After HandleEpicComplete, roll over to the next epic queued in
+//     .doug/epics/ and keep looping; exit 0 when the backlog is empty.
 //   - Fatal errors (nested bug, blocked task, epic commit failure) return non-nil
 //     so cobra exits with code 1.
 //   - Max iterations reached → exit code 0.
-func runOrchestrate(cmd *cobra.Command, args []string) error {
+//
+// SIGINT/SIGTERM: the signal is forwarded to the agent's process group and
+// doug waits for it to exit, then HandleInterrupt rolls back (unless
+// --keep-changes) and returns the attempt so it does not consume a retry.
+// A signal that arrives between agent runs is honoured at the start of the
+// next iteration. Either way doug exits with exitCodeInterrupted.
+func runOrchestrate(cmd *cobra.Command, args []string) (runErr error) {
 	// Step 1: Determine project root from the current working directory.
 	projectRoot, err := os.Getwd()
 	if err != nil {
@@ -83,6 +113,8 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 	statePath := filepath.Join(dougDir, "project-state.yaml")
 	tasksPath := filepath.Join(dougDir, "tasks.yaml")
 	logsDir := filepath.Join(dougDir, "logs")
+	historyDir := filepath.Join(dougDir, "history")
+	epicsDir := filepath.Join(dougDir, "epics")
 	changelogPath := filepath.Join(projectRoot, "CHANGELOG.md")
 	skillsConfigPath := filepath.Join(projectRoot, config.DefaultSkillsConfigPath)
 
@@ -111,12 +143,44 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 	if cmd.Flags().Changed("agent-heartbeat-seconds") {
 		cfg.AgentHeartbeatSeconds = runFlags.agentHeartbeatSeconds
 	}
+	if cmd.Flags().Changed("agent-timeout-seconds") {
+		cfg.AgentTimeoutSeconds = runFlags.agentTimeoutSeconds
+	}
+	if cmd.Flags().Changed("lint") {
+		if err := config.ValidateLintMode(runFlags.lint); err != nil {
+			return fmt.Errorf("--lint: %w", err)
+		}
+		cfg.Lint = runFlags.lint
+	}
+
+	// Webhook notifications are optional; a bad webhook block fails fast.
+	events, err := newEventSink(cfg.Webhook)
+	if err != nil {
+		return err
+	}
 
 	// Step 3: Verify all required binaries are available before doing any work.
-	if err := orchestrator.CheckDependencies(cfg); err != nil {
+	if err := orchestrator.CheckDependencies(cfg, projectRoot); err != nil {
 		return fmt.Errorf("dependency check failed: %w", err)
 	}
 
+	// Rollbacks reset and clean the working tree, so uncommitted user work
+	// must be out of the way before the first attempt.
+	policy := orchestrator.DirtyTreeRefuse
+	switch {
+	case runFlags.stash:
+		policy = orchestrator.DirtyTreeStash
+	case runFlags.commitBaseline:
+		policy = orchestrator.DirtyTreeCommitBaseline
+	}
+	restore, err := orchestrator.GuardWorkingTree(projectRoot, policy)
+	if err != nil {
+		return err
+	}
+	if restore != nil {
+		defer restore()
+	}
+
 	// Step 4: Load state and task files.
 	projectState, err := state.LoadProjectState(statePath)
 	if err != nil {
@@ -127,26 +191,65 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("load tasks: %w", err)
 	}
 
+	// From here on any error, signal included, is reported as run_aborted.
+	if events != nil {
+		defer func() {
+			if runErr != nil {
+				publishRunAborted(events, projectState, runErr)
+			}
+		}()
+	}
+
 	// Step 5: detect epic rollover when tasks.yaml switched to a new epic.
+	// The finished epic is archived to .doug/history/ before its metrics are
+	// reset; if the archive cannot be written the reset is never persisted.
+	finished := types.EpicHistory{Epic: projectState.CurrentEpic, Metrics: projectState.Metrics}
 	rolled, err := orchestrator.PrepareForEpicRollover(projectState, tasks)
 	if err != nil {
 		return fmt.Errorf("epic rollover blocked: %w", err)
 	}
 	if rolled {
+		if err := state.SaveEpicHistory(historyDir, &finished); err != nil {
+			return fmt.Errorf("archive metrics for epic %s: %w", finished.Epic.ID, err)
+		}
+		log.Info(fmt.Sprintf("archived epic %s to %s", finished.Epic.ID, historyDir))
 		log.Info(fmt.Sprintf("detected new epic %s in tasks.yaml — resetting runtime state for rollover", tasks.Epic.ID))
 	}
 
 	// Step 5: Bootstrap state on first run (no-op if CurrentEpic.ID is already set).
-	orchestrator.BootstrapFromTasks(projectState, tasks)
+	if err := orchestrator.BootstrapFromTasks(projectState, tasks, cfg.BranchTemplate); err != nil {
+		return fmt.Errorf("bootstrap: %w", err)
+	}
+	log.SetTaskContext(log.TaskContext{EpicID: projectState.CurrentEpic.ID})
+	defer log.ClearTaskContext()
+
+	// Epics queued in .doug/epics/ run after the current one, in name order.
+	backlog := epicBacklog{
+		dir:         epicsDir,
+		historyDir:  historyDir,
+		tasksPath:   tasksPath,
+		statePath:   statePath,
+		projectRoot: projectRoot,
+		kbEnabled:   cfg.KBEnabled,
+		branchTmpl:  cfg.BranchTemplate,
+	}
 
-	// Step 6: Early exit if all tasks are already complete.
+	// Step 6: Move on to the next backlog epic, or exit early, if all tasks
+	// are already complete.
 	if orchestrator.IsEpicAlreadyComplete(projectState, tasks, cfg.KBEnabled) {
-		log.Success("all tasks already DONE — nothing to do")
-		return nil // exit code 0
+		next, err := backlog.advance(projectState, tasks)
+		if err != nil {
+			return fmt.Errorf("advance to next epic: %w", err)
+		}
+		if next == nil {
+			log.Success("all tasks already DONE — nothing to do")
+			return nil // exit code 0
+		}
+		tasks = next
 	}
 
 	// Step 7: Construct the build system implementation.
-	buildSys, err := build.NewBuildSystem(cfg.BuildSystem, projectRoot)
+	buildSys, err := build.NewBuildSystemFromConfig(cfg, projectRoot)
 	if err != nil {
 		return fmt.Errorf("build system: %w", err)
 	}
@@ -163,6 +266,9 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 	if err := orchestrator.ValidateTaskTypes(tasks); err != nil {
 		return fmt.Errorf("task type validation failed: %w", err)
 	}
+	if err := orchestrator.ValidateTaskDependencies(tasks); err != nil {
+		return fmt.Errorf("task dependency validation failed: %w", err)
+	}
 
 	// Step 10: Ensure the working tree is on the correct epic feature branch.
 	if err := git.EnsureEpicBranch(projectState.CurrentEpic.BranchName, projectRoot); err != nil {
@@ -171,6 +277,12 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 
 	// Step 11: Align active and next task pointers with the current task list state.
 	orchestrator.InitializeTaskPointers(projectState, tasks, cfg.KBEnabled)
+	if projectState.ActiveTask.Type == types.TaskTypeManualReview {
+		if err := state.SaveProjectState(statePath, projectState); err != nil {
+			log.Warning(fmt.Sprintf("could not save state after setting manual review: %v", err))
+		}
+		return orchestrator.BlockedTasksError(tasks)
+	}
 
 	// Step 12: Validate state/task consistency.
 	// Synthetic tasks (bugfix, documentation) are never in tasks.yaml by design;
@@ -190,11 +302,30 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("save initial project state: %w", err)
 	}
 
+	// From here on, SIGINT/SIGTERM are handled by the loop instead of killing
+	// doug mid-iteration.
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupts)
+
+	// In json log mode stdout carries only log events, so the live copy of
+	// the agent's stdout goes to stderr; the transcript is unaffected.
+	var agentStdout io.Writer
+	if log.CurrentFormat() == log.FormatJSON {
+		agentStdout = os.Stderr
+	}
+
 	// -------------------------------------------------------------------------
 	// Main orchestration loop
 	// -------------------------------------------------------------------------
 	for iteration := 0; iteration < cfg.MaxIterations; iteration++ {
-		log.Section(fmt.Sprintf("ITERATION %d — task %s", iteration+1, projectState.ActiveTask.ID))
+		// Honour a signal received while the previous iteration's handler ran.
+		// State was persisted by the handler, so there is nothing to undo.
+		select {
+		case sig := <-interrupts:
+			return fmt.Errorf("%w by %s before task %s started", errInterrupted, sig, projectState.ActiveTask.ID)
+		default:
+		}
 
 		// IncrementAttempts at the START of each iteration, matching Bash orchestrator behavior.
 		orchestrator.IncrementAttempts(projectState)
@@ -204,6 +335,14 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 		taskType := projectState.ActiveTask.Type
 		attempts := projectState.ActiveTask.Attempts
 
+		// Attribute every log line of this iteration to the task and attempt.
+		log.SetTaskContext(log.TaskContext{
+			EpicID:  projectState.CurrentEpic.ID,
+			TaskID:  taskID,
+			Attempt: attempts,
+		})
+		log.Section(fmt.Sprintf("ITERATION %d — task %s", iteration+1, taskID))
+
 		// Safety net: catch any stuck loop regardless of outcome type.
 		// HandleFailure blocks at attempts == MaxRetries; this fires at MaxRetries+1
 		// as a backstop for tasks that always report SUCCESS but never advance.
@@ -229,14 +368,42 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("create session file: %w", err)
 		}
 
+		// Offer the JSON result file only when it is read, and clear any left
+		// over from an earlier run so a stale result is never picked up.
+		var sessionJSONPath string
+		if slices.Contains(cfg.ResultSources, config.ResultJSON) {
+			sessionJSONPath = agent.SessionJSONPath(sessionPath)
+			if err := os.Remove(sessionJSONPath); err != nil && !os.IsNotExist(err) {
+				log.Warning(fmt.Sprintf("could not remove stale %s: %v", sessionJSONPath, err))
+			}
+		}
+
+		// Tee agent output into a transcript next to the session file. A
+		// transcript that cannot be created is not worth failing the attempt.
+		transcript, err := agent.OpenTranscript(agent.TranscriptPath(sessionPath), agent.TranscriptOptions{
+			StripANSI: cfg.TranscriptStripANSI,
+			MaxBytes:  cfg.TranscriptMaxBytes,
+		})
+		if err != nil {
+			log.Warning(fmt.Sprintf("agent transcript disabled for this attempt: %v", err))
+		}
+		// The stdout result channel reads the end of the output from memory:
+		// the transcript keeps only its head and may not be open at all.
+		outputTail := agent.NewOutputTail(agent.ResultTailBytes)
+
 		// Look up description and acceptance criteria for user-defined tasks.
 		// For synthetic tasks (bugfix, documentation) the task won't be found — empty values are fine.
 		var taskDesc string
 		var taskCriteria []string
+		timeoutSeconds := cfg.AgentTimeoutSeconds
 		for _, t := range tasks.Epic.Tasks {
 			if t.ID == taskID {
 				taskDesc = t.Description
 				taskCriteria = t.AcceptanceCriteria
+				// Per-task timeout wins over doug.yaml, but not over an explicit CLI flag.
+				if t.AgentTimeoutSeconds > 0 && !cmd.Flags().Changed("agent-timeout-seconds") {
+					timeoutSeconds = t.AgentTimeoutSeconds
+				}
 				break
 			}
 		}
@@ -246,11 +413,14 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 			TaskID:             taskID,
 			TaskType:           taskType,
 			SessionFilePath:    sessionPath,
+			SessionJSONPath:    sessionJSONPath,
 			DougDir:            dougDir,
 			Description:        taskDesc,
 			AcceptanceCriteria: taskCriteria,
 			Attempts:           attempts,
 			MaxRetries:         cfg.MaxRetries,
+			PreviousAttempts:   orchestrator.AttemptsFor(projectState, taskID),
+			HumanNotes:         orchestrator.NotesFor(projectState, taskID),
 		}); err != nil {
 			return fmt.Errorf("write active task: %w", err)
 		}
@@ -272,16 +442,36 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 			CurrentEpic:   projectState.CurrentEpic,
 			Config:        cfg,
 			BuildSystem:   buildSys,
+			Events:        events,
+			Interrupt:     interrupts,
 			ProjectRoot:   projectRoot,
 			TaskStartTime: time.Now(),
+			SessionPath:   sessionPath,
 			State:         projectState,
 			Tasks:         tasks,
 			StatePath:     statePath,
 			TasksPath:     tasksPath,
 			DougDir:       dougDir,
 			LogsDir:       logsDir,
+			HistoryDir:    historyDir,
 			ChangelogPath: changelogPath,
 		}
+		if transcript != nil {
+			ctx.TranscriptPath = transcript.Path()
+		}
+
+		// The agent has not run yet, so an aborting pre_task hook does not
+		// count against the task's retries.
+		if err := handlers.FireHook(ctx, config.HookPreTask, ""); err != nil {
+			if transcript != nil {
+				_ = transcript.Close()
+			}
+			projectState.ActiveTask.Attempts--
+			if saveErr := state.SaveProjectState(statePath, projectState); saveErr != nil {
+				log.Warning(fmt.Sprintf("could not restore attempt counter for task %s: %v", taskID, saveErr))
+			}
+			return err
+		}
 
 		// Resolve {{skill_name}} and {{task_id}} in agent command before invocation.
 		skillName, _ := agent.GetSkillForTaskType(string(taskType), skillsConfigPath)
@@ -291,29 +481,74 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 		// Invoke the agent; a non-zero exit is non-fatal — the session file is
 		// the authoritative result regardless of the agent process exit code.
 		log.Info(fmt.Sprintf("invoking agent for task %s (attempt %d)", taskID, attempts))
-		heartbeatEvery := time.Duration(cfg.AgentHeartbeatSeconds) * time.Second
-		if _, agentErr := agent.RunAgent(resolvedCmd, projectRoot, heartbeatEvery, func(elapsed time.Duration) {
-			log.Info(fmt.Sprintf(
-				"agent still running for task %s (attempt %d, elapsed %s)",
-				taskID,
-				attempts,
-				elapsed.Round(time.Second),
-			))
-		}); agentErr != nil {
-			log.Warning(fmt.Sprintf("agent exited with error: %v — reading session result anyway", agentErr))
+		_, agentErr := agent.RunAgentWithOptions(resolvedCmd, projectRoot, agent.RunOptions{
+			HeartbeatInterval: time.Duration(cfg.AgentHeartbeatSeconds) * time.Second,
+			HeartbeatFn: func(elapsed time.Duration) {
+				log.Info(fmt.Sprintf(
+					"agent still running for task %s (attempt %d, elapsed %s)",
+					taskID,
+					attempts,
+					elapsed.Round(time.Second),
+				))
+			},
+			Timeout:   time.Duration(timeoutSeconds) * time.Second,
+			Interrupt: interrupts,
+			// A nil *Transcript must not become a non-nil io.Writer.
+			Transcript: transcriptWriter(transcript),
+			Tail:       outputTail,
+			Stdout:     agentStdout,
+		})
+		if transcript != nil {
+			if err := transcript.Close(); err != nil {
+				log.Warning(fmt.Sprintf("agent transcript incomplete: %v", err))
+			}
+		}
+
+		if errors.Is(agentErr, agent.ErrAgentInterrupted) {
+			log.Warning(fmt.Sprintf("%v — stopping after task %s attempt %d", agentErr, taskID, attempts))
+			if err := handlers.HandleInterrupt(ctx, runFlags.keepChanges); err != nil {
+				return fmt.Errorf("%w: HandleInterrupt: %v", errInterrupted, err)
+			}
+			return fmt.Errorf("%w: task %s attempt %d was not counted", errInterrupted, taskID, attempts)
 		}
 
-		// Parse the session result written by the agent.
-		result, parseErr := agent.ParseSessionResult(sessionPath)
-		if parseErr != nil {
-			log.Error(fmt.Sprintf("failed to parse session result from %s: %v — treating as FAILURE", sessionPath, parseErr))
+		var result *types.SessionResult
+		if errors.Is(agentErr, agent.ErrAgentTimeout) {
+			// A timed-out agent may have left a half-written session file;
+			// ignore it and count the attempt as a FAILURE with rollback.
+			log.Error(fmt.Sprintf("agent for task %s (attempt %d) terminated: %v — treating as FAILURE", taskID, attempts, agentErr))
+			ctx.AgentTimedOut = true
+			ctx.FailureReason = agentErr.Error()
 			result = &types.SessionResult{Outcome: types.OutcomeFailure}
+		} else {
+			if agentErr != nil {
+				log.Warning(fmt.Sprintf("agent exited with error: %v — reading session result anyway", agentErr))
+			}
+
+			// Read the session result from the configured channels in order.
+			var source string
+			var parseErr error
+			result, source, parseErr = agent.ReadSessionResult(sessionPath, outputTail.Bytes(), cfg.ResultSources)
+			if parseErr != nil {
+				log.Error(fmt.Sprintf("failed to parse session result from %s: %v — treating as FAILURE", sessionPath, parseErr))
+				result = &types.SessionResult{Outcome: types.OutcomeFailure}
+				ctx.FailureReason = fmt.Sprintf("session result could not be parsed: %v", parseErr)
+			} else if source != config.ResultFrontmatter {
+				log.Info(fmt.Sprintf("session result read from %s", source))
+			}
 		}
 		ctx.SessionResult = result
 
 		log.Info(fmt.Sprintf("session outcome: %s", result.Outcome))
 
+		// An aborting post_agent hook stops the run before the outcome is
+		// handled; the agent's changes are left in the working tree.
+		if err := handlers.FireHook(ctx, config.HookPostAgent, ctx.FailureReason); err != nil {
+			return err
+		}
+
 		// Dispatch to the appropriate outcome handler.
+		epicDone := false
 		switch result.Outcome {
 
 		case types.OutcomeSuccess:
@@ -329,14 +564,30 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 					// Tier 3: epic commit failure — surface as exit code 1 (CI-6).
 					return fmt.Errorf("epic finalization failed: %w", err)
 				}
-				return nil // exit code 0
+				epicDone = true
 
 			case handlers.Continue:
 				// Normal forward progress — state already updated in memory by handler.
+				// Without KB synthesis the last DONE task completes the epic.
+				if !cfg.KBEnabled && orchestrator.IsEpicAlreadyComplete(projectState, tasks, cfg.KBEnabled) {
+					if err := handlers.HandleEpicComplete(ctx); err != nil {
+						return fmt.Errorf("epic finalization failed: %w", err)
+					}
+					epicDone = true
+				}
 
 			case handlers.Retry:
 				// Non-fatal issue (build/test failure, git commit failure).
 				// The handler rolled back changes; the loop retries on the next iteration.
+
+			case handlers.Interrupted:
+				// A signal stopped verification; like an interrupted agent,
+				// the attempt is not counted.
+				log.Warning(fmt.Sprintf("verification interrupted — stopping after task %s attempt %d", taskID, attempts))
+				if err := handlers.HandleInterrupt(ctx, runFlags.keepChanges); err != nil {
+					return fmt.Errorf("%w: HandleInterrupt: %v", errInterrupted, err)
+				}
+				return fmt.Errorf("%w: task %s attempt %d was not counted", errInterrupted, taskID, attempts)
 			}
 
 		case types.OutcomeFailure:
@@ -359,7 +610,20 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 				// Tier 3: epic commit failure — exit code 1 (CI-6).
 				return fmt.Errorf("epic finalization failed: %w", err)
 			}
-			return nil // exit code 0
+			epicDone = true
+		}
+
+		// A finished epic hands over to the next one in the backlog; the
+		// iteration budget is shared across epics.
+		if epicDone {
+			next, err := backlog.advance(projectState, tasks)
+			if err != nil {
+				return fmt.Errorf("advance to next epic: %w", err)
+			}
+			if next == nil {
+				return nil // exit code 0
+			}
+			tasks = next
 		}
 	}
 
@@ -367,3 +631,49 @@ func runOrchestrate(cmd *cobra.Command, args []string) error {
 	log.Warning(fmt.Sprintf("max iterations (%d) reached — exiting", cfg.MaxIterations))
 	return nil // exit code 0
 }
+
+// transcriptWriter returns t as an io.Writer, or nil when t is nil, so that
+// RunOptions.Transcript stays a true nil interface when no transcript is open.
+func transcriptWriter(t *agent.Transcript) io.Writer {
+	if t == nil {
+		return nil
+	}
+	return t
+}
+
+// newEventSink returns the webhook sink configured by cfg, or nil when no
+// webhook URL is set. The signing secret is read from cfg.SecretEnv.
+func newEventSink(cfg config.WebhookConfig) (notify.Sink, error) {
+	if cfg.URL == "" {
+		return nil, nil
+	}
+	sink, err := notify.NewWebhookSink(notify.WebhookOptions{
+		URL:         cfg.URL,
+		Secret:      os.Getenv(cfg.SecretEnv),
+		Events:      cfg.Events,
+		MaxAttempts: cfg.MaxAttempts,
+		Deadline:    time.Duration(cfg.DeadlineSeconds) * time.Second,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("webhook: %w", err)
+	}
+	return sink, nil
+}
+
+// publishRunAborted reports the error that stopped the run, attributed to
+// the active task.
+func publishRunAborted(sink notify.Sink, st *types.ProjectState, runErr error) {
+	sink.Publish(notify.Event{
+		Type:     notify.RunAborted,
+		EpicID:   st.CurrentEpic.ID,
+		EpicName: st.CurrentEpic.Name,
+		TaskID:   st.ActiveTask.ID,
+		TaskType: string(st.ActiveTask.Type),
+		Attempt:  st.ActiveTask.Attempts,
+		Reason:   runErr.Error(),
+		Metrics: notify.Metrics{
+			TasksCompleted:       st.Metrics.TotalTasksCompleted,
+			TotalDurationSeconds: st.Metrics.TotalDurationSeconds,
+		},
+	})
+}
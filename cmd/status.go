@@ -0,0 +1,297 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robertgumeny/doug/internal/config"
+	"github.com/robertgumeny/doug/internal/metrics"
+	"github.com/robertgumeny/doug/internal/orchestrator"
+	"github.com/robertgumeny/doug/internal/state"
+	"github.com/robertgumeny/doug/internal/types"
+)
+
+var statusFlags struct {
+	json bool
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show epic and task progress",
+	Long:  "Render the current epic, task pointers, per-task status and metrics from .doug/project-state.yaml and .doug/tasks.yaml. Never modifies state.",
+	Args:  cobra.NoArgs,
+	RunE:  runStatus,
+}
+
+func init() {
+	statusCmd.Flags().BoolVar(&statusFlags.json, "json", false, "Emit the status report as JSON")
+}
+
+// statusReport is the read-only snapshot rendered by doug status.
+// JSON tags define the --json output contract for scripts.
+type statusReport struct {
+	Epic       statusEpic    `json:"epic"`
+	ActiveTask statusPointer `json:"active_task"`
+	NextTask   statusPointer `json:"next_task"`
+	MaxRetries int           `json:"max_retries"`
+	Tasks      []statusTask  `json:"tasks"`
+	Counts     statusCounts  `json:"counts"`
+	Metrics    statusMetrics `json:"metrics"`
+	Issues     []string      `json:"issues"`
+}
+
+type statusEpic struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	BranchName  string `json:"branch_name"`
+	StartedAt   string `json:"started_at"`
+	CompletedAt string `json:"completed_at,omitempty"`
+}
+
+type statusPointer struct {
+	Type     string `json:"type,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Attempts int    `json:"attempts,omitempty"`
+}
+
+type statusTask struct {
+	ID        string   `json:"id"`
+	Type      string   `json:"type"`
+	Status    string   `json:"status"`
+	DependsOn []string `json:"depends_on,omitempty"`
+}
+
+type statusCounts struct {
+	TODO       int `json:"todo"`
+	InProgress int `json:"in_progress"`
+	Done       int `json:"done"`
+	Blocked    int `json:"blocked"`
+}
+
+type statusMetrics struct {
+	TotalTasksCompleted  int `json:"total_tasks_completed"`
+	TotalDurationSeconds int `json:"total_duration_seconds"`
+}
+
+func runStatus(cmd *cobra.Command, args []string) error {
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+
+	report, err := buildStatusReport(projectRoot)
+	if err != nil {
+		return err
+	}
+
+	if statusFlags.json {
+		return writeStatusJSON(cmd.OutOrStdout(), report)
+	}
+	writeStatusText(cmd.OutOrStdout(), report)
+	return nil
+}
+
+// buildStatusReport loads doug.yaml, project-state.yaml and tasks.yaml from
+// projectRoot and assembles a statusReport. Nothing is written back to disk:
+// validation runs against a copy of the loaded state so auto-corrections made
+// by ValidateStateSync are reported as issues rather than applied.
+func buildStatusReport(projectRoot string) (*statusReport, error) {
+	dougDir := filepath.Join(projectRoot, ".doug")
+
+	cfg, err := config.LoadConfig(filepath.Join(dougDir, "doug.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	projectState, err := state.LoadProjectState(filepath.Join(dougDir, "project-state.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("load project state: %w", err)
+	}
+	tasks, err := state.LoadTasks(filepath.Join(dougDir, "tasks.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("load tasks: %w", err)
+	}
+
+	report := &statusReport{
+		Epic: statusEpic{
+			ID:         projectState.CurrentEpic.ID,
+			Name:       projectState.CurrentEpic.Name,
+			BranchName: projectState.CurrentEpic.BranchName,
+			StartedAt:  projectState.CurrentEpic.StartedAt,
+		},
+		ActiveTask: statusPointer{
+			Type:     string(projectState.ActiveTask.Type),
+			ID:       projectState.ActiveTask.ID,
+			Attempts: projectState.ActiveTask.Attempts,
+		},
+		NextTask: statusPointer{
+			Type: string(projectState.NextTask.Type),
+			ID:   projectState.NextTask.ID,
+		},
+		MaxRetries: cfg.MaxRetries,
+		Tasks:      make([]statusTask, 0, len(tasks.Epic.Tasks)),
+		Metrics: statusMetrics{
+			TotalTasksCompleted:  projectState.Metrics.TotalTasksCompleted,
+			TotalDurationSeconds: projectState.Metrics.TotalDurationSeconds,
+		},
+		Issues: []string{},
+	}
+	if projectState.CurrentEpic.CompletedAt != nil {
+		report.Epic.CompletedAt = *projectState.CurrentEpic.CompletedAt
+	}
+
+	// Before the first doug run the state file is empty; show the epic from
+	// tasks.yaml so the table is still meaningful.
+	if report.Epic.ID == "" {
+		report.Epic.ID = tasks.Epic.ID
+		report.Epic.Name = tasks.Epic.Name
+	}
+
+	for _, t := range tasks.Epic.Tasks {
+		report.Tasks = append(report.Tasks, statusTask{
+			ID:        t.ID,
+			Type:      string(t.Type),
+			Status:    string(t.Status),
+			DependsOn: t.DependsOn,
+		})
+		switch t.Status {
+		case types.StatusTODO:
+			report.Counts.TODO++
+		case types.StatusInProgress:
+			report.Counts.InProgress++
+		case types.StatusDone:
+			report.Counts.Done++
+		case types.StatusBlocked:
+			report.Counts.Blocked++
+		}
+	}
+
+	report.Issues = append(report.Issues, statusIssues(projectState, tasks, cfg)...)
+	return report, nil
+}
+
+// statusIssues runs the same consistency checks doug run performs at startup
+// and returns a human-readable description of every problem found. The
+// caller's state is never mutated.
+func statusIssues(projectState *types.ProjectState, tasks *types.Tasks, cfg *config.OrchestratorConfig) []string {
+	var issues []string
+
+	if projectState.CurrentEpic.ID == "" {
+		return append(issues, "project state has not been bootstrapped yet — run doug run to start the epic")
+	}
+	if tasks.Epic.ID != "" && tasks.Epic.ID != projectState.CurrentEpic.ID {
+		issues = append(issues, fmt.Sprintf(
+			"tasks.yaml declares epic %q but project state is on epic %q — doug run will attempt an epic rollover",
+			tasks.Epic.ID, projectState.CurrentEpic.ID))
+	}
+
+	if err := orchestrator.ValidateYAMLStructure(projectState, tasks); err != nil {
+		issues = append(issues, err.Error())
+	}
+	if err := orchestrator.ValidateTaskTypes(tasks); err != nil {
+		issues = append(issues, err.Error())
+	}
+	if err := orchestrator.ValidateTaskDependencies(tasks); err != nil {
+		issues = append(issues, err.Error())
+	}
+	if stalled := orchestrator.StalledTasks(tasks); len(stalled) > 0 {
+		issues = append(issues, fmt.Sprintf("tasks %s depend on BLOCKED tasks and cannot run",
+			strings.Join(stalled, ", ")))
+	}
+
+	// ValidateStateSync may redirect active_task; run it on a copy.
+	// Synthetic tasks are never in tasks.yaml, matching the skip in doug run.
+	if projectState.ActiveTask.ID != "" && !projectState.ActiveTask.Type.IsSynthetic() {
+		snapshot := *projectState
+		vResult, vErr := orchestrator.ValidateStateSync(&snapshot, tasks)
+		switch {
+		case vErr != nil:
+			issues = append(issues, vErr.Error())
+		case vResult.Kind == orchestrator.ValidationAutoCorrected:
+			issues = append(issues, vResult.Description+" (doug run will apply this correction)")
+		}
+	}
+
+	if projectState.ActiveTask.Type == types.TaskTypeManualReview {
+		issues = append(issues, fmt.Sprintf("task %s is awaiting manual review — fix the cause, then run doug unblock %s",
+			projectState.ActiveTask.ID, projectState.ActiveTask.ID))
+	}
+	if projectState.ActiveTask.Attempts > cfg.MaxRetries {
+		issues = append(issues, fmt.Sprintf("active task %s has %d attempts, exceeding max_retries (%d)",
+			projectState.ActiveTask.ID, projectState.ActiveTask.Attempts, cfg.MaxRetries))
+	}
+
+	return issues
+}
+
+// writeStatusJSON encodes report as indented JSON to w.
+func writeStatusJSON(w io.Writer, report *statusReport) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("encode status: %w", err)
+	}
+	return nil
+}
+
+// writeStatusText renders report as a human-readable table to w.
+func writeStatusText(w io.Writer, report *statusReport) {
+	const line = "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"
+
+	fmt.Fprintf(w, "%s\n", line)
+	fmt.Fprintf(w, "EPIC %s — %s\n", report.Epic.ID, report.Epic.Name)
+	fmt.Fprintf(w, "%s\n", line)
+	if report.Epic.BranchName != "" {
+		fmt.Fprintf(w, "  %-14s %s\n", "Branch:", report.Epic.BranchName)
+	}
+	if report.Epic.StartedAt != "" {
+		fmt.Fprintf(w, "  %-14s %s\n", "Started:", report.Epic.StartedAt)
+	}
+	if report.Epic.CompletedAt != "" {
+		fmt.Fprintf(w, "  %-14s %s\n", "Completed:", report.Epic.CompletedAt)
+	}
+	fmt.Fprintf(w, "  %-14s %s\n", "Active task:", formatStatusPointer(report.ActiveTask))
+	if report.ActiveTask.ID != "" {
+		fmt.Fprintf(w, "  %-14s %d of %d\n", "Attempts:", report.ActiveTask.Attempts, report.MaxRetries)
+	}
+	fmt.Fprintf(w, "  %-14s %s\n", "Next task:", formatStatusPointer(report.NextTask))
+
+	fmt.Fprintf(w, "\n  %-20s %-14s %-12s %s\n", "TASK", "TYPE", "STATUS", "DEPENDS ON")
+	for _, t := range report.Tasks {
+		marker := " "
+		if t.ID == report.ActiveTask.ID {
+			marker = "*"
+		}
+		deps := "—"
+		if len(t.DependsOn) > 0 {
+			deps = strings.Join(t.DependsOn, ", ")
+		}
+		fmt.Fprintf(w, "%s %-20s %-14s %-12s %s\n", marker, t.ID, t.Type, t.Status, deps)
+	}
+	fmt.Fprintf(w, "\n  TODO %d · IN_PROGRESS %d · DONE %d · BLOCKED %d\n",
+		report.Counts.TODO, report.Counts.InProgress, report.Counts.Done, report.Counts.Blocked)
+
+	fmt.Fprintf(w, "\n  %-22s %d\n", "Metrics recorded:", report.Metrics.TotalTasksCompleted)
+	fmt.Fprintf(w, "  %-22s %s\n", "Total time:", metrics.FormatDuration(report.Metrics.TotalDurationSeconds))
+
+	if len(report.Issues) > 0 {
+		fmt.Fprintf(w, "\nIssues:\n")
+		for _, issue := range report.Issues {
+			fmt.Fprintf(w, "  ! %s\n", issue)
+		}
+	}
+	fmt.Fprintf(w, "%s\n", line)
+}
+
+// formatStatusPointer renders a task pointer as "ID (type)" or "—" when empty.
+func formatStatusPointer(p statusPointer) string {
+	if p.ID == "" {
+		return "—"
+	}
+	return fmt.Sprintf("%s (%s)", p.ID, p.Type)
+}
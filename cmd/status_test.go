@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupStatusProject initialises a doug project and overwrites its state files
+// with the given content.
+func setupStatusProject(t *testing.T, stateYAML, tasksYAML string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := initProject(dir, false, "", []string{"claude"}); err != nil {
+		t.Fatalf("initProject: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".doug", "project-state.yaml"), []byte(stateYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".doug", "tasks.yaml"), []byte(tasksYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+const statusTasksYAML = `epic:
+  id: EPIC-1
+  name: First Epic
+  tasks:
+    - id: EPIC-1-001
+      type: feature
+      status: DONE
+    - id: EPIC-1-002
+      type: feature
+      status: IN_PROGRESS
+    - id: EPIC-1-003
+      type: feature
+      status: TODO
+`
+
+const statusStateYAML = `current_epic:
+  id: EPIC-1
+  name: First Epic
+  branch_name: feature/EPIC-1
+  started_at: "2026-03-01T00:00:00Z"
+active_task:
+  type: feature
+  id: EPIC-1-002
+  attempts: 2
+next_task:
+  type: feature
+  id: EPIC-1-003
+metrics:
+  total_tasks_completed: 1
+  total_duration_seconds: 125
+  tasks:
+    - task_id: EPIC-1-001
+      outcome: success
+      duration_seconds: 125
+      completed_at: "2026-03-01T00:02:05Z"
+`
+
+func TestBuildStatusReport_Consistent(t *testing.T) {
+	dir := setupStatusProject(t, statusStateYAML, statusTasksYAML)
+
+	report, err := buildStatusReport(dir)
+	if err != nil {
+		t.Fatalf("buildStatusReport: %v", err)
+	}
+	if report.Epic.ID != "EPIC-1" {
+		t.Errorf("Epic.ID = %q, want EPIC-1", report.Epic.ID)
+	}
+	if report.ActiveTask.ID != "EPIC-1-002" || report.ActiveTask.Attempts != 2 {
+		t.Errorf("ActiveTask = %+v, want EPIC-1-002 with 2 attempts", report.ActiveTask)
+	}
+	if report.NextTask.ID != "EPIC-1-003" {
+		t.Errorf("NextTask.ID = %q, want EPIC-1-003", report.NextTask.ID)
+	}
+	if report.Counts.Done != 1 || report.Counts.InProgress != 1 || report.Counts.TODO != 1 {
+		t.Errorf("Counts = %+v, want 1 DONE, 1 IN_PROGRESS, 1 TODO", report.Counts)
+	}
+	if report.Metrics.TotalDurationSeconds != 125 {
+		t.Errorf("Metrics.TotalDurationSeconds = %d, want 125", report.Metrics.TotalDurationSeconds)
+	}
+	if len(report.Issues) != 0 {
+		t.Errorf("expected no issues, got %v", report.Issues)
+	}
+}
+
+func TestBuildStatusReport_FlagsDriftWithoutMutating(t *testing.T) {
+	drifted := strings.Replace(statusStateYAML, "id: EPIC-1-002", "id: EPIC-1-999", 1)
+	dir := setupStatusProject(t, drifted, statusTasksYAML)
+	statePath := filepath.Join(dir, ".doug", "project-state.yaml")
+	before, _ := os.ReadFile(statePath)
+
+	report, err := buildStatusReport(dir)
+	if err != nil {
+		t.Fatalf("buildStatusReport: %v", err)
+	}
+	if len(report.Issues) == 0 {
+		t.Fatal("expected a state sync issue for unknown active task")
+	}
+	if !strings.Contains(strings.Join(report.Issues, "\n"), "EPIC-1-999") {
+		t.Errorf("issues should mention the unknown task ID; got %v", report.Issues)
+	}
+	if report.ActiveTask.ID != "EPIC-1-999" {
+		t.Errorf("report must show the on-disk active task; got %q", report.ActiveTask.ID)
+	}
+
+	after, _ := os.ReadFile(statePath)
+	if !bytes.Equal(before, after) {
+		t.Error("buildStatusReport must not modify project-state.yaml")
+	}
+}
+
+func TestBuildStatusReport_NotBootstrapped(t *testing.T) {
+	dir := setupStatusProject(t, "{}\n", statusTasksYAML)
+
+	report, err := buildStatusReport(dir)
+	if err != nil {
+		t.Fatalf("buildStatusReport: %v", err)
+	}
+	if report.Epic.ID != "EPIC-1" {
+		t.Errorf("Epic.ID should fall back to tasks.yaml; got %q", report.Epic.ID)
+	}
+	if len(report.Issues) != 1 || !strings.Contains(report.Issues[0], "bootstrapped") {
+		t.Errorf("expected a single not-bootstrapped issue, got %v", report.Issues)
+	}
+}
+
+func TestBuildStatusReport_MissingState(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := buildStatusReport(dir); err == nil {
+		t.Fatal("expected error when .doug/ state files are missing")
+	}
+}
+
+func TestWriteStatus_TextAndJSON(t *testing.T) {
+	dir := setupStatusProject(t, statusStateYAML, statusTasksYAML)
+	report, err := buildStatusReport(dir)
+	if err != nil {
+		t.Fatalf("buildStatusReport: %v", err)
+	}
+
+	var text bytes.Buffer
+	writeStatusText(&text, report)
+	for _, want := range []string{"EPIC EPIC-1", "EPIC-1-002 (feature)", "2 of", "IN_PROGRESS", "2m 5s"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("text output missing %q:\n%s", want, text.String())
+		}
+	}
+
+	var raw bytes.Buffer
+	if err := writeStatusJSON(&raw, report); err != nil {
+		t.Fatalf("writeStatusJSON: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(raw.Bytes(), &decoded); err != nil {
+		t.Fatalf("JSON output is invalid: %v\n%s", err, raw.String())
+	}
+	for _, key := range []string{"epic", "active_task", "next_task", "max_retries", "tasks", "counts", "metrics", "issues"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("JSON output missing key %q", key)
+		}
+	}
+}
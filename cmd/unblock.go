@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/robertgumeny/doug/internal/config"
+	"github.com/robertgumeny/doug/internal/log"
+	"github.com/robertgumeny/doug/internal/metrics"
+	"github.com/robertgumeny/doug/internal/orchestrator"
+	"github.com/robertgumeny/doug/internal/state"
+	"github.com/robertgumeny/doug/internal/types"
+)
+
+var unblockFlags struct {
+	note string
+}
+
+var unblockCmd = &cobra.Command{
+	Use:   "unblock <task-id>",
+	Short: "Return a BLOCKED task to TODO",
+	Long:  "Flip a BLOCKED task back to TODO, reset its attempts and the task pointers, and optionally leave a note for the agent's next ACTIVE_TASK.md. The intervention is recorded in the epic metrics.",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runUnblock,
+}
+
+func init() {
+	unblockCmd.Flags().StringVar(&unblockFlags.note, "note", "", "Guidance shown to the agent in the task's next ACTIVE_TASK.md")
+}
+
+func runUnblock(cmd *cobra.Command, args []string) error {
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("get working directory: %w", err)
+	}
+	return unblockTask(projectRoot, args[0], unblockFlags.note)
+}
+
+// unblockTask applies orchestrator.UnblockTask to the project in projectRoot,
+// attaches note (when non-empty) to the task, records the intervention, and
+// saves tasks.yaml and project-state.yaml.
+func unblockTask(projectRoot, taskID, note string) error {
+	dougDir := filepath.Join(projectRoot, ".doug")
+	statePath := filepath.Join(dougDir, "project-state.yaml")
+	tasksPath := filepath.Join(dougDir, "tasks.yaml")
+
+	cfg, err := config.LoadConfig(filepath.Join(dougDir, "doug.yaml"))
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	projectState, err := state.LoadProjectState(statePath)
+	if err != nil {
+		return fmt.Errorf("load project state: %w", err)
+	}
+	tasks, err := state.LoadTasks(tasksPath)
+	if err != nil {
+		return fmt.Errorf("load tasks: %w", err)
+	}
+
+	if err := orchestrator.UnblockTask(projectState, tasks, taskID, cfg.KBEnabled); err != nil {
+		return fmt.Errorf("unblock: %w", err)
+	}
+	note = strings.TrimSpace(note)
+	if note != "" {
+		orchestrator.AddNote(projectState, types.HumanNote{
+			TaskID:  taskID,
+			Note:    note,
+			AddedAt: time.Now().UTC().Format(time.RFC3339),
+		})
+	}
+	metrics.RecordIntervention(projectState, taskID, "unblock", note)
+
+	// tasks.yaml first: if the state save then fails, doug run re-derives the
+	// pointers from the TODO task at startup.
+	if err := state.SaveTasks(tasksPath, tasks); err != nil {
+		return fmt.Errorf("save tasks: %w", err)
+	}
+	if err := state.SaveProjectState(statePath, projectState); err != nil {
+		return fmt.Errorf("save project state: %w", err)
+	}
+
+	log.Success(fmt.Sprintf("task %s unblocked — status reset to %s", taskID, types.StatusTODO))
+	if active := projectState.ActiveTask; active.ID != "" {
+		log.Info(fmt.Sprintf("active task is now %s (%s); run doug run to continue", active.ID, active.Type))
+	}
+	return nil
+}
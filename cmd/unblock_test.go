@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robertgumeny/doug/internal/state"
+	"github.com/robertgumeny/doug/internal/types"
+)
+
+const unblockTasksYAML = `epic:
+  id: EPIC-1
+  name: First Epic
+  tasks:
+    - id: EPIC-1-001
+      type: feature
+      status: DONE
+    - id: EPIC-1-002
+      type: feature
+      status: BLOCKED
+    - id: EPIC-1-003
+      type: feature
+      status: TODO
+      depends_on: [EPIC-1-002]
+`
+
+const unblockStateYAML = `current_epic:
+  id: EPIC-1
+  name: First Epic
+  branch_name: feature/EPIC-1
+  started_at: "2026-03-01T00:00:00Z"
+active_task:
+  type: manual_review
+  id: EPIC-1-002
+next_task:
+  type: feature
+  id: EPIC-1-003
+metrics:
+  total_tasks_completed: 0
+  total_duration_seconds: 0
+  tasks: []
+`
+
+func TestUnblockTask_ResetsTaskAndRecordsNote(t *testing.T) {
+	dir := setupStatusProject(t, unblockStateYAML, unblockTasksYAML)
+
+	if err := unblockTask(dir, "EPIC-1-002", "  Mock the clock instead of sleeping.  "); err != nil {
+		t.Fatalf("unblockTask: %v", err)
+	}
+
+	tasks, err := state.LoadTasks(filepath.Join(dir, ".doug", "tasks.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tasks.Epic.Tasks[1].Status; got != types.StatusTODO {
+		t.Errorf("EPIC-1-002 status = %s, want TODO", got)
+	}
+
+	st, err := state.LoadProjectState(filepath.Join(dir, ".doug", "project-state.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := types.TaskPointer{Type: types.TaskTypeFeature, ID: "EPIC-1-002"}
+	if st.ActiveTask != want {
+		t.Errorf("ActiveTask = %+v, want %+v", st.ActiveTask, want)
+	}
+	if len(st.HumanNotes) != 1 || st.HumanNotes[0].Note != "Mock the clock instead of sleeping." {
+		t.Errorf("HumanNotes = %+v, want the trimmed note", st.HumanNotes)
+	}
+	if iv := st.Metrics.Interventions; len(iv) != 1 || iv[0].TaskID != "EPIC-1-002" || iv[0].Action != "unblock" {
+		t.Errorf("Interventions = %+v, want one unblock of EPIC-1-002", iv)
+	}
+}
+
+func TestUnblockTask_NotBlocked(t *testing.T) {
+	dir := setupStatusProject(t, unblockStateYAML, unblockTasksYAML)
+
+	err := unblockTask(dir, "EPIC-1-001", "")
+	if err == nil || !strings.Contains(err.Error(), "not BLOCKED") {
+		t.Fatalf("err = %v, want a not BLOCKED error", err)
+	}
+}
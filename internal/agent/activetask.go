@@ -17,6 +17,9 @@ type ActiveTaskConfig struct {
 	TaskID          string
 	TaskType        types.TaskType
 	SessionFilePath string
+	// SessionJSONPath, when set, is listed in the briefing as an alternative
+	// to the session file for agents that write JSON more reliably.
+	SessionJSONPath string
 	// DougDir is the path to the .doug/ directory. ACTIVE_TASK.md is written
 	// to {DougDir}/ACTIVE_TASK.md. For bugfix tasks, ACTIVE_BUG.md is also
 	// read from this directory.
@@ -30,6 +33,12 @@ type ActiveTaskConfig struct {
 	Attempts int
 	// MaxRetries is the configured maximum number of retries from doug.yaml.
 	MaxRetries int
+	// PreviousAttempts lists earlier rejected attempts at this task, oldest
+	// first. Rendered as a "Previous Attempts" section when non-empty.
+	PreviousAttempts []types.AttemptRecord
+	// HumanNotes is guidance left with doug unblock --note, oldest first.
+	// Rendered as a "Notes from a Human" section when non-empty.
+	HumanNotes []types.HumanNote
 }
 
 // skillsConfigFile mirrors the YAML structure of skills-config.yaml.
@@ -74,10 +83,18 @@ func GetSkillForTaskType(taskType, configPath string) (string, error) {
 // For bugfix tasks, the content of .doug/ACTIVE_BUG.md is appended as a
 // "Bug Context" section. If ACTIVE_BUG.md is missing, the section is omitted
 // and a warning is logged.
+//
+// When config.HumanNotes is non-empty, a "Notes from a Human" section passes
+// on the guidance left when the task was unblocked. When
+// config.PreviousAttempts is non-empty, a "Previous Attempts" section explains
+// why each earlier attempt was rejected.
 func WriteActiveTask(config ActiveTaskConfig) error {
 	var sb strings.Builder
 	sb.WriteString("# Active Task\n\n")
 	sb.WriteString(fmt.Sprintf("**Session File**: %s\n", config.SessionFilePath))
+	if config.SessionJSONPath != "" {
+		sb.WriteString(fmt.Sprintf("**Session JSON File** (alternative): %s\n", config.SessionJSONPath))
+	}
 	sb.WriteString(fmt.Sprintf("**Active Bug File**: %s\n", filepath.Join(config.DougDir, "ACTIVE_BUG.md")))
 	sb.WriteString(fmt.Sprintf("**Failure File**: %s\n", filepath.Join(config.DougDir, "ACTIVE_FAILURE.md")))
 	sb.WriteString(fmt.Sprintf("**PRD File**: %s\n", filepath.Join(config.DougDir, "PRD.md")))
@@ -105,6 +122,13 @@ func WriteActiveTask(config ActiveTaskConfig) error {
 		}
 	}
 
+	if len(config.HumanNotes) > 0 {
+		writeHumanNotes(&sb, config.HumanNotes)
+	}
+	if len(config.PreviousAttempts) > 0 {
+		writePreviousAttempts(&sb, config.PreviousAttempts)
+	}
+
 	outPath := filepath.Join(config.DougDir, "ACTIVE_TASK.md")
 	if err := os.MkdirAll(config.DougDir, 0o755); err != nil {
 		return fmt.Errorf("create .doug directory %s: %w", config.DougDir, err)
@@ -125,3 +149,36 @@ func readBugContext(dougDir string) (string, error) {
 	}
 	return string(data), nil
 }
+
+// writeHumanNotes renders the "Notes from a Human" section, one paragraph per
+// note.
+func writeHumanNotes(sb *strings.Builder, notes []types.HumanNote) {
+	sb.WriteString("\n\n---\n\n## Notes from a Human\n\n")
+	sb.WriteString("This task was blocked and then returned to the queue by a person, who left this guidance. Follow it.\n")
+	for _, n := range notes {
+		sb.WriteString("\n")
+		sb.WriteString(strings.TrimSpace(n.Note))
+		sb.WriteString("\n")
+	}
+}
+
+// writePreviousAttempts renders the "Previous Attempts" section: the rejection
+// reason for each attempt, followed by the failing command output and the
+// agent's own failure report when present.
+func writePreviousAttempts(sb *strings.Builder, attempts []types.AttemptRecord) {
+	sb.WriteString("\n\n---\n\n## Previous Attempts\n\n")
+	sb.WriteString("Earlier attempts at this task were rejected. Address these problems before reporting SUCCESS again.\n")
+	for _, rec := range attempts {
+		sb.WriteString(fmt.Sprintf("\n### Attempt %d: %s\n", rec.Attempt, rec.Reason))
+		if rec.Output != "" {
+			sb.WriteString("\n```\n")
+			sb.WriteString(rec.Output)
+			sb.WriteString("\n```\n")
+		}
+		if rec.FailureReport != "" {
+			sb.WriteString("\n**Agent failure report**:\n\n")
+			sb.WriteString(rec.FailureReport)
+			sb.WriteString("\n")
+		}
+	}
+}
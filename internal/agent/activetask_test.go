@@ -188,6 +188,109 @@ func TestWriteActiveTask(t *testing.T) {
 		}
 	})
 
+	t.Run("session JSON file listed only when set", func(t *testing.T) {
+		dir := t.TempDir()
+		dougDir := filepath.Join(dir, ".doug")
+		read := func(cfg ActiveTaskConfig) string {
+			t.Helper()
+			cfg.TaskID, cfg.TaskType, cfg.SessionFilePath, cfg.DougDir = "EPIC-1-001", types.TaskTypeFeature, "session.md", dougDir
+			if err := WriteActiveTask(cfg); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			data, _ := os.ReadFile(filepath.Join(dougDir, "ACTIVE_TASK.md"))
+			return string(data)
+		}
+
+		if content := read(ActiveTaskConfig{}); strings.Contains(content, "Session JSON File") {
+			t.Errorf("unexpected Session JSON File line, got:\n%s", content)
+		}
+		if content := read(ActiveTaskConfig{SessionJSONPath: "session.json"}); !strings.Contains(content, "**Session JSON File** (alternative): session.json") {
+			t.Errorf("expected Session JSON File line, got:\n%s", content)
+		}
+	})
+
+	t.Run("renders previous attempts", func(t *testing.T) {
+		dir := t.TempDir()
+		dougDir := filepath.Join(dir, ".doug")
+
+		err := WriteActiveTask(ActiveTaskConfig{
+			TaskID:          "EPIC-4-002",
+			TaskType:        types.TaskTypeFeature,
+			SessionFilePath: "session.md",
+			DougDir:         dougDir,
+			Attempts:        3,
+			MaxRetries:      5,
+			PreviousAttempts: []types.AttemptRecord{
+				{TaskID: "EPIC-4-002", Attempt: 1, Reason: "test verification failed", Output: "--- FAIL: TestParse"},
+				{TaskID: "EPIC-4-002", Attempt: 2, Reason: "agent reported FAILURE", FailureReport: "Could not find the fixture."},
+			},
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		data, _ := os.ReadFile(filepath.Join(dougDir, "ACTIVE_TASK.md"))
+		content := string(data)
+
+		for _, want := range []string{
+			"## Previous Attempts",
+			"### Attempt 1: test verification failed",
+			"--- FAIL: TestParse",
+			"### Attempt 2: agent reported FAILURE",
+			"Could not find the fixture.",
+		} {
+			if !strings.Contains(content, want) {
+				t.Errorf("expected %q in ACTIVE_TASK.md, got:\n%s", want, content)
+			}
+		}
+	})
+
+	t.Run("renders human notes", func(t *testing.T) {
+		dir := t.TempDir()
+		dougDir := filepath.Join(dir, ".doug")
+
+		if err := WriteActiveTask(ActiveTaskConfig{
+			TaskID:          "EPIC-4-002",
+			TaskType:        types.TaskTypeFeature,
+			SessionFilePath: "session.md",
+			DougDir:         dougDir,
+			Attempts:        1,
+			MaxRetries:      5,
+			HumanNotes: []types.HumanNote{
+				{TaskID: "EPIC-4-002", Note: "The fixture lives in testdata/legacy, not testdata/."},
+			},
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		data, _ := os.ReadFile(filepath.Join(dougDir, "ACTIVE_TASK.md"))
+		content := string(data)
+		for _, want := range []string{"## Notes from a Human", "The fixture lives in testdata/legacy, not testdata/."} {
+			if !strings.Contains(content, want) {
+				t.Errorf("expected %q in ACTIVE_TASK.md, got:\n%s", want, content)
+			}
+		}
+	})
+
+	t.Run("omits previous attempts section on first attempt", func(t *testing.T) {
+		dir := t.TempDir()
+		dougDir := filepath.Join(dir, ".doug")
+
+		if err := WriteActiveTask(ActiveTaskConfig{
+			TaskID:          "EPIC-4-002",
+			TaskType:        types.TaskTypeFeature,
+			SessionFilePath: "session.md",
+			DougDir:         dougDir,
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		data, _ := os.ReadFile(filepath.Join(dougDir, "ACTIVE_TASK.md"))
+		if strings.Contains(string(data), "Previous Attempts") {
+			t.Errorf("expected no Previous Attempts section, got:\n%s", data)
+		}
+	})
+
 	t.Run("no skill content in output", func(t *testing.T) {
 		dir := t.TempDir()
 		dougDir := filepath.Join(dir, ".doug")
@@ -1,80 +1,72 @@
 package agent
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
 	"time"
+
+	"github.com/robertgumeny/doug/internal/shellargs"
 )
 
-// splitShellArgs tokenizes s like a POSIX shell, respecting single and double
-// quotes and backslash escapes outside quotes. No variable expansion or
-// globbing is performed. This allows agent commands in doug.yaml such as:
-//
-//	claude -p "Refer to CLAUDE.md for instructions"
-//
-// to be parsed correctly instead of being fragmented by whitespace splitting.
+// splitShellArgs tokenizes an agent command line without shell evaluation;
+// see shellargs.Split.
 func splitShellArgs(s string) ([]string, error) {
-	var args []string
-	var cur strings.Builder
-	inSingle := false
-	inDouble := false
-
-	for i := 0; i < len(s); i++ {
-		ch := s[i]
-		switch {
-		case inSingle:
-			if ch == '\'' {
-				inSingle = false
-			} else {
-				cur.WriteByte(ch)
-			}
-		case inDouble:
-			if ch == '\\' && i+1 < len(s) {
-				next := s[i+1]
-				// Characters escapable inside double quotes per POSIX
-				if next == '"' || next == '\\' || next == '$' || next == '`' || next == '\n' {
-					cur.WriteByte(next)
-					i++
-				} else {
-					cur.WriteByte(ch)
-				}
-			} else if ch == '"' {
-				inDouble = false
-			} else {
-				cur.WriteByte(ch)
-			}
-		case ch == '\\':
-			if i+1 < len(s) {
-				cur.WriteByte(s[i+1])
-				i++
-			}
-		case ch == '\'':
-			inSingle = true
-		case ch == '"':
-			inDouble = true
-		case ch == ' ' || ch == '\t':
-			if cur.Len() > 0 {
-				args = append(args, cur.String())
-				cur.Reset()
-			}
-		default:
-			cur.WriteByte(ch)
-		}
-	}
+	return shellargs.Split(s)
+}
 
-	if inSingle {
-		return nil, fmt.Errorf("unterminated single quote in agent command")
-	}
-	if inDouble {
-		return nil, fmt.Errorf("unterminated double quote in agent command")
-	}
-	if cur.Len() > 0 {
-		args = append(args, cur.String())
-	}
+// DefaultKillGrace is how long RunAgentWithOptions waits after sending SIGTERM
+// to a timed-out agent's process group before escalating to SIGKILL.
+const DefaultKillGrace = 10 * time.Second
+
+// transcriptWaitDelay bounds how long Wait keeps copying output after the
+// agent exits when its output is teed into a Transcript or Tail.
+const transcriptWaitDelay = 5 * time.Second
+
+// ErrAgentTimeout is returned (wrapped) by RunAgentWithOptions when the agent
+// exceeded RunOptions.Timeout and was terminated. Callers use errors.Is to
+// distinguish a timeout from an ordinary non-zero exit.
+var ErrAgentTimeout = errors.New("agent timed out")
+
+// ErrAgentInterrupted is returned (wrapped) by RunAgentWithOptions when a
+// signal arrived on RunOptions.Interrupt and was forwarded to the agent.
+var ErrAgentInterrupted = errors.New("agent interrupted")
+
+// RunOptions configures a single agent invocation.
+type RunOptions struct {
+	// HeartbeatInterval and HeartbeatFn enable periodic liveness callbacks
+	// while the agent runs. Both must be set for heartbeats to fire.
+	HeartbeatInterval time.Duration
+	HeartbeatFn       func(elapsed time.Duration)
+
+	// Timeout is the maximum wall-clock time the agent may run. Zero disables
+	// the deadline. When exceeded, the agent's whole process group receives
+	// SIGTERM, then SIGKILL after KillGrace.
+	Timeout time.Duration
 
-	return args, nil
+	// KillGrace overrides DefaultKillGrace when > 0.
+	KillGrace time.Duration
+
+	// Interrupt, when non-nil, delivers signals received by doug. The first
+	// signal is forwarded to the agent's process group; if the agent has not
+	// exited after KillGrace it is killed.
+	Interrupt <-chan os.Signal
+
+	// Transcript, when non-nil, receives a copy of everything the agent
+	// writes to stdout and stderr (see OpenTranscript).
+	Transcript io.Writer
+
+	// Tail, when non-nil, also receives that copy, whether or not a
+	// Transcript is recorded (see OutputTail).
+	Tail io.Writer
+
+	// Stdout, when non-nil, replaces os.Stdout as the live echo of the
+	// agent's stdout, e.g. os.Stderr when doug's stdout carries JSON logs.
+	// The agent's stderr is always echoed to os.Stderr.
+	Stdout io.Writer
 }
 
 // RunAgent invokes the agent using agentCommand parsed with shell-style
@@ -92,6 +84,19 @@ func RunAgent(
 	heartbeatInterval time.Duration,
 	heartbeatFn func(elapsed time.Duration),
 ) (time.Duration, error) {
+	return RunAgentWithOptions(agentCommand, projectRoot, RunOptions{
+		HeartbeatInterval: heartbeatInterval,
+		HeartbeatFn:       heartbeatFn,
+	})
+}
+
+// RunAgentWithOptions is RunAgent with the full set of RunOptions.
+//
+// The agent is started in its own process group so that any helper processes
+// it spawns are signalled along with it. A timed-out run returns an error
+// wrapping ErrAgentTimeout; a run stopped by a signal on opts.Interrupt returns
+// an error wrapping ErrAgentInterrupted.
+func RunAgentWithOptions(agentCommand, projectRoot string, opts RunOptions) (time.Duration, error) {
 	trimmed := strings.TrimSpace(agentCommand)
 	if trimmed == "" {
 		return 0, fmt.Errorf("agentCommand must not be empty or whitespace")
@@ -104,8 +109,26 @@ func RunAgent(
 
 	cmd := exec.Command(parts[0], parts[1:]...)
 	cmd.Dir = projectRoot
-	cmd.Stdout = os.Stdout
+	stdout := opts.Stdout
+	if stdout == nil {
+		stdout = os.Stdout
+	}
+	cmd.Stdout = stdout
 	cmd.Stderr = os.Stderr
+	var tees []io.Writer
+	for _, w := range []io.Writer{opts.Transcript, opts.Tail} {
+		if w != nil {
+			tees = append(tees, w)
+		}
+	}
+	if len(tees) > 0 {
+		cmd.Stdout = io.MultiWriter(append([]io.Writer{stdout}, tees...)...)
+		cmd.Stderr = io.MultiWriter(append([]io.Writer{os.Stderr}, tees...)...)
+		// Output is now copied through pipes; don't let a leftover child that
+		// inherited them keep Wait blocked after the agent itself exits.
+		cmd.WaitDelay = transcriptWaitDelay
+	}
+	setProcessGroup(cmd)
 
 	start := time.Now()
 	if err := cmd.Start(); err != nil {
@@ -113,16 +136,16 @@ func RunAgent(
 	}
 
 	var stopHeartbeat chan struct{}
-	if heartbeatInterval > 0 && heartbeatFn != nil {
+	if opts.HeartbeatInterval > 0 && opts.HeartbeatFn != nil {
 		stopHeartbeat = make(chan struct{})
-		ticker := time.NewTicker(heartbeatInterval)
+		ticker := time.NewTicker(opts.HeartbeatInterval)
 		defer ticker.Stop()
 
 		go func() {
 			for {
 				select {
 				case <-ticker.C:
-					heartbeatFn(time.Since(start))
+					opts.HeartbeatFn(time.Since(start))
 				case <-stopHeartbeat:
 					return
 				}
@@ -130,12 +153,40 @@ func RunAgent(
 		}()
 	}
 
-	waitErr := cmd.Wait()
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	var deadline <-chan time.Time
+	if opts.Timeout > 0 {
+		timer := time.NewTimer(opts.Timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	var waitErr error
+	timedOut := false
+	var interrupted os.Signal
+	select {
+	case waitErr = <-done:
+	case <-deadline:
+		timedOut = true
+		waitErr = stopProcessGroup(cmd, done, opts.KillGrace)
+	case interrupted = <-opts.Interrupt:
+		waitErr = forwardAndWait(cmd, done, interrupted, opts.KillGrace)
+	}
+
 	duration := time.Since(start)
 	if stopHeartbeat != nil {
 		close(stopHeartbeat)
 	}
 
+	if timedOut {
+		return duration, fmt.Errorf("%w after %s", ErrAgentTimeout, opts.Timeout)
+	}
+	if interrupted != nil {
+		return duration, fmt.Errorf("%w by %s", ErrAgentInterrupted, interrupted)
+	}
+
 	if waitErr != nil {
 		if exitErr, ok := waitErr.(*exec.ExitError); ok {
 			return duration, fmt.Errorf("agent exited with code %d", exitErr.ExitCode())
@@ -145,3 +196,42 @@ func RunAgent(
 
 	return duration, nil
 }
+
+// stopProcessGroup terminates the agent's process group and waits for the
+// agent to exit. SIGTERM is sent first; if the agent has not exited after
+// grace (DefaultKillGrace when zero), SIGKILL follows. done receives the
+// result of cmd.Wait.
+func stopProcessGroup(cmd *exec.Cmd, done <-chan error, grace time.Duration) error {
+	if grace <= 0 {
+		grace = DefaultKillGrace
+	}
+
+	_ = terminateProcessGroup(cmd)
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(grace):
+	}
+
+	_ = killProcessGroup(cmd)
+	return <-done
+}
+
+// forwardAndWait delivers sig to the agent's process group and waits for the
+// agent to exit, killing the group if it is still running after grace
+// (DefaultKillGrace when zero).
+func forwardAndWait(cmd *exec.Cmd, done <-chan error, sig os.Signal, grace time.Duration) error {
+	if grace <= 0 {
+		grace = DefaultKillGrace
+	}
+
+	_ = signalProcessGroup(cmd, sig)
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(grace):
+	}
+
+	_ = killProcessGroup(cmd)
+	return <-done
+}
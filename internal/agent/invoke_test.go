@@ -1,12 +1,17 @@
 package agent
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"sync/atomic"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -16,12 +21,19 @@ import (
 // instead of running the test suite. This allows the test binary to act as a
 // controllable agent command in RunAgent tests.
 func TestMain(m *testing.M) {
+	if v := os.Getenv("TEST_SUBPROCESS_OUTPUT"); v != "" {
+		fmt.Fprintln(os.Stdout, v)
+		fmt.Fprintln(os.Stderr, "stderr: "+v)
+	}
 	switch os.Getenv("TEST_SUBPROCESS_EXIT") {
 	case "0":
 		os.Exit(0)
 	case "1":
 		os.Exit(1)
 	}
+	if os.Getenv("TEST_SUBPROCESS_IGNORE_TERM") == "1" {
+		signal.Ignore(syscall.SIGTERM)
+	}
 	if v := os.Getenv("TEST_SUBPROCESS_SLEEP_MS"); v != "" {
 		ms, err := strconv.Atoi(v)
 		if err == nil && ms > 0 {
@@ -209,4 +221,123 @@ func TestRunAgent(t *testing.T) {
 			t.Fatalf("expected 0 heartbeats when disabled, got %d", got)
 		}
 	})
+
+	t.Run("timeout terminates the agent and returns ErrAgentTimeout", func(t *testing.T) {
+		t.Setenv("TEST_SUBPROCESS_SLEEP_MS", "5000")
+		cmd := fmt.Sprintf("%s -test.run=^$", testBin)
+
+		duration, err := RunAgentWithOptions(cmd, t.TempDir(), RunOptions{Timeout: 100 * time.Millisecond})
+		if !errors.Is(err, ErrAgentTimeout) {
+			t.Fatalf("expected ErrAgentTimeout, got: %v", err)
+		}
+		if duration >= 3*time.Second {
+			t.Errorf("agent should be stopped near the deadline, ran for %v", duration)
+		}
+	})
+
+	t.Run("timeout escalates to SIGKILL when SIGTERM is ignored", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("process groups and SIGTERM are not used on Windows")
+		}
+		t.Setenv("TEST_SUBPROCESS_SLEEP_MS", "5000")
+		t.Setenv("TEST_SUBPROCESS_IGNORE_TERM", "1")
+		cmd := fmt.Sprintf("%s -test.run=^$", testBin)
+
+		duration, err := RunAgentWithOptions(cmd, t.TempDir(), RunOptions{
+			Timeout:   100 * time.Millisecond,
+			KillGrace: 100 * time.Millisecond,
+		})
+		if !errors.Is(err, ErrAgentTimeout) {
+			t.Fatalf("expected ErrAgentTimeout, got: %v", err)
+		}
+		if duration >= 3*time.Second {
+			t.Errorf("SIGKILL escalation should stop the agent promptly, ran for %v", duration)
+		}
+	})
+
+	t.Run("agent finishing before the timeout is not an error", func(t *testing.T) {
+		t.Setenv("TEST_SUBPROCESS_EXIT", "0")
+		cmd := fmt.Sprintf("%s -test.run=^$", testBin)
+
+		if _, err := RunAgentWithOptions(cmd, t.TempDir(), RunOptions{Timeout: 5 * time.Second}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+
+	t.Run("interrupt is forwarded and returns ErrAgentInterrupted", func(t *testing.T) {
+		t.Setenv("TEST_SUBPROCESS_SLEEP_MS", "5000")
+		cmd := fmt.Sprintf("%s -test.run=^$", testBin)
+
+		interrupt := make(chan os.Signal, 1)
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			interrupt <- syscall.SIGTERM
+		}()
+
+		duration, err := RunAgentWithOptions(cmd, t.TempDir(), RunOptions{Interrupt: interrupt})
+		if !errors.Is(err, ErrAgentInterrupted) {
+			t.Fatalf("expected ErrAgentInterrupted, got: %v", err)
+		}
+		if duration >= 3*time.Second {
+			t.Errorf("forwarded signal should stop the agent promptly, ran for %v", duration)
+		}
+	})
+
+	t.Run("stdout option replaces os.Stdout", func(t *testing.T) {
+		t.Setenv("TEST_SUBPROCESS_OUTPUT", "hello from agent")
+		t.Setenv("TEST_SUBPROCESS_EXIT", "0")
+		cmd := fmt.Sprintf("%s -test.run=^$", testBin)
+
+		var stdout bytes.Buffer
+		if _, err := RunAgentWithOptions(cmd, t.TempDir(), RunOptions{Stdout: &stdout}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := stdout.String(); got != "hello from agent\n" {
+			t.Errorf("Stdout got %q, want only the agent's stdout", got)
+		}
+	})
+
+	t.Run("tail receives output without a transcript", func(t *testing.T) {
+		t.Setenv("TEST_SUBPROCESS_OUTPUT", "hello from agent")
+		t.Setenv("TEST_SUBPROCESS_EXIT", "0")
+		cmd := fmt.Sprintf("%s -test.run=^$", testBin)
+
+		tail := NewOutputTail(ResultTailBytes)
+		if _, err := RunAgentWithOptions(cmd, t.TempDir(), RunOptions{Tail: tail, Stdout: &bytes.Buffer{}}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		for _, want := range []string{"hello from agent\n", "stderr: hello from agent"} {
+			if !strings.Contains(string(tail.Bytes()), want) {
+				t.Errorf("tail missing %q: %q", want, tail.Bytes())
+			}
+		}
+	})
+
+	t.Run("transcript receives stdout and stderr", func(t *testing.T) {
+		t.Setenv("TEST_SUBPROCESS_OUTPUT", "hello from agent")
+		t.Setenv("TEST_SUBPROCESS_EXIT", "0")
+		cmd := fmt.Sprintf("%s -test.run=^$", testBin)
+
+		path := filepath.Join(t.TempDir(), "session-T1_attempt-1.log")
+		tr, err := OpenTranscript(path, TranscriptOptions{})
+		if err != nil {
+			t.Fatalf("OpenTranscript: %v", err)
+		}
+		if _, err := RunAgentWithOptions(cmd, t.TempDir(), RunOptions{Transcript: tr}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if err := tr.Close(); err != nil {
+			t.Fatalf("Close: %v", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("read transcript: %v", err)
+		}
+		for _, want := range []string{"hello from agent", "stderr: hello from agent"} {
+			if !strings.Contains(string(data), want) {
+				t.Errorf("transcript missing %q:\n%s", want, data)
+			}
+		}
+	})
 }
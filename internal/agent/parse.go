@@ -8,11 +8,13 @@ import (
 
 	"gopkg.in/yaml.v3"
 
+	"github.com/robertgumeny/doug/internal/log"
 	"github.com/robertgumeny/doug/internal/types"
 )
 
 // ErrNoFrontmatter is returned when the session file contains no YAML
-// frontmatter delimiters (--- ... ---).
+// frontmatter delimiters (--- ... ---), and by the JSON result channels when
+// they hold no result (see ReadSessionResult).
 var ErrNoFrontmatter = errors.New("no YAML frontmatter found")
 
 // ErrMissingOutcome is returned when the frontmatter is valid YAML but the
@@ -38,6 +40,10 @@ func (e *ErrInvalidOutcome) Error() string {
 //   - ErrNoFrontmatter    – no --- delimiters found
 //   - ErrMissingOutcome   – outcome field absent or empty
 //   - *ErrInvalidOutcome  – outcome is not one of the four valid values
+//
+// The optional self-report fields never make the result unparseable: a
+// malformed one is logged and dropped (see sanitizeSelfReport). Confidence is
+// lower-cased before it is checked.
 func ParseSessionResult(filePath string) (*types.SessionResult, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
@@ -76,19 +82,59 @@ func ParseSessionResult(filePath string) (*types.SessionResult, error) {
 
 	var result types.SessionResult
 	if err := yaml.Unmarshal([]byte(frontmatter), &result); err != nil {
-		return nil, fmt.Errorf("unmarshal frontmatter: %w", err)
+		// yaml.v3 still decodes every well-typed field; a mistyped self-report
+		// field is left empty, and a mistyped outcome fails validation below.
+		var typeErr *yaml.TypeError
+		if !errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("unmarshal frontmatter: %w", err)
+		}
+		log.Warning(fmt.Sprintf("session result: ignoring malformed field(s): %s", strings.Join(typeErr.Errors, "; ")))
 	}
 
+	if err := validateResult(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// validateResult checks the outcome of a parsed session result, whichever
+// channel it came from, and sanitizes its optional self-report.
+func validateResult(result *types.SessionResult) error {
 	if result.Outcome == "" {
-		return nil, ErrMissingOutcome
+		return ErrMissingOutcome
 	}
 
 	switch result.Outcome {
 	case types.OutcomeSuccess, types.OutcomeBug, types.OutcomeFailure, types.OutcomeEpicComplete:
 		// valid
 	default:
-		return nil, &ErrInvalidOutcome{Value: string(result.Outcome)}
+		return &ErrInvalidOutcome{Value: string(result.Outcome)}
 	}
 
-	return &result, nil
+	sanitizeSelfReport(result)
+	return nil
+}
+
+// sanitizeSelfReport normalises confidence to lower case and drops the
+// malformed parts of the optional self-report, logging a warning for each:
+// an invalid confidence is cleared and a criterion without text is removed.
+// The self-report is advisory, so it never turns a valid outcome into a
+// parse failure.
+func sanitizeSelfReport(result *types.SessionResult) {
+	result.Confidence = types.Confidence(strings.ToLower(strings.TrimSpace(string(result.Confidence))))
+	switch result.Confidence {
+	case "", types.ConfidenceLow, types.ConfidenceMedium, types.ConfidenceHigh:
+	default:
+		log.Warning(fmt.Sprintf("session result: ignoring confidence %q: must be one of low, medium, high", result.Confidence))
+		result.Confidence = ""
+	}
+	kept := result.AcceptanceCriteria[:0]
+	for i, c := range result.AcceptanceCriteria {
+		if strings.TrimSpace(c.Criterion) == "" {
+			log.Warning(fmt.Sprintf("session result: ignoring acceptance_criteria[%d]: criterion is empty", i))
+			continue
+		}
+		kept = append(kept, c)
+	}
+	result.AcceptanceCriteria = kept
 }
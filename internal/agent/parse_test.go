@@ -139,3 +139,84 @@ func TestParseSessionResult(t *testing.T) {
 		}
 	})
 }
+
+func TestParseSessionResult_SelfReport(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "session.md")
+	content := "---\n" +
+		"outcome: SUCCESS\n" +
+		"changelog_entry: \"Add refunds\"\n" +
+		"dependencies_added: []\n" +
+		"files_changed: [internal/refund.go, internal/refund_test.go]\n" +
+		"tests_added: [TestRefund_Partial]\n" +
+		"acceptance_criteria:\n" +
+		"  - criterion: Partial refunds are supported\n" +
+		"    met: true\n" +
+		"    evidence: TestRefund_Partial\n" +
+		"  - criterion: Refunds are audited\n" +
+		"notes_for_next_task: \"The audit log lives in internal/audit.\"\n" +
+		"confidence: High\n" +
+		"---\n"
+	if err := os.WriteFile(f, []byte(content), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	result, err := ParseSessionResult(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.FilesChanged) != 2 || len(result.TestsAdded) != 1 {
+		t.Errorf("files_changed = %v, tests_added = %v", result.FilesChanged, result.TestsAdded)
+	}
+	want := []types.CriterionResult{
+		{Criterion: "Partial refunds are supported", Met: true, Evidence: "TestRefund_Partial"},
+		{Criterion: "Refunds are audited"},
+	}
+	if len(result.AcceptanceCriteria) != 2 || result.AcceptanceCriteria[0] != want[0] || result.AcceptanceCriteria[1] != want[1] {
+		t.Errorf("acceptance_criteria = %+v, want %+v", result.AcceptanceCriteria, want)
+	}
+	if result.NotesForNextTask != "The audit log lives in internal/audit." {
+		t.Errorf("notes_for_next_task = %q", result.NotesForNextTask)
+	}
+	if result.Confidence != types.ConfidenceHigh {
+		t.Errorf("confidence = %q, want %q", result.Confidence, types.ConfidenceHigh)
+	}
+}
+
+func TestParseSessionResult_MalformedSelfReportIsDropped(t *testing.T) {
+	frontmatter := "outcome: SUCCESS\n" +
+		"confidence: 0.9\n" +
+		"tests_added: {TestRefund: true}\n" +
+		"acceptance_criteria:\n" +
+		"  - met: true\n" +
+		"  - criterion: Refunds are audited\n" +
+		"    met: true\n" +
+		"notes_for_next_task: kept\n"
+	f := filepath.Join(t.TempDir(), "session.md")
+	if err := os.WriteFile(f, []byte("---\n"+frontmatter+"---\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+
+	result, err := ParseSessionResult(f)
+	if err != nil {
+		t.Fatalf("a malformed self-report must not fail the parse: %v", err)
+	}
+	if result.Outcome != types.OutcomeSuccess || result.NotesForNextTask != "kept" {
+		t.Errorf("outcome = %q, notes = %q; want SUCCESS and the valid fields kept", result.Outcome, result.NotesForNextTask)
+	}
+	if result.Confidence != "" || result.TestsAdded != nil {
+		t.Errorf("confidence = %q, tests_added = %v; want both dropped", result.Confidence, result.TestsAdded)
+	}
+	if len(result.AcceptanceCriteria) != 1 || result.AcceptanceCriteria[0].Criterion != "Refunds are audited" {
+		t.Errorf("acceptance_criteria = %+v, want only the criterion with text", result.AcceptanceCriteria)
+	}
+}
+
+func TestParseSessionResult_MistypedOutcomeFails(t *testing.T) {
+	f := filepath.Join(t.TempDir(), "session.md")
+	if err := os.WriteFile(f, []byte("---\noutcome: [SUCCESS]\n---\n"), 0o644); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	if _, err := ParseSessionResult(f); !errors.Is(err, ErrMissingOutcome) {
+		t.Fatalf("expected ErrMissingOutcome, got: %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+//go:build windows
+
+package agent
+
+import (
+	"os"
+	"os/exec"
+)
+
+// setProcessGroup is a no-op on Windows; process groups are not used.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateProcessGroup kills the agent process. Windows has no SIGTERM
+// equivalent for console processes, so termination is immediate.
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// signalProcessGroup kills the agent process. Windows cannot deliver
+// arbitrary signals to another process, so sig is ignored.
+func signalProcessGroup(cmd *exec.Cmd, sig os.Signal) error {
+	return cmd.Process.Kill()
+}
+
+// killProcessGroup kills the agent process.
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,146 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/robertgumeny/doug/internal/config"
+	"github.com/robertgumeny/doug/internal/log"
+	"github.com/robertgumeny/doug/internal/types"
+)
+
+// Markers around the JSON session result an agent may print instead of, or
+// as well as, writing the session file.
+const (
+	ResultBeginMarker = "<<<DOUG_RESULT>>>"
+	ResultEndMarker   = "<<<END_DOUG_RESULT>>>"
+)
+
+// SessionJSONPath returns the JSON session result path that belongs to the
+// session file at sessionPath: the same name with a .json extension, e.g.
+//
+//	{logsDir}/sessions/{epic}/session-{taskID}_attempt-{attempt}.json
+func SessionJSONPath(sessionPath string) string {
+	return strings.TrimSuffix(sessionPath, ".md") + ".json"
+}
+
+// ReadSessionResult reads the agent's result from the channels in sources
+// (config.ResultFrontmatter, config.ResultJSON, config.ResultStdout), in
+// order, and returns the first valid one together with the source it came
+// from. output is the end of the agent's output read by ResultStdout (see
+// OutputTail); nil means no output was captured.
+//
+// When no source holds a valid result, the error of the first source that
+// had one but could not use it (bad syntax, invalid outcome or field) is
+// returned; otherwise that of the first source. It is prefixed with the
+// source name and keeps the typed errors of ParseSessionResult.
+func ReadSessionResult(sessionPath string, output []byte, sources []string) (*types.SessionResult, string, error) {
+	var first, specific error
+	for _, src := range sources {
+		var result *types.SessionResult
+		var err error
+		switch src {
+		case config.ResultFrontmatter:
+			result, err = ParseSessionResult(sessionPath)
+		case config.ResultJSON:
+			result, err = ParseSessionJSON(SessionJSONPath(sessionPath))
+		case config.ResultStdout:
+			if output == nil {
+				err = fmt.Errorf("agent output was not captured: %w", os.ErrNotExist)
+			} else {
+				result, err = ParseResultFromOutput(output)
+			}
+		default:
+			err = fmt.Errorf("unknown result source %q", src)
+		}
+		if err == nil {
+			return result, src, nil
+		}
+
+		err = fmt.Errorf("%s: %w", src, err)
+		if first == nil {
+			first = err
+		}
+		if specific == nil && !isAbsentResult(err) {
+			specific = err
+		}
+	}
+	if specific != nil {
+		return nil, "", specific
+	}
+	if first == nil {
+		first = errors.New("no result sources configured")
+	}
+	return nil, "", first
+}
+
+// isAbsentResult reports whether err means a source simply held no result,
+// as opposed to holding a broken one.
+func isAbsentResult(err error) bool {
+	return errors.Is(err, os.ErrNotExist) || errors.Is(err, ErrNoFrontmatter) || errors.Is(err, ErrMissingOutcome)
+}
+
+// ParseSessionJSON reads a session result written as a JSON object with the
+// same fields as the front-matter. It returns the same typed errors as
+// ParseSessionResult; an empty file counts as ErrNoFrontmatter.
+func ParseSessionJSON(path string) (*types.SessionResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err // caller uses errors.Is(err, os.ErrNotExist)
+	}
+	return decodeResultJSON(string(data))
+}
+
+// ParseResultFromOutput extracts the last JSON block between
+// ResultBeginMarker and ResultEndMarker from captured agent output. Escape
+// sequences are ignored, and a block that was itself printed inside a JSON
+// string (as agents with a JSON output format do) is unquoted. It returns the
+// same typed errors as ParseSessionResult; output without a complete block
+// counts as ErrNoFrontmatter.
+func ParseResultFromOutput(data []byte) (*types.SessionResult, error) {
+	var ansi ansiState
+	output := string(ansi.strip(data))
+
+	start := strings.LastIndex(output, ResultBeginMarker)
+	if start == -1 {
+		return nil, fmt.Errorf("no %s block in agent output: %w", ResultBeginMarker, ErrNoFrontmatter)
+	}
+	block := output[start+len(ResultBeginMarker):]
+	end := strings.Index(block, ResultEndMarker)
+	if end == -1 {
+		return nil, fmt.Errorf("%s without %s in agent output: %w", ResultBeginMarker, ResultEndMarker, ErrNoFrontmatter)
+	}
+	block = block[:end]
+
+	result, err := decodeResultJSON(block)
+	if err != nil && strings.Contains(block, `\"`) {
+		if unquoted, uerr := strconv.Unquote(`"` + strings.TrimSpace(block) + `"`); uerr == nil {
+			return decodeResultJSON(unquoted)
+		}
+	}
+	return result, err
+}
+
+// decodeResultJSON unmarshals and validates a JSON session result.
+func decodeResultJSON(data string) (*types.SessionResult, error) {
+	if strings.TrimSpace(data) == "" {
+		return nil, ErrNoFrontmatter
+	}
+	var result types.SessionResult
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		// As with YAML, a mistyped field is skipped and the rest decoded.
+		var typeErr *json.UnmarshalTypeError
+		if !errors.As(err, &typeErr) {
+			return nil, fmt.Errorf("unmarshal JSON result: %w", err)
+		}
+		log.Warning(fmt.Sprintf("session result: ignoring malformed field %s: %v", typeErr.Field, err))
+	}
+	if err := validateResult(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
@@ -0,0 +1,157 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robertgumeny/doug/internal/config"
+	"github.com/robertgumeny/doug/internal/types"
+)
+
+// sessionFile returns the path of a session file, as CreateSessionFile leaves
+// it, in a fresh directory.
+func sessionFile(t *testing.T) string {
+	t.Helper()
+	sessionPath := filepath.Join(t.TempDir(), "session-EPIC-1-001_attempt-1.md")
+	write(t, sessionPath, "---\ntask_id: \"EPIC-1-001\"\noutcome: \"\"\nchangelog_entry: \"\"\n---\n")
+	return sessionPath
+}
+
+func write(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestSessionJSONPath(t *testing.T) {
+	got := SessionJSONPath("logs/sessions/EPIC-1/session-EPIC-1-001_attempt-2.md")
+	if want := "logs/sessions/EPIC-1/session-EPIC-1-001_attempt-2.json"; got != want {
+		t.Errorf("SessionJSONPath = %q, want %q", got, want)
+	}
+}
+
+func TestReadSessionResult_FallsBackInOrder(t *testing.T) {
+	sessionPath := sessionFile(t)
+	write(t, SessionJSONPath(sessionPath), `{"task_id": "EPIC-1-001", "outcome": "SUCCESS", "changelog_entry": "Add widgets",
+		"acceptance_criteria": [{"criterion": "widgets render", "met": true}]}`)
+
+	result, source, err := ReadSessionResult(sessionPath, nil, config.DefaultResultSources)
+	if err != nil {
+		t.Fatalf("ReadSessionResult: %v", err)
+	}
+	if source != config.ResultJSON {
+		t.Errorf("source = %q, want %q", source, config.ResultJSON)
+	}
+	if result.Outcome != types.OutcomeSuccess || result.ChangelogEntry != "Add widgets" {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.AcceptanceCriteria) != 1 || !result.AcceptanceCriteria[0].Met {
+		t.Errorf("AcceptanceCriteria = %+v", result.AcceptanceCriteria)
+	}
+
+	// A filled-in front-matter wins when it comes first.
+	write(t, sessionPath, "---\noutcome: \"BUG\"\n---\n")
+	if result, source, err = ReadSessionResult(sessionPath, nil, config.DefaultResultSources); err != nil || source != config.ResultFrontmatter || result.Outcome != types.OutcomeBug {
+		t.Errorf("got (%+v, %q, %v), want the front-matter BUG result", result, source, err)
+	}
+}
+
+func TestReadSessionResult_Stdout(t *testing.T) {
+	sessionPath := sessionFile(t)
+	tail := NewOutputTail(ResultTailBytes)
+	fmt.Fprint(tail, "working...\n"+
+		ResultBeginMarker+`{"outcome": "FAILURE"}`+ResultEndMarker+"\n"+
+		"\x1b[32mdone\x1b[0m\n"+
+		ResultBeginMarker+"\n{\"outcome\": \"SUCCESS\", \"changelog_entry\": \"Fix parser\"}\n"+ResultEndMarker+"\n")
+
+	result, source, err := ReadSessionResult(sessionPath, tail.Bytes(), config.DefaultResultSources)
+	if err != nil {
+		t.Fatalf("ReadSessionResult: %v", err)
+	}
+	if source != config.ResultStdout {
+		t.Errorf("source = %q, want %q", source, config.ResultStdout)
+	}
+	if result.Outcome != types.OutcomeSuccess || result.ChangelogEntry != "Fix parser" {
+		t.Errorf("result = %+v, want the last block", result)
+	}
+}
+
+func TestParseResultFromOutput_EscapedBlock(t *testing.T) {
+	// An agent with a JSON output format prints the block inside a string.
+	output := `{"type":"result","result":"All done.\n` + ResultBeginMarker + `\n{\"outcome\": \"EPIC_COMPLETE\"}\n` + ResultEndMarker + `"}` + "\n"
+
+	result, err := ParseResultFromOutput([]byte(output))
+	if err != nil {
+		t.Fatalf("ParseResultFromOutput: %v", err)
+	}
+	if result.Outcome != types.OutcomeEpicComplete {
+		t.Errorf("Outcome = %q, want %q", result.Outcome, types.OutcomeEpicComplete)
+	}
+}
+
+func TestReadSessionResult_Errors(t *testing.T) {
+	t.Run("nothing written", func(t *testing.T) {
+		sessionPath := sessionFile(t)
+
+		_, _, err := ReadSessionResult(sessionPath, []byte("no result here\n"), config.DefaultResultSources)
+		if !errors.Is(err, ErrMissingOutcome) {
+			t.Errorf("err = %v, want ErrMissingOutcome from the front-matter", err)
+		}
+	})
+
+	t.Run("invalid outcome beats absent results", func(t *testing.T) {
+		sessionPath := sessionFile(t)
+		write(t, SessionJSONPath(sessionPath), `{"outcome": "DONE"}`)
+
+		_, _, err := ReadSessionResult(sessionPath, nil, config.DefaultResultSources)
+		var invalid *ErrInvalidOutcome
+		if !errors.As(err, &invalid) || invalid.Value != "DONE" {
+			t.Errorf("err = %v, want *ErrInvalidOutcome for DONE", err)
+		}
+		if err == nil || !strings.HasPrefix(err.Error(), config.ResultJSON+": ") {
+			t.Errorf("err = %v, want it prefixed with the source", err)
+		}
+	})
+
+	t.Run("unclosed block", func(t *testing.T) {
+		if _, err := ParseResultFromOutput([]byte(ResultBeginMarker + `{"outcome": "SUCCESS"}`)); !errors.Is(err, ErrNoFrontmatter) {
+			t.Errorf("err = %v, want ErrNoFrontmatter", err)
+		}
+	})
+
+	t.Run("mistyped self-report field is dropped", func(t *testing.T) {
+		sessionPath := sessionFile(t)
+		write(t, SessionJSONPath(sessionPath), `{"outcome": "SUCCESS", "confidence": 0.9, "notes_for_next_task": "kept"}`)
+
+		result, err := ParseSessionJSON(SessionJSONPath(sessionPath))
+		if err != nil {
+			t.Fatalf("ParseSessionJSON: %v", err)
+		}
+		if result.Outcome != types.OutcomeSuccess || result.Confidence != "" || result.NotesForNextTask != "kept" {
+			t.Errorf("result = %+v, want SUCCESS without the confidence", result)
+		}
+	})
+
+	t.Run("malformed JSON", func(t *testing.T) {
+		sessionPath := sessionFile(t)
+		write(t, SessionJSONPath(sessionPath), `{"outcome": "SUCCESS",`)
+
+		if _, err := ParseSessionJSON(SessionJSONPath(sessionPath)); err == nil || !strings.Contains(err.Error(), "unmarshal") {
+			t.Errorf("err = %v, want an unmarshal error", err)
+		}
+	})
+
+	t.Run("stdout not captured", func(t *testing.T) {
+		sessionPath := sessionFile(t)
+
+		_, _, err := ReadSessionResult(sessionPath, nil, []string{config.ResultStdout})
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("err = %v, want os.ErrNotExist", err)
+		}
+	})
+}
@@ -0,0 +1,214 @@
+package agent
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// TranscriptPath returns the transcript file path that belongs to the session
+// file at sessionPath: the same name with a .log extension, e.g.
+//
+//	{logsDir}/sessions/{epic}/session-{taskID}_attempt-{attempt}.log
+func TranscriptPath(sessionPath string) string {
+	return strings.TrimSuffix(sessionPath, ".md") + ".log"
+}
+
+// TranscriptOptions controls what is written to a transcript file.
+type TranscriptOptions struct {
+	// StripANSI removes terminal escape sequences (colors, cursor movement)
+	// so the transcript reads cleanly in an editor.
+	StripANSI bool
+
+	// MaxBytes caps the transcript size. Output beyond the cap is dropped and
+	// a single truncation marker is written. Zero means no cap.
+	MaxBytes int64
+}
+
+// Transcript is an io.Writer that records agent output to a file. It is safe
+// for concurrent use by the stdout and stderr copiers of one agent process.
+//
+// Write never returns an error: a transcript problem must not interrupt the
+// agent or the live terminal output it is teed from. The first I/O error is
+// reported by Close instead.
+type Transcript struct {
+	mu        sync.Mutex
+	f         *os.File
+	path      string
+	opts      TranscriptOptions
+	written   int64
+	truncated bool
+	ansi      ansiState
+	err       error
+}
+
+// OpenTranscript creates (or truncates) the transcript file at path.
+func OpenTranscript(path string, opts TranscriptOptions) (*Transcript, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("create transcript %s: %w", path, err)
+	}
+	return &Transcript{f: f, path: path, opts: opts}, nil
+}
+
+// Path returns the transcript file path.
+func (t *Transcript) Path() string {
+	return t.path
+}
+
+// Write records p, stripping escape sequences and enforcing the size cap as
+// configured. It always reports len(p) bytes written.
+func (t *Transcript) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.err != nil || t.truncated {
+		return len(p), nil
+	}
+
+	out := p
+	if t.opts.StripANSI {
+		out = t.ansi.strip(p)
+	}
+
+	if t.opts.MaxBytes > 0 && t.written+int64(len(out)) > t.opts.MaxBytes {
+		out = out[:t.opts.MaxBytes-t.written]
+		t.truncated = true
+	}
+
+	n, err := t.f.Write(out)
+	t.written += int64(n)
+	if err != nil {
+		t.err = err
+		return len(p), nil
+	}
+	if t.truncated {
+		marker := fmt.Sprintf("\n[doug: transcript truncated at %d bytes]\n", t.opts.MaxBytes)
+		if _, err := t.f.WriteString(marker); err != nil {
+			t.err = err
+		}
+	}
+	return len(p), nil
+}
+
+// Close closes the transcript file and returns the first error encountered
+// while writing, if any.
+func (t *Transcript) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	closeErr := t.f.Close()
+	if t.err != nil {
+		return fmt.Errorf("write transcript %s: %w", t.path, t.err)
+	}
+	if closeErr != nil {
+		return fmt.Errorf("close transcript %s: %w", t.path, closeErr)
+	}
+	return nil
+}
+
+// ResultTailBytes is how much of the end of the agent's output run keeps in
+// an OutputTail for the stdout result channel: far more than any result
+// block, and independent of transcript_max_bytes.
+const ResultTailBytes = 1 << 20
+
+// OutputTail is an io.Writer that keeps the last max bytes of agent output,
+// escape sequences removed, in memory. Unlike a Transcript, which keeps the
+// head of the output and may not exist at all, it always holds the end of
+// the output, where the result block is printed. It is safe for concurrent
+// use and Write never fails.
+type OutputTail struct {
+	mu   sync.Mutex
+	max  int
+	buf  []byte
+	ansi ansiState
+}
+
+// NewOutputTail returns an OutputTail that keeps the last max bytes.
+func NewOutputTail(max int) *OutputTail {
+	return &OutputTail{max: max}
+}
+
+// Write appends p, without escape sequences, dropping what falls out of the
+// tail. It always reports len(p) bytes written.
+func (t *OutputTail) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.buf = append(t.buf, t.ansi.strip(p)...)
+	// Let the buffer run to twice the limit before dropping the head, so
+	// steady output does not move the whole tail on every write.
+	if len(t.buf) > 2*t.max {
+		t.buf = t.buf[:copy(t.buf, t.buf[len(t.buf)-t.max:])]
+	}
+	return len(p), nil
+}
+
+// Bytes returns a copy of the last max bytes written. It is never nil.
+func (t *OutputTail) Bytes() []byte {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tail := t.buf
+	if len(tail) > t.max {
+		tail = tail[len(tail)-t.max:]
+	}
+	return append([]byte{}, tail...)
+}
+
+// ansiState is a small state machine that removes ANSI escape sequences from a
+// byte stream. State is kept between calls because a sequence may be split
+// across two writes.
+type ansiState int
+
+const (
+	ansiText      ansiState = iota
+	ansiEscape              // saw ESC
+	ansiCSI                 // inside ESC [ ... final byte
+	ansiOSC                 // inside ESC ] ... BEL or ESC \
+	ansiOSCEscape           // saw ESC inside an OSC sequence
+)
+
+// strip returns p with escape sequences removed, advancing the state.
+func (s *ansiState) strip(p []byte) []byte {
+	out := make([]byte, 0, len(p))
+	for _, b := range p {
+		switch *s {
+		case ansiText:
+			if b == 0x1b {
+				*s = ansiEscape
+			} else {
+				out = append(out, b)
+			}
+		case ansiEscape:
+			switch b {
+			case '[':
+				*s = ansiCSI
+			case ']':
+				*s = ansiOSC
+			default:
+				// Two-byte sequence such as ESC 7 or ESC =.
+				*s = ansiText
+			}
+		case ansiCSI:
+			if b >= 0x40 && b <= 0x7e {
+				*s = ansiText
+			}
+		case ansiOSC:
+			switch b {
+			case 0x07:
+				*s = ansiText
+			case 0x1b:
+				*s = ansiOSCEscape
+			}
+		case ansiOSCEscape:
+			if b == '\\' {
+				*s = ansiText
+			} else {
+				*s = ansiOSC
+			}
+		}
+	}
+	return out
+}
@@ -0,0 +1,97 @@
+package agent
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTranscriptPath(t *testing.T) {
+	got := TranscriptPath(filepath.Join("logs", "sessions", "EPIC-1", "session-EPIC-1-001_attempt-2.md"))
+	want := filepath.Join("logs", "sessions", "EPIC-1", "session-EPIC-1-001_attempt-2.log")
+	if got != want {
+		t.Errorf("TranscriptPath = %q, want %q", got, want)
+	}
+}
+
+// writeTranscript writes each chunk to a new transcript and returns the file contents.
+func writeTranscript(t *testing.T, opts TranscriptOptions, chunks ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "session.log")
+	tr, err := OpenTranscript(path, opts)
+	if err != nil {
+		t.Fatalf("OpenTranscript: %v", err)
+	}
+	for _, c := range chunks {
+		n, err := tr.Write([]byte(c))
+		if err != nil || n != len(c) {
+			t.Fatalf("Write(%q) = %d, %v; want %d, nil", c, n, err, len(c))
+		}
+	}
+	if err := tr.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read transcript: %v", err)
+	}
+	return string(data)
+}
+
+func TestTranscript_StripANSI(t *testing.T) {
+	tests := []struct {
+		name   string
+		chunks []string
+		want   string
+	}{
+		{"color codes", []string{"\x1b[1;32mok\x1b[0m done"}, "ok done"},
+		{"sequence split across writes", []string{"a\x1b[3", "1mb\x1b", "[0mc"}, "abc"},
+		{"OSC title with BEL", []string{"\x1b]0;title\x07text"}, "text"},
+		{"OSC hyperlink with ST", []string{"\x1b]8;;http://x\x1b\\link"}, "link"},
+		{"plain text untouched", []string{"line 1\nline 2\n"}, "line 1\nline 2\n"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := writeTranscript(t, TranscriptOptions{StripANSI: true}, tc.chunks...)
+			if got != tc.want {
+				t.Errorf("got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestTranscript_KeepsANSIWhenNotStripping(t *testing.T) {
+	in := "\x1b[31mred\x1b[0m"
+	if got := writeTranscript(t, TranscriptOptions{}, in); got != in {
+		t.Errorf("got %q, want %q", got, in)
+	}
+}
+
+func TestTranscript_MaxBytesTruncates(t *testing.T) {
+	got := writeTranscript(t, TranscriptOptions{MaxBytes: 10}, "0123456", "789abcdef", "more output")
+	if !strings.HasPrefix(got, "0123456789\n") {
+		t.Errorf("expected first 10 bytes to be kept, got %q", got)
+	}
+	if strings.Count(got, "transcript truncated") != 1 {
+		t.Errorf("expected exactly one truncation marker, got %q", got)
+	}
+	if strings.Contains(got, "more output") {
+		t.Errorf("output past the cap must be dropped, got %q", got)
+	}
+}
+
+func TestOutputTail_KeepsEndOfOutput(t *testing.T) {
+	tail := NewOutputTail(10)
+	if got := tail.Bytes(); got == nil || len(got) != 0 {
+		t.Errorf("empty tail = %#v, want non-nil and empty", got)
+	}
+	for _, chunk := range []string{"0123456", "\x1b[31m789abcdef\x1b[0m", "ghijklmnopqrstuvwxyz", "ABC"} {
+		if n, err := tail.Write([]byte(chunk)); n != len(chunk) || err != nil {
+			t.Fatalf("Write = (%d, %v), want (%d, nil)", n, err, len(chunk))
+		}
+	}
+	if got := string(tail.Bytes()); got != "tuvwxyzABC" {
+		t.Errorf("tail = %q, want the last 10 bytes without escape sequences", got)
+	}
+}
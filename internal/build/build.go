@@ -20,6 +20,11 @@ type BuildSystem interface {
 	// Test runs the project's test suite.
 	Test() error
 
+	// Lint runs static analysis. Implementations return nil when no linter
+	// applies to the project. Whether a failure blocks the task is decided by
+	// the caller (the lint setting in doug.yaml), not the implementation.
+	Lint() error
+
 	// IsInitialized reports whether the build system has been initialized for the project.
 	IsInitialized() bool
 }
@@ -74,6 +79,17 @@ func (g *GoBuildSystem) Test() error {
 	return nil
 }
 
+// Lint runs go vet ./... and returns an error containing the last 50 lines of output on failure.
+func (g *GoBuildSystem) Lint() error {
+	cmd := exec.Command("go", "vet", "./...")
+	cmd.Dir = g.projectRoot
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return wrapOutput(err, out)
+	}
+	return nil
+}
+
 // wrapOutput returns an error that includes the last 50 lines of command output.
 func wrapOutput(err error, output []byte) error {
 	lines := strings.Split(string(output), "\n")
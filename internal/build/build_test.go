@@ -118,3 +118,17 @@ func TestGoBuildSystemInstallFailsWithoutGoMod(t *testing.T) {
 		t.Error("expected Install to fail in a directory with no go.mod")
 	}
 }
+
+func TestGoBuildSystemLintFailureIncludesOutput(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "go.mod", "module example\n\ngo 1.21\n")
+	writeFile(t, dir, "main.go", "package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Printf(\"%d\\n\", \"not a number\") }\n")
+
+	err := build.NewGoBuildSystem(dir).Lint()
+	if err == nil {
+		t.Fatal("expected go vet to report the Printf mismatch")
+	}
+	if !strings.Contains(err.Error(), "Printf") {
+		t.Errorf("error should include go vet output, got: %v", err)
+	}
+}
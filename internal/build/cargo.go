@@ -0,0 +1,57 @@
+package build
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+)
+
+// CargoBuildSystem implements BuildSystem for Rust projects using cargo commands.
+// All commands use exec.Command with an explicit args slice — no shell eval.
+type CargoBuildSystem struct {
+	projectRoot string
+}
+
+// NewCargoBuildSystem creates a CargoBuildSystem rooted at projectRoot.
+func NewCargoBuildSystem(projectRoot string) *CargoBuildSystem {
+	return &CargoBuildSystem{projectRoot: projectRoot}
+}
+
+// IsInitialized returns true if Cargo.lock exists in the project root.
+func (c *CargoBuildSystem) IsInitialized() bool {
+	_, err := os.Stat(filepath.Join(c.projectRoot, "Cargo.lock"))
+	return err == nil
+}
+
+// Install runs cargo fetch to download all crates listed in Cargo.toml.
+func (c *CargoBuildSystem) Install() error {
+	return c.run("fetch")
+}
+
+// Build runs cargo build --all-targets and returns an error containing the last 50 lines of output on failure.
+// --all-targets compiles tests, examples and benches too, so they cannot rot unnoticed.
+func (c *CargoBuildSystem) Build() error {
+	return c.run("build", "--all-targets")
+}
+
+// Test runs cargo test and returns an error containing the last 50 lines of output on failure.
+func (c *CargoBuildSystem) Test() error {
+	return c.run("test")
+}
+
+// Lint runs cargo clippy --all-targets with warnings denied and returns an error
+// containing the last 50 lines of output on failure.
+func (c *CargoBuildSystem) Lint() error {
+	return c.run("clippy", "--all-targets", "--", "-D", "warnings")
+}
+
+// run executes cargo with args in the project root.
+func (c *CargoBuildSystem) run(args ...string) error {
+	cmd := exec.Command("cargo", args...)
+	cmd.Dir = c.projectRoot
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return wrapOutput(err, out)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+package build_test
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/robertgumeny/doug/internal/build"
+)
+
+func TestCargoBuildSystemIsInitialized_FalseWhenCargoLockMissing(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "Cargo.toml", "[package]\nname = \"example\"\n")
+	c := build.NewCargoBuildSystem(dir)
+	if c.IsInitialized() {
+		t.Error("expected IsInitialized to return false when Cargo.lock does not exist")
+	}
+}
+
+func TestCargoBuildSystemIsInitialized_TrueWhenCargoLockExists(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "Cargo.lock", "")
+	c := build.NewCargoBuildSystem(dir)
+	if !c.IsInitialized() {
+		t.Error("expected IsInitialized to return true when Cargo.lock exists")
+	}
+}
+
+// requireCargo skips the test when the cargo toolchain is not installed.
+func requireCargo(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("cargo"); err != nil {
+		t.Skip("cargo not on PATH")
+	}
+}
+
+// writeCargoCrate writes a minimal binary crate whose main.rs is mainRS.
+func writeCargoCrate(t *testing.T, dir, mainRS string) {
+	t.Helper()
+	writeFile(t, dir, "main.rs", mainRS)
+	writeFile(t, dir, "Cargo.toml", "[package]\nname = \"example\"\nversion = \"0.1.0\"\nedition = \"2021\"\n\n[[bin]]\nname = \"example\"\npath = \"main.rs\"\n")
+}
+
+func TestCargoBuildSystemBuildAndTestSucceed(t *testing.T) {
+	requireCargo(t)
+	dir := t.TempDir()
+	writeCargoCrate(t, dir, "fn main() {}\n\n#[test]\nfn passes() {}\n")
+
+	c := build.NewCargoBuildSystem(dir)
+	if err := c.Build(); err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if err := c.Test(); err != nil {
+		t.Fatalf("Test: %v", err)
+	}
+}
+
+func TestCargoBuildSystemBuildFailureIncludesOutput(t *testing.T) {
+	requireCargo(t)
+	dir := t.TempDir()
+	writeCargoCrate(t, dir, "fn main() { undefined_fn(); }\n")
+
+	err := build.NewCargoBuildSystem(dir).Build()
+	if err == nil {
+		t.Fatal("expected build error")
+	}
+	if !strings.Contains(err.Error(), "undefined_fn") {
+		t.Errorf("error should include compiler output, got: %v", err)
+	}
+}
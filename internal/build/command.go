@@ -0,0 +1,159 @@
+package build
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/robertgumeny/doug/internal/config"
+	"github.com/robertgumeny/doug/internal/shellargs"
+)
+
+// CommandBuildSystem implements BuildSystem by running command lines declared
+// under build_commands in doug.yaml. Each line is tokenized with
+// shellargs.Split and run via exec.Command — no shell eval.
+type CommandBuildSystem struct {
+	projectRoot string
+	commands    config.BuildCommandsConfig
+}
+
+// NewCommandBuildSystem creates a CommandBuildSystem rooted at projectRoot.
+// It returns an error when build or test is missing or any configured line
+// cannot be tokenized, so misconfiguration is reported before the loop starts.
+func NewCommandBuildSystem(projectRoot string, commands config.BuildCommandsConfig) (*CommandBuildSystem, error) {
+	var problems []string
+	if strings.TrimSpace(commands.Build) == "" {
+		problems = append(problems, "build_commands.build is required")
+	}
+	if strings.TrimSpace(commands.Test) == "" {
+		problems = append(problems, "build_commands.test is required")
+	}
+	for _, c := range []struct{ key, line string }{
+		{"install", commands.Install},
+		{"build", commands.Build},
+		{"test", commands.Test},
+		{"lint", commands.Lint},
+	} {
+		if _, err := shellargs.Split(c.line); err != nil {
+			problems = append(problems, fmt.Sprintf("build_commands.%s: %v", c.key, err))
+		}
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid command build system: %s", strings.Join(problems, "; "))
+	}
+	return &CommandBuildSystem{projectRoot: projectRoot, commands: commands}, nil
+}
+
+// IsInitialized returns true if the initialized_when marker exists in the
+// project root, or unconditionally when no marker is configured.
+func (c *CommandBuildSystem) IsInitialized() bool {
+	if c.commands.InitializedWhen == "" {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(c.projectRoot, c.commands.InitializedWhen))
+	return err == nil
+}
+
+// Install runs build_commands.install. Returns nil (skip) if it is not configured.
+func (c *CommandBuildSystem) Install() error {
+	return c.run(c.commands.Install)
+}
+
+// Build runs build_commands.build and returns an error containing the last 50 lines of output on failure.
+func (c *CommandBuildSystem) Build() error {
+	return c.run(c.commands.Build)
+}
+
+// Test runs build_commands.test and returns an error containing the last 50 lines of output on failure.
+func (c *CommandBuildSystem) Test() error {
+	return c.run(c.commands.Test)
+}
+
+// Lint runs build_commands.lint. Returns nil (skip) if it is not configured.
+func (c *CommandBuildSystem) Lint() error {
+	return c.run(c.commands.Lint)
+}
+
+// run executes line in the project root.
+func (c *CommandBuildSystem) run(line string) error {
+	return runCommandLine(c.projectRoot, line)
+}
+
+// lintCommandBuildSystem replaces the Lint stage of another BuildSystem with
+// the lint_command line from doug.yaml.
+type lintCommandBuildSystem struct {
+	BuildSystem
+	projectRoot string
+	line        string
+}
+
+// Lint runs the configured lint_command.
+func (l *lintCommandBuildSystem) Lint() error {
+	return runCommandLine(l.projectRoot, l.line)
+}
+
+// runCommandLine tokenizes line with shellargs.Split and executes it in dir.
+// An empty line is a no-op. Failures include the last 50 lines of output.
+func runCommandLine(dir, line string) error {
+	parts, err := shellargs.Split(strings.TrimSpace(line))
+	if err != nil {
+		return err
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	cmd := exec.Command(parts[0], parts[1:]...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return wrapOutput(err, out)
+	}
+	return nil
+}
+
+// CommandBinaries returns the executable named by each configured command line
+// in commands, de-duplicated and in install/build/test/lint order. Lines that
+// are empty or cannot be tokenized are skipped.
+func CommandBinaries(commands config.BuildCommandsConfig) []string {
+	var bins []string
+	seen := make(map[string]bool)
+	for _, line := range []string{commands.Install, commands.Build, commands.Test, commands.Lint} {
+		parts, err := shellargs.Split(strings.TrimSpace(line))
+		if err != nil || len(parts) == 0 || seen[parts[0]] {
+			continue
+		}
+		seen[parts[0]] = true
+		bins = append(bins, parts[0])
+	}
+	return bins
+}
+
+// NewBuildSystemFromConfig returns the BuildSystem selected by cfg.BuildSystem.
+// It handles the types that take settings from doug.yaml ("command", and
+// "python" with python_type_check) and defers to NewBuildSystem for the rest.
+// A non-empty cfg.LintCommand replaces the selected system's Lint stage.
+func NewBuildSystemFromConfig(cfg *config.OrchestratorConfig, projectRoot string) (BuildSystem, error) {
+	var bs BuildSystem
+	var err error
+	switch cfg.BuildSystem {
+	case "command":
+		bs, err = NewCommandBuildSystem(projectRoot, cfg.BuildCommands)
+	case "python":
+		bs = NewPythonBuildSystem(projectRoot, cfg.PythonTypeCheck)
+	default:
+		bs, err = NewBuildSystem(cfg.BuildSystem, projectRoot)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(cfg.LintCommand) != "" {
+		if _, err := shellargs.Split(cfg.LintCommand); err != nil {
+			return nil, fmt.Errorf("lint_command: %w", err)
+		}
+		bs = &lintCommandBuildSystem{BuildSystem: bs, projectRoot: projectRoot, line: cfg.LintCommand}
+	}
+	return bs, nil
+}
@@ -0,0 +1,147 @@
+package build_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/robertgumeny/doug/internal/build"
+	"github.com/robertgumeny/doug/internal/config"
+)
+
+func TestNewCommandBuildSystem_RequiresBuildAndTest(t *testing.T) {
+	_, err := build.NewCommandBuildSystem(t.TempDir(), config.BuildCommandsConfig{Install: "make deps"})
+	if err == nil {
+		t.Fatal("expected error when build and test are missing")
+	}
+	for _, want := range []string{"build_commands.build", "build_commands.test"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should mention %q, got: %v", want, err)
+		}
+	}
+}
+
+func TestNewCommandBuildSystem_RejectsUnterminatedQuote(t *testing.T) {
+	_, err := build.NewCommandBuildSystem(t.TempDir(), config.BuildCommandsConfig{
+		Build: "go build ./...",
+		Test:  `go test "./...`,
+	})
+	if err == nil || !strings.Contains(err.Error(), "build_commands.test") {
+		t.Fatalf("expected tokenization error for test command, got: %v", err)
+	}
+}
+
+func TestCommandBuildSystemIsInitialized(t *testing.T) {
+	dir := t.TempDir()
+	cmds := config.BuildCommandsConfig{Build: "go version", Test: "go version"}
+
+	always, err := build.NewCommandBuildSystem(dir, cmds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !always.IsInitialized() {
+		t.Error("expected IsInitialized to be true when initialized_when is empty")
+	}
+
+	cmds.InitializedWhen = ".deps-installed"
+	marked, err := build.NewCommandBuildSystem(dir, cmds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if marked.IsInitialized() {
+		t.Error("expected IsInitialized to be false before the marker exists")
+	}
+	writeFile(t, dir, ".deps-installed", "")
+	if !marked.IsInitialized() {
+		t.Error("expected IsInitialized to be true once the marker exists")
+	}
+}
+
+func TestCommandBuildSystem_RunsConfiguredCommands(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "go.mod", "module example\n\ngo 1.21\n")
+	writeFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+
+	c, err := build.NewCommandBuildSystem(dir, config.BuildCommandsConfig{
+		Build: "go build ./...",
+		Test:  `go vet "./..."`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Install(); err != nil {
+		t.Errorf("Install with no command should be a no-op, got: %v", err)
+	}
+	if err := c.Build(); err != nil {
+		t.Errorf("Build: %v", err)
+	}
+	if err := c.Test(); err != nil {
+		t.Errorf("Test: %v", err)
+	}
+	if err := c.Lint(); err != nil {
+		t.Errorf("Lint with no command should be a no-op, got: %v", err)
+	}
+}
+
+func TestCommandBuildSystem_FailureIncludesOutput(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "go.mod", "module example\n\ngo 1.21\n")
+	writeFile(t, dir, "main.go", "package main\n\nfunc main() { undefined() }\n")
+
+	c, err := build.NewCommandBuildSystem(dir, config.BuildCommandsConfig{Build: "go build ./...", Test: "go test ./..."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = c.Build()
+	if err == nil {
+		t.Fatal("expected build error")
+	}
+	if !strings.Contains(err.Error(), "undefined") {
+		t.Errorf("error should include command output, got: %v", err)
+	}
+}
+
+func TestCommandBinaries_DeduplicatesInOrder(t *testing.T) {
+	got := build.CommandBinaries(config.BuildCommandsConfig{
+		Install: "./gradlew dependencies",
+		Build:   "./gradlew assemble",
+		Test:    "make test",
+		Lint:    "",
+	})
+	want := []string{"./gradlew", "make"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("CommandBinaries = %v, want %v", got, want)
+	}
+}
+
+func TestNewBuildSystemFromConfig_Command(t *testing.T) {
+	cfg := &config.OrchestratorConfig{
+		BuildSystem:   "command",
+		BuildCommands: config.BuildCommandsConfig{Build: "make", Test: "make test"},
+	}
+	bs, err := build.NewBuildSystemFromConfig(cfg, t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := bs.(*build.CommandBuildSystem); !ok {
+		t.Errorf("expected *CommandBuildSystem, got %T", bs)
+	}
+}
+
+func TestNewBuildSystemFromConfig_LintCommandOverridesLint(t *testing.T) {
+	dir := t.TempDir()
+	cfg := &config.OrchestratorConfig{
+		BuildSystem: "npm",
+		LintCommand: `go vet "./does-not-exist/..."`,
+	}
+	bs, err := build.NewBuildSystemFromConfig(cfg, dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// npm's own Lint would skip (no package.json); the override must run instead.
+	if err := bs.Lint(); err == nil {
+		t.Error("expected lint_command to run and fail")
+	}
+	if bs.IsInitialized() {
+		t.Error("other stages must still come from the npm build system")
+	}
+}
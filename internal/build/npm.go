@@ -52,7 +52,7 @@ func (n *NpmBuildSystem) Build() error {
 // Returns nil (skip) if no test script key exists in package.json.
 // Returns nil (skip) if the command output contains the NO_TESTS_CONFIGURED sentinel.
 func (n *NpmBuildSystem) Test() error {
-	if !n.hasTestScript() {
+	if !n.hasScript("test") {
 		return nil
 	}
 
@@ -70,8 +70,24 @@ func (n *NpmBuildSystem) Test() error {
 	return nil
 }
 
-// hasTestScript reports whether package.json in the project root contains a "test" key under "scripts".
-func (n *NpmBuildSystem) hasTestScript() bool {
+// Lint runs npm run lint if a lint script is configured in package.json.
+// Returns nil (skip) if no lint script key exists in package.json.
+func (n *NpmBuildSystem) Lint() error {
+	if !n.hasScript("lint") {
+		return nil
+	}
+
+	cmd := exec.Command("npm", "run", "lint")
+	cmd.Dir = n.projectRoot
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return wrapOutput(err, out)
+	}
+	return nil
+}
+
+// hasScript reports whether package.json in the project root contains name as a key under "scripts".
+func (n *NpmBuildSystem) hasScript(name string) bool {
 	data, err := os.ReadFile(filepath.Join(n.projectRoot, "package.json"))
 	if err != nil {
 		return false
@@ -84,12 +100,14 @@ func (n *NpmBuildSystem) hasTestScript() bool {
 		return false
 	}
 
-	_, ok := pkg.Scripts["test"]
+	_, ok := pkg.Scripts[name]
 	return ok
 }
 
 // NewBuildSystem returns a BuildSystem implementation for the given buildSystemType.
-// Supported types: "go" and "npm".
+// Supported types: "go", "npm", "cargo" and "python" (without a type-check
+// command; see NewBuildSystemFromConfig). The "command" type needs build_commands from
+// doug.yaml and is constructed by NewBuildSystemFromConfig instead.
 // Returns a descriptive error for unknown types.
 func NewBuildSystem(buildSystemType, projectRoot string) (BuildSystem, error) {
 	switch buildSystemType {
@@ -97,7 +115,13 @@ func NewBuildSystem(buildSystemType, projectRoot string) (BuildSystem, error) {
 		return NewGoBuildSystem(projectRoot), nil
 	case "npm":
 		return NewNpmBuildSystem(projectRoot), nil
+	case "cargo":
+		return NewCargoBuildSystem(projectRoot), nil
+	case "python":
+		return NewPythonBuildSystem(projectRoot, ""), nil
+	case "command":
+		return nil, fmt.Errorf("build system \"command\" requires build_commands from doug.yaml")
 	default:
-		return nil, fmt.Errorf("unknown build system type %q: supported types are \"go\" and \"npm\"", buildSystemType)
+		return nil, fmt.Errorf("unknown build system type %q: supported types are \"go\", \"npm\", \"cargo\", \"python\" and \"command\"", buildSystemType)
 	}
 }
@@ -75,6 +75,15 @@ func TestNpmBuildSystemTest_ReturnsNilWhenPackageJsonMalformed(t *testing.T) {
 	}
 }
 
+func TestNpmBuildSystemLint_ReturnsNilWhenLintScriptNotPresent(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "package.json", `{"scripts":{"test":"jest"}}`)
+	n := build.NewNpmBuildSystem(dir)
+	if err := n.Lint(); err != nil {
+		t.Errorf("expected nil when no lint script in package.json, got: %v", err)
+	}
+}
+
 // --- NewBuildSystem factory ---
 
 func TestNewBuildSystem_ReturnsGoBuildSystemForGo(t *testing.T) {
@@ -97,10 +106,30 @@ func TestNewBuildSystem_ReturnsNpmBuildSystemForNpm(t *testing.T) {
 	}
 }
 
+func TestNewBuildSystem_ReturnsCargoBuildSystemForCargo(t *testing.T) {
+	bs, err := build.NewBuildSystem("cargo", t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error for 'cargo' build system: %v", err)
+	}
+	if _, ok := bs.(*build.CargoBuildSystem); !ok {
+		t.Errorf("expected *CargoBuildSystem for type 'cargo', got %T", bs)
+	}
+}
+
+func TestNewBuildSystem_ReturnsPythonBuildSystemForPython(t *testing.T) {
+	bs, err := build.NewBuildSystem("python", t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error for 'python' build system: %v", err)
+	}
+	if _, ok := bs.(*build.PythonBuildSystem); !ok {
+		t.Errorf("expected *PythonBuildSystem for type 'python', got %T", bs)
+	}
+}
+
 func TestNewBuildSystem_ReturnsErrorForUnknownType(t *testing.T) {
-	_, err := build.NewBuildSystem("python", t.TempDir())
+	_, err := build.NewBuildSystem("gradle", t.TempDir())
 	if err == nil {
-		t.Error("expected error for unknown build system type 'python', got nil")
+		t.Error("expected error for unknown build system type 'gradle', got nil")
 	}
 }
 
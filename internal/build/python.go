@@ -0,0 +1,191 @@
+package build
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/robertgumeny/doug/internal/shellargs"
+)
+
+// pytestNoTestsCollected is the pytest exit code for "no tests were collected".
+const pytestNoTestsCollected = 5
+
+// pythonCompileExclude keeps compileall out of virtualenvs and tool caches.
+const pythonCompileExclude = `(^|[/\\])(\.venv|venv|\.git|node_modules|\.tox|\.nox|\.mypy_cache|\.pytest_cache|__pycache__)([/\\]|$)`
+
+// PythonBuildSystem implements BuildSystem for Python projects.
+//
+// Dependencies are installed with uv sync when uv is on PATH and the project
+// has a pyproject.toml; otherwise a .venv is created and populated with pip.
+// Once a virtualenv exists every command runs with its interpreter.
+// All commands use exec.Command with an explicit args slice — no shell eval.
+type PythonBuildSystem struct {
+	projectRoot string
+	typeCheck   string
+}
+
+// NewPythonBuildSystem creates a PythonBuildSystem rooted at projectRoot.
+// typeCheck is an optional command line (e.g. "mypy src") run after
+// byte-compilation in Build; it is split like agent_command, and its
+// executable is resolved with PythonTool.
+func NewPythonBuildSystem(projectRoot, typeCheck string) *PythonBuildSystem {
+	return &PythonBuildSystem{projectRoot: projectRoot, typeCheck: typeCheck}
+}
+
+// IsInitialized returns true if a virtualenv (.venv/ or venv/) or a lock file
+// (uv.lock, poetry.lock, pdm.lock) exists in the project root.
+func (p *PythonBuildSystem) IsInitialized() bool {
+	for _, dir := range []string{".venv", "venv"} {
+		if info, err := os.Stat(filepath.Join(p.projectRoot, dir)); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	for _, lock := range []string{"uv.lock", "poetry.lock", "pdm.lock"} {
+		if _, err := os.Stat(filepath.Join(p.projectRoot, lock)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Install runs uv sync when uv is available and pyproject.toml exists.
+// Otherwise it creates .venv (if missing) and runs pip install -r
+// requirements.txt, or pip install -e . for a pyproject-only project.
+func (p *PythonBuildSystem) Install() error {
+	if p.exists("pyproject.toml") {
+		if _, err := exec.LookPath("uv"); err == nil {
+			return p.run("uv", "sync")
+		}
+	}
+
+	if p.venvPython() == "" {
+		if err := p.run(PythonBinary(), "-m", "venv", ".venv"); err != nil {
+			return err
+		}
+	}
+	python := p.venvPython()
+	if p.exists("requirements.txt") {
+		return p.run(python, "-m", "pip", "install", "-r", "requirements.txt")
+	}
+	return p.run(python, "-m", "pip", "install", "-e", ".")
+}
+
+// Build byte-compiles every module in the project (catching syntax errors)
+// and then runs the configured type-check command, if any. Returns an error
+// containing the last 50 lines of output on failure.
+func (p *PythonBuildSystem) Build() error {
+	if err := p.run(p.python(), "-m", "compileall", "-q", "-x", pythonCompileExclude, "."); err != nil {
+		return err
+	}
+
+	parts, err := shellargs.Split(strings.TrimSpace(p.typeCheck))
+	if err != nil {
+		return err
+	}
+	if len(parts) == 0 {
+		return nil
+	}
+	return p.run(PythonTool(p.projectRoot, parts[0]), parts[1:]...)
+}
+
+// Test runs python -m pytest.
+// Returns nil (skip) if pytest collected no tests (exit code 5).
+// Returns nil (skip) if the command output contains the NO_TESTS_CONFIGURED sentinel.
+func (p *PythonBuildSystem) Test() error {
+	cmd := exec.Command(p.python(), "-m", "pytest")
+	cmd.Dir = p.projectRoot
+	out, err := cmd.CombinedOutput()
+
+	if strings.Contains(string(out), "NO_TESTS_CONFIGURED") {
+		return nil
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == pytestNoTestsCollected {
+		return nil
+	}
+
+	if err != nil {
+		return wrapOutput(err, out)
+	}
+	return nil
+}
+
+// Lint is a no-op: Python has no single standard linter. Configure one with
+// lint_command in doug.yaml (e.g. "ruff check .").
+func (p *PythonBuildSystem) Lint() error {
+	return nil
+}
+
+// python returns the virtualenv interpreter when one exists, else the system one.
+func (p *PythonBuildSystem) python() string {
+	if venv := p.venvPython(); venv != "" {
+		return venv
+	}
+	return PythonBinary()
+}
+
+// venvPython returns the interpreter inside .venv/ or venv/, or "" if neither exists.
+func (p *PythonBuildSystem) venvPython() string {
+	return venvExecutable(p.projectRoot, "python")
+}
+
+// PythonTool resolves the executable of a Python tool command such as the
+// "mypy" in python_type_check: the copy installed in the project's .venv/ or
+// venv/ when there is one, else name itself for a PATH lookup. A name that
+// already contains a path separator is returned unchanged.
+func PythonTool(projectRoot, name string) string {
+	if strings.ContainsAny(name, `/\`) {
+		return name
+	}
+	if path := venvExecutable(projectRoot, name); path != "" {
+		return path
+	}
+	return name
+}
+
+// venvExecutable returns the path of name inside .venv/bin/ or venv/bin/
+// (Scripts\name.exe on Windows), or "" if neither virtualenv has it.
+func venvExecutable(projectRoot, name string) string {
+	rel := filepath.Join("bin", name)
+	if runtime.GOOS == "windows" {
+		rel = filepath.Join("Scripts", name+".exe")
+	}
+	for _, dir := range []string{".venv", "venv"} {
+		path := filepath.Join(projectRoot, dir, rel)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// exists reports whether name exists in the project root.
+func (p *PythonBuildSystem) exists(name string) bool {
+	_, err := os.Stat(filepath.Join(p.projectRoot, name))
+	return err == nil
+}
+
+// run executes name with args in the project root.
+func (p *PythonBuildSystem) run(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Dir = p.projectRoot
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return wrapOutput(err, out)
+	}
+	return nil
+}
+
+// PythonBinary is the interpreter doug expects on PATH for the python build
+// system: python3, or python on Windows where python3 is rarely installed.
+func PythonBinary() string {
+	if runtime.GOOS == "windows" {
+		return "python"
+	}
+	return "python3"
+}
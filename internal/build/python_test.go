@@ -0,0 +1,187 @@
+package build_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/robertgumeny/doug/internal/build"
+)
+
+// requirePython skips the test when no Python interpreter is installed.
+func requirePython(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath(build.PythonBinary()); err != nil {
+		t.Skip("python not on PATH")
+	}
+}
+
+func TestPythonBuildSystemIsInitialized(t *testing.T) {
+	tests := []struct {
+		name  string
+		setup func(t *testing.T, dir string)
+		want  bool
+	}{
+		{"nothing installed", func(t *testing.T, dir string) {
+			writeFile(t, dir, "pyproject.toml", "[project]\nname = \"x\"\n")
+		}, false},
+		{".venv directory", func(t *testing.T, dir string) {
+			if err := os.Mkdir(filepath.Join(dir, ".venv"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}, true},
+		{"venv directory", func(t *testing.T, dir string) {
+			if err := os.Mkdir(filepath.Join(dir, "venv"), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}, true},
+		{"uv.lock", func(t *testing.T, dir string) { writeFile(t, dir, "uv.lock", "") }, true},
+		{"poetry.lock", func(t *testing.T, dir string) { writeFile(t, dir, "poetry.lock", "") }, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			tc.setup(t, dir)
+			if got := build.NewPythonBuildSystem(dir, "").IsInitialized(); got != tc.want {
+				t.Errorf("IsInitialized = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPythonBuildSystemBuildSucceeds(t *testing.T) {
+	requirePython(t)
+	dir := t.TempDir()
+	writeFile(t, dir, "app.py", "def main():\n    return 1\n")
+
+	if err := build.NewPythonBuildSystem(dir, "").Build(); err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+}
+
+func TestPythonBuildSystemBuildFailureIncludesOutput(t *testing.T) {
+	requirePython(t)
+	dir := t.TempDir()
+	writeFile(t, dir, "broken.py", "def main(:\n    pass\n")
+
+	err := build.NewPythonBuildSystem(dir, "").Build()
+	if err == nil {
+		t.Fatal("expected build error for syntax error")
+	}
+	if !strings.Contains(err.Error(), "broken.py") {
+		t.Errorf("error should name the failing module, got: %v", err)
+	}
+}
+
+func TestPythonBuildSystemBuildSkipsVirtualenv(t *testing.T) {
+	requirePython(t)
+	dir := t.TempDir()
+	writeFile(t, dir, "app.py", "x = 1\n")
+	if err := os.MkdirAll(filepath.Join(dir, ".venv", "lib"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(dir, ".venv", "lib"), "vendored.py", "def broken(:\n")
+
+	if err := build.NewPythonBuildSystem(dir, "").Build(); err != nil {
+		t.Fatalf("Build should ignore .venv, got: %v", err)
+	}
+}
+
+func TestPythonBuildSystemBuildRunsTypeCheck(t *testing.T) {
+	requirePython(t)
+	dir := t.TempDir()
+	writeFile(t, dir, "app.py", "x = 1\n")
+
+	typeCheck := build.PythonBinary() + ` -c "import sys; print('type errors found'); sys.exit(1)"`
+	err := build.NewPythonBuildSystem(dir, typeCheck).Build()
+	if err == nil {
+		t.Fatal("expected type-check failure to fail the build")
+	}
+	if !strings.Contains(err.Error(), "type errors found") {
+		t.Errorf("error should include type-check output, got: %v", err)
+	}
+}
+
+func TestPythonBuildSystemBuildRunsTypeCheckFromVirtualenv(t *testing.T) {
+	requirePython(t)
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a shell script as the virtualenv tool")
+	}
+	dir := t.TempDir()
+	writeFile(t, dir, "app.py", "x = 1\n")
+	binDir := filepath.Join(dir, ".venv", "bin")
+	if err := os.MkdirAll(binDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, binDir, "venv-typechecker-555", "#!/bin/sh\necho venv type checker ran\nexit 1\n")
+	if err := os.Chmod(filepath.Join(binDir, "venv-typechecker-555"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	err := build.NewPythonBuildSystem(dir, "venv-typechecker-555 src").Build()
+	if err == nil || !strings.Contains(err.Error(), "venv type checker ran") {
+		t.Fatalf("expected the .venv/bin type checker to run, got: %v", err)
+	}
+}
+
+func TestPythonTool_FallsBackToPATH(t *testing.T) {
+	if got := build.PythonTool(t.TempDir(), "mypy"); got != "mypy" {
+		t.Errorf("PythonTool without a virtualenv = %q, want %q", got, "mypy")
+	}
+}
+
+func TestPythonBuildSystemTest_NoTestsConfiguredSkips(t *testing.T) {
+	requirePython(t)
+	dir := t.TempDir()
+	// A stub pytest module that prints the sentinel and fails.
+	writeFile(t, dir, "pytest.py", "import sys\nprint('NO_TESTS_CONFIGURED')\nsys.exit(1)\n")
+
+	if err := build.NewPythonBuildSystem(dir, "").Test(); err != nil {
+		t.Errorf("expected nil (skip) for NO_TESTS_CONFIGURED, got: %v", err)
+	}
+}
+
+func TestPythonBuildSystemTest_NoTestsCollectedSkips(t *testing.T) {
+	requirePython(t)
+	dir := t.TempDir()
+	// A stub pytest module that exits like pytest does when nothing is collected.
+	writeFile(t, dir, "pytest.py", "import sys\nsys.exit(5)\n")
+
+	if err := build.NewPythonBuildSystem(dir, "").Test(); err != nil {
+		t.Errorf("expected nil (skip) for exit code 5, got: %v", err)
+	}
+}
+
+func TestPythonBuildSystemTest_FailureIncludesOutput(t *testing.T) {
+	requirePython(t)
+	dir := t.TempDir()
+	writeFile(t, dir, "pytest.py", "import sys\nprint('1 failed')\nsys.exit(1)\n")
+
+	err := build.NewPythonBuildSystem(dir, "").Test()
+	if err == nil {
+		t.Fatal("expected test failure")
+	}
+	if !strings.Contains(err.Error(), "1 failed") {
+		t.Errorf("error should include pytest output, got: %v", err)
+	}
+}
+
+func TestPythonBuildSystemInstall_CreatesVirtualenv(t *testing.T) {
+	requirePython(t)
+	if _, err := exec.LookPath("uv"); err == nil {
+		t.Skip("uv on PATH; the pip path is not exercised")
+	}
+	dir := t.TempDir()
+	writeFile(t, dir, "requirements.txt", "")
+
+	p := build.NewPythonBuildSystem(dir, "")
+	if err := p.Install(); err != nil {
+		t.Fatalf("Install: %v", err)
+	}
+	if !p.IsInitialized() {
+		t.Error("expected IsInitialized to be true after Install created .venv")
+	}
+}
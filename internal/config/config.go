@@ -6,10 +6,15 @@ package config
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/robertgumeny/doug/internal/naming"
 )
 
 // Default values for OrchestratorConfig fields.
@@ -20,12 +25,113 @@ const (
 	DefaultMaxIterations    = 20
 	DefaultKBEnabled        = true
 	DefaultAgentHeartbeat   = 30
+	DefaultAgentTimeout     = 0
+	DefaultTranscriptANSI   = true
+	DefaultTranscriptMax    = 10 << 20 // 10 MiB
 	DefaultSkillsConfigPath = ".doug/skills-config.yaml"
+	DefaultLint             = LintOff
+	DefaultHookTimeout      = 60
+	DefaultHookOnFailure    = HookWarn
+	DefaultWebhookSecretEnv = "DOUG_WEBHOOK_SECRET"
+	DefaultEpicStrategy     = EpicLeave
+	DefaultBranchTemplate   = "feature/{{.Epic}}"
+	DefaultCommitTemplate   = "{{.Kind}}: {{with .Task}}{{.}}{{else}}finalize {{.Epic}}{{end}}"
+)
+
+// Messages of the commits on_epic_complete creates on the base branch while
+// commit_template is left at DefaultCommitTemplate. A customized
+// commit_template renders these commits too, with .Type set to the strategy.
+const (
+	DefaultMergeTemplate  = "chore: merge {{.Branch}} into {{.Base}}"
+	DefaultSquashTemplate = "feat: {{.Epic}} — {{.EpicName}}{{with .Changelog}}\n\n{{.}}{{end}}"
+)
+
+// Lint modes for the lint setting in doug.yaml.
+const (
+	LintOff     = "off"     // never run the lint stage
+	LintWarn    = "warn"    // run it and log failures, but accept the task
+	LintEnforce = "enforce" // a lint failure rolls back and retries, like a test failure
+)
+
+// Lifecycle hook events for the hooks section of doug.yaml.
+const (
+	HookPreTask      = "pre_task"      // before the agent is invoked
+	HookPostAgent    = "post_agent"    // after the agent exits, before the outcome is handled
+	HookTaskDone     = "task_done"     // after a task is committed
+	HookTaskRetry    = "task_retry"    // when an attempt is rejected and will be retried
+	HookTaskBlocked  = "task_blocked"  // when a task exhausts max_retries
+	HookBugScheduled = "bug_scheduled" // when a bugfix task is scheduled
+	HookEpicComplete = "epic_complete" // after the epic is finalized
+)
+
+// HookEvents lists every valid hook event in the order they can fire.
+var HookEvents = []string{
+	HookPreTask,
+	HookPostAgent,
+	HookTaskDone,
+	HookTaskRetry,
+	HookTaskBlocked,
+	HookBugScheduled,
+	HookEpicComplete,
+}
+
+// Integration strategies for on_epic_complete.strategy, also the strategy
+// argument of git.IntegrateBranch.
+const (
+	EpicLeave       = "leave"  // keep the epic branch as-is for review
+	EpicMerge       = "merge"  // merge --no-ff into the base branch
+	EpicSquash      = "squash" // one squash commit on the base branch, message built from CHANGELOG.md
+	EpicFastForward = "ff"     // fast-forward the base branch; fails if it has diverged
+)
+
+// Session result channels for result_sources. They are tried in the order
+// listed and the first one holding a valid result wins.
+const (
+	ResultFrontmatter = "frontmatter" // YAML front-matter in the session .md file
+	ResultJSON        = "json"        // the session .json file next to it
+	ResultStdout      = "stdout"      // a marker-delimited JSON block in the agent's captured stdout (last agent.ResultTailBytes)
+)
+
+// DefaultResultSources is the result_sources order used when doug.yaml does
+// not set one.
+var DefaultResultSources = []string{ResultFrontmatter, ResultJSON, ResultStdout}
+
+// Hook failure policies for on_failure.
+const (
+	HookWarn  = "warn"  // log the failure and keep going
+	HookAbort = "abort" // stop the run
 )
 
 // OrchestratorConfig holds all configuration for the doug orchestrator.
 // It is read from .doug/doug.yaml. CLI flags override it at the highest
 // precedence by being applied after LoadConfig returns.
+//
+// AgentTimeoutSeconds bounds each agent invocation (0 disables the deadline);
+// individual tasks may override it with agent_timeout_seconds in tasks.yaml.
+//
+// TranscriptStripANSI and TranscriptMaxBytes control the per-attempt agent
+// transcript written next to each session file (0 bytes means no cap).
+//
+// BuildCommands is only consulted when BuildSystem is "command", and
+// PythonTypeCheck (e.g. "mypy src") only when it is "python".
+//
+// Lint selects the lint mode (LintOff, LintWarn or LintEnforce). LintCommand,
+// when set, replaces the build system's default linter.
+//
+// Hooks maps lifecycle events (see HookEvents) to the commands run when they
+// fire. Webhook, when its URL is set, POSTs run events to an HTTP endpoint.
+//
+// OnEpicComplete selects how a finished epic branch is integrated into its
+// base branch.
+//
+// BranchTemplate and CommitTemplate are text/template strings rendered with
+// naming.NameData for every epic branch and every task, documentation and
+// finalization commit.
+//
+// Rollback adds paths that survive the rollback of a rejected attempt.
+//
+// ResultSources lists where the agent's session result is looked for, in
+// order (ResultFrontmatter, ResultJSON, ResultStdout).
 type OrchestratorConfig struct {
 	AgentCommand          string `yaml:"agent_command"`
 	BuildSystem           string `yaml:"build_system"`
@@ -33,6 +139,90 @@ type OrchestratorConfig struct {
 	MaxIterations         int    `yaml:"max_iterations"`
 	KBEnabled             bool   `yaml:"kb_enabled"`
 	AgentHeartbeatSeconds int    `yaml:"agent_heartbeat_seconds"`
+	AgentTimeoutSeconds   int    `yaml:"agent_timeout_seconds"`
+	TranscriptStripANSI   bool   `yaml:"transcript_strip_ansi"`
+	TranscriptMaxBytes    int64  `yaml:"transcript_max_bytes"`
+
+	BuildCommands   BuildCommandsConfig `yaml:"build_commands"`
+	PythonTypeCheck string              `yaml:"python_type_check"`
+	Lint            string              `yaml:"lint"`
+	LintCommand     string              `yaml:"lint_command"`
+
+	Hooks   map[string][]HookConfig `yaml:"hooks"`
+	Webhook WebhookConfig           `yaml:"webhook"`
+
+	OnEpicComplete EpicCompleteConfig `yaml:"on_epic_complete"`
+	BranchTemplate string             `yaml:"branch_template"`
+	CommitTemplate string             `yaml:"commit_template"`
+
+	Rollback      RollbackConfig `yaml:"rollback"`
+	ResultSources []string       `yaml:"result_sources"`
+}
+
+// RollbackConfig lists paths a rollback must keep, in addition to the
+// built-in set (.doug/ state, docs/kb/, .env, *.backup), which cannot be
+// removed.
+//
+// Protect holds filepath.Match globs, relative to the project root, of files
+// whose contents are saved before the reset and written back afterwards,
+// tracked or not; a matching directory protects every file below it.
+// PreserveUntracked holds gitignore-style patterns of untracked files that
+// git clean must not delete. Negated ("!") patterns are rejected in both so
+// nothing can re-expose the orchestrator's own files.
+type RollbackConfig struct {
+	Protect           []string `yaml:"protect"`
+	PreserveUntracked []string `yaml:"preserve_untracked"`
+}
+
+// EpicCompleteConfig configures epic branch integration. Strategy is one of
+// EpicLeave, EpicMerge, EpicSquash or EpicFastForward; BaseBranch is the
+// branch integrated into and is required by every strategy except EpicLeave.
+type EpicCompleteConfig struct {
+	Strategy   string `yaml:"strategy"`
+	BaseBranch string `yaml:"base_branch"`
+}
+
+// WebhookConfig configures the run-event webhook. An empty URL disables it.
+//
+// The HMAC signing secret is read from the environment variable named by
+// SecretEnv (DefaultWebhookSecretEnv when empty) so it never lives in
+// doug.yaml; deliveries are unsigned when that variable is unset. Events
+// limits which events are sent (empty means all). MaxAttempts and
+// DeadlineSeconds bound retries and the total time spent per event; zero
+// selects the notifier defaults (3 attempts, 10 seconds).
+type WebhookConfig struct {
+	URL             string   `yaml:"url"`
+	SecretEnv       string   `yaml:"secret_env"`
+	Events          []string `yaml:"events"`
+	MaxAttempts     int      `yaml:"max_attempts"`
+	DeadlineSeconds int      `yaml:"deadline_seconds"`
+}
+
+// HookConfig is one command bound to a lifecycle event. Command is split like
+// agent_command (quotes respected, no shell) and receives the event payload as
+// JSON on stdin. TimeoutSeconds and OnFailure default to DefaultHookTimeout
+// and DefaultHookOnFailure when omitted.
+type HookConfig struct {
+	Command        string `yaml:"command"`
+	TimeoutSeconds int    `yaml:"timeout_seconds"`
+	OnFailure      string `yaml:"on_failure"`
+}
+
+// BuildCommandsConfig declares the command lines run by the "command" build
+// system. Each line is split like agent_command (quotes respected, no shell),
+// so pipes, globs and variable expansion are not available — wrap them in a
+// script or Makefile target instead.
+//
+// Build and Test are required; Install and Lint are skipped when empty.
+// InitializedWhen names a file (relative to the project root) whose presence
+// means dependencies are installed; when empty the project is always treated
+// as initialized.
+type BuildCommandsConfig struct {
+	Install         string `yaml:"install"`
+	Build           string `yaml:"build"`
+	Test            string `yaml:"test"`
+	Lint            string `yaml:"lint"`
+	InitializedWhen string `yaml:"initialized_when"`
 }
 
 // defaults returns an OrchestratorConfig populated with sane defaults.
@@ -44,6 +234,15 @@ func defaults() OrchestratorConfig {
 		MaxIterations:         DefaultMaxIterations,
 		KBEnabled:             DefaultKBEnabled,
 		AgentHeartbeatSeconds: DefaultAgentHeartbeat,
+		AgentTimeoutSeconds:   DefaultAgentTimeout,
+		TranscriptStripANSI:   DefaultTranscriptANSI,
+		TranscriptMaxBytes:    DefaultTranscriptMax,
+		Lint:                  DefaultLint,
+		Webhook:               WebhookConfig{SecretEnv: DefaultWebhookSecretEnv},
+		OnEpicComplete:        EpicCompleteConfig{Strategy: DefaultEpicStrategy},
+		BranchTemplate:        DefaultBranchTemplate,
+		CommitTemplate:        DefaultCommitTemplate,
+		ResultSources:         append([]string(nil), DefaultResultSources...),
 	}
 }
 
@@ -56,6 +255,24 @@ type partialConfig struct {
 	MaxIterations         *int    `yaml:"max_iterations"`
 	KBEnabled             *bool   `yaml:"kb_enabled"`
 	AgentHeartbeatSeconds *int    `yaml:"agent_heartbeat_seconds"`
+	AgentTimeoutSeconds   *int    `yaml:"agent_timeout_seconds"`
+	TranscriptStripANSI   *bool   `yaml:"transcript_strip_ansi"`
+	TranscriptMaxBytes    *int64  `yaml:"transcript_max_bytes"`
+
+	BuildCommands   *BuildCommandsConfig `yaml:"build_commands"`
+	PythonTypeCheck *string              `yaml:"python_type_check"`
+	Lint            *string              `yaml:"lint"`
+	LintCommand     *string              `yaml:"lint_command"`
+
+	Hooks   map[string][]HookConfig `yaml:"hooks"`
+	Webhook *WebhookConfig          `yaml:"webhook"`
+
+	OnEpicComplete *EpicCompleteConfig `yaml:"on_epic_complete"`
+	BranchTemplate *string             `yaml:"branch_template"`
+	CommitTemplate *string             `yaml:"commit_template"`
+
+	Rollback      *RollbackConfig `yaml:"rollback"`
+	ResultSources []string        `yaml:"result_sources"`
 }
 
 // LoadConfig reads doug.yaml at path and returns an OrchestratorConfig.
@@ -99,15 +316,214 @@ func LoadConfig(path string) (*OrchestratorConfig, error) {
 	if partial.AgentHeartbeatSeconds != nil {
 		cfg.AgentHeartbeatSeconds = *partial.AgentHeartbeatSeconds
 	}
+	if partial.AgentTimeoutSeconds != nil {
+		cfg.AgentTimeoutSeconds = *partial.AgentTimeoutSeconds
+	}
+	if partial.TranscriptStripANSI != nil {
+		cfg.TranscriptStripANSI = *partial.TranscriptStripANSI
+	}
+	if partial.TranscriptMaxBytes != nil {
+		cfg.TranscriptMaxBytes = *partial.TranscriptMaxBytes
+	}
+	if partial.BuildCommands != nil {
+		cfg.BuildCommands = *partial.BuildCommands
+	}
+	if partial.PythonTypeCheck != nil {
+		cfg.PythonTypeCheck = *partial.PythonTypeCheck
+	}
+	if partial.Lint != nil {
+		cfg.Lint = *partial.Lint
+	}
+	if partial.LintCommand != nil {
+		cfg.LintCommand = *partial.LintCommand
+	}
+	if partial.Hooks != nil {
+		cfg.Hooks = partial.Hooks
+	}
+	if partial.Webhook != nil {
+		cfg.Webhook = *partial.Webhook
+	}
+	if cfg.Webhook.SecretEnv == "" {
+		cfg.Webhook.SecretEnv = DefaultWebhookSecretEnv
+	}
+	if partial.OnEpicComplete != nil {
+		cfg.OnEpicComplete = *partial.OnEpicComplete
+	}
+	if cfg.OnEpicComplete.Strategy == "" {
+		cfg.OnEpicComplete.Strategy = DefaultEpicStrategy
+	}
+	if partial.BranchTemplate != nil {
+		cfg.BranchTemplate = *partial.BranchTemplate
+	}
+	if partial.CommitTemplate != nil {
+		cfg.CommitTemplate = *partial.CommitTemplate
+	}
+	if partial.Rollback != nil {
+		cfg.Rollback = *partial.Rollback
+	}
+	if partial.ResultSources != nil {
+		cfg.ResultSources = partial.ResultSources
+	}
+
+	if err := ValidateLintMode(cfg.Lint); err != nil {
+		return nil, err
+	}
+	if err := normalizeHooks(cfg.Hooks); err != nil {
+		return nil, err
+	}
+	if err := validateEpicComplete(cfg.OnEpicComplete); err != nil {
+		return nil, err
+	}
+	if err := naming.ValidateBranchTemplate(cfg.BranchTemplate); err != nil {
+		return nil, fmt.Errorf("invalid branch_template: %w", err)
+	}
+	if err := naming.ValidateCommitTemplate(cfg.CommitTemplate); err != nil {
+		return nil, fmt.Errorf("invalid commit_template: %w", err)
+	}
+	if err := validateRollback(cfg.Rollback); err != nil {
+		return nil, err
+	}
+	if err := validateResultSources(cfg.ResultSources); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
 
+// ValidateLintMode returns an error unless mode is LintOff, LintWarn or LintEnforce.
+func ValidateLintMode(mode string) error {
+	switch mode {
+	case LintOff, LintWarn, LintEnforce:
+		return nil
+	default:
+		return fmt.Errorf("invalid lint mode %q: must be one of: off, warn, enforce", mode)
+	}
+}
+
+// LintEnabled reports whether mode asks for the lint stage to run. An empty
+// mode (a config not loaded through LoadConfig) is treated as off.
+func LintEnabled(mode string) bool {
+	return mode == LintWarn || mode == LintEnforce
+}
+
+// normalizeHooks validates hook events, commands and failure policies, and
+// fills in the default timeout and policy where they are omitted.
+func normalizeHooks(hooks map[string][]HookConfig) error {
+	for event, list := range hooks {
+		if !isHookEvent(event) {
+			return fmt.Errorf("unknown hook event %q: must be one of: %s", event, strings.Join(HookEvents, ", "))
+		}
+		for i := range list {
+			h := &list[i]
+			if strings.TrimSpace(h.Command) == "" {
+				return fmt.Errorf("hooks.%s[%d]: command is required", event, i)
+			}
+			if h.TimeoutSeconds < 0 {
+				return fmt.Errorf("hooks.%s[%d]: timeout_seconds must not be negative", event, i)
+			}
+			if h.TimeoutSeconds == 0 {
+				h.TimeoutSeconds = DefaultHookTimeout
+			}
+			switch h.OnFailure {
+			case "":
+				h.OnFailure = DefaultHookOnFailure
+			case HookWarn, HookAbort:
+			default:
+				return fmt.Errorf("hooks.%s[%d]: invalid on_failure %q: must be warn or abort", event, i, h.OnFailure)
+			}
+		}
+	}
+	return nil
+}
+
+// validateEpicComplete returns an error for an unknown strategy, or when a
+// strategy other than EpicLeave has no base branch.
+func validateEpicComplete(c EpicCompleteConfig) error {
+	switch c.Strategy {
+	case EpicLeave:
+		return nil
+	case EpicMerge, EpicSquash, EpicFastForward:
+	default:
+		return fmt.Errorf("invalid on_epic_complete.strategy %q: must be one of: leave, merge, squash, ff", c.Strategy)
+	}
+	if strings.TrimSpace(c.BaseBranch) == "" {
+		return fmt.Errorf("on_epic_complete.base_branch is required for strategy %q", c.Strategy)
+	}
+	return nil
+}
+
+// validateRollback rejects empty, negated or escaping rollback patterns, and
+// protect globs that are absolute or malformed. A leading "/" is allowed in
+// preserve_untracked, where it anchors the pattern to the project root as in
+// .gitignore.
+func validateRollback(c RollbackConfig) error {
+	check := func(field string, i int, pattern string) error {
+		p := strings.TrimSpace(pattern)
+		switch {
+		case p == "":
+			return fmt.Errorf("rollback.%s[%d]: pattern is empty", field, i)
+		case strings.HasPrefix(p, "!"):
+			return fmt.Errorf("rollback.%s[%d]: negated pattern %q is not allowed; the built-in paths cannot be removed", field, i, pattern)
+		case slices.Contains(strings.Split(filepath.ToSlash(p), "/"), ".."):
+			return fmt.Errorf("rollback.%s[%d]: pattern %q must stay inside the project", field, i, pattern)
+		}
+		return nil
+	}
+	for i, p := range c.Protect {
+		if err := check("protect", i, p); err != nil {
+			return err
+		}
+		if filepath.IsAbs(p) || strings.HasPrefix(p, "/") {
+			return fmt.Errorf("rollback.protect[%d]: pattern %q must be relative to the project root", i, p)
+		}
+		if _, err := filepath.Match(p, ""); err != nil {
+			return fmt.Errorf("rollback.protect[%d]: invalid glob %q: %w", i, p, err)
+		}
+	}
+	for i, p := range c.PreserveUntracked {
+		if err := check("preserve_untracked", i, p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateResultSources requires at least one source and rejects unknown or
+// repeated ones.
+func validateResultSources(sources []string) error {
+	if len(sources) == 0 {
+		return errors.New("result_sources must list at least one of: frontmatter, json, stdout")
+	}
+	for i, src := range sources {
+		switch src {
+		case ResultFrontmatter, ResultJSON, ResultStdout:
+		default:
+			return fmt.Errorf("invalid result_sources[%d] %q: must be one of: frontmatter, json, stdout", i, src)
+		}
+		if slices.Contains(sources[:i], src) {
+			return fmt.Errorf("result_sources lists %q twice", src)
+		}
+	}
+	return nil
+}
+
+// isHookEvent reports whether event is one of HookEvents.
+func isHookEvent(event string) bool {
+	for _, e := range HookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
 // DetectBuildSystem returns the build system identifier based on marker files
 // found in dir. Rules (highest precedence first):
 //   - "go"  if go.mod exists
 //   - "npm" if package.json exists (and go.mod does not)
-//   - "go"  if neither file exists (safe default)
+//   - "cargo" if Cargo.toml exists (and neither of the above does)
+//   - "python" if pyproject.toml or requirements.txt exists (and none of the above does)
+//   - "go"  if no marker file exists (safe default)
 func DetectBuildSystem(dir string) string {
 	_, goModErr := os.Stat(filepath.Join(dir, "go.mod"))
 	if goModErr == nil {
@@ -119,5 +535,16 @@ func DetectBuildSystem(dir string) string {
 		return "npm"
 	}
 
+	_, cargoErr := os.Stat(filepath.Join(dir, "Cargo.toml"))
+	if cargoErr == nil {
+		return "cargo"
+	}
+
+	for _, marker := range []string{"pyproject.toml", "requirements.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, marker)); err == nil {
+			return "python"
+		}
+	}
+
 	return "go"
 }
@@ -3,6 +3,8 @@ package config_test
 import (
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/robertgumeny/doug/internal/config"
@@ -135,6 +137,276 @@ func TestLoadConfig_PartialFile(t *testing.T) {
 	}
 }
 
+func TestLoadConfig_AgentTimeout(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doug.yaml")
+	writeFile(t, path, "agent_timeout_seconds: 900\n")
+
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.AgentTimeoutSeconds != 900 {
+		t.Errorf("AgentTimeoutSeconds = %d, want 900", cfg.AgentTimeoutSeconds)
+	}
+
+	defaults, err := config.LoadConfig(filepath.Join(dir, "missing.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if defaults.AgentTimeoutSeconds != config.DefaultAgentTimeout {
+		t.Errorf("default AgentTimeoutSeconds = %d, want %d", defaults.AgentTimeoutSeconds, config.DefaultAgentTimeout)
+	}
+}
+
+func TestLoadConfig_Lint(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doug.yaml")
+	writeFile(t, path, "lint: enforce\nlint_command: golangci-lint run\n")
+
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.Lint != config.LintEnforce || cfg.LintCommand != "golangci-lint run" {
+		t.Errorf("got lint=%q lint_command=%q", cfg.Lint, cfg.LintCommand)
+	}
+
+	defaults, err := config.LoadConfig(filepath.Join(dir, "missing.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if defaults.Lint != config.LintOff {
+		t.Errorf("default Lint = %q, want %q", defaults.Lint, config.LintOff)
+	}
+
+	writeFile(t, path, "lint: strict\n")
+	if _, err := config.LoadConfig(path); err == nil || !strings.Contains(err.Error(), "strict") {
+		t.Errorf("expected invalid lint mode error naming the value, got: %v", err)
+	}
+}
+
+func TestLoadConfig_Hooks(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doug.yaml")
+	writeFile(t, path, `hooks:
+  task_done:
+    - command: ./scripts/notify.sh
+    - command: make snapshot
+      timeout_seconds: 300
+      on_failure: abort
+`)
+
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	done := cfg.Hooks[config.HookTaskDone]
+	if len(done) != 2 {
+		t.Fatalf("expected 2 task_done hooks, got %+v", cfg.Hooks)
+	}
+	if done[0].TimeoutSeconds != config.DefaultHookTimeout || done[0].OnFailure != config.HookWarn {
+		t.Errorf("expected defaults filled in, got %+v", done[0])
+	}
+	if done[1].TimeoutSeconds != 300 || done[1].OnFailure != config.HookAbort {
+		t.Errorf("expected explicit values kept, got %+v", done[1])
+	}
+
+	for yaml, want := range map[string]string{
+		"hooks:\n  task_finished:\n    - command: x\n":                   "task_finished",
+		"hooks:\n  pre_task:\n    - timeout_seconds: 5\n":                "command is required",
+		"hooks:\n  pre_task:\n    - command: x\n      on_failure: ask\n": "ask",
+	} {
+		writeFile(t, path, yaml)
+		if _, err := config.LoadConfig(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadConfig(%q): expected error containing %q, got: %v", yaml, want, err)
+		}
+	}
+}
+
+func TestLoadConfig_Webhook(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doug.yaml")
+	writeFile(t, path, `webhook:
+  url: https://hooks.example.com/doug
+  events: [task_blocked, run_aborted]
+  deadline_seconds: 5
+`)
+
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w := cfg.Webhook
+	if w.URL != "https://hooks.example.com/doug" || len(w.Events) != 2 || w.DeadlineSeconds != 5 {
+		t.Errorf("unexpected webhook config: %+v", w)
+	}
+	if w.SecretEnv != config.DefaultWebhookSecretEnv {
+		t.Errorf("SecretEnv = %q, want default %q", w.SecretEnv, config.DefaultWebhookSecretEnv)
+	}
+
+	defaults, err := config.LoadConfig(filepath.Join(dir, "missing.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if defaults.Webhook.URL != "" {
+		t.Errorf("expected webhook disabled by default, got %+v", defaults.Webhook)
+	}
+}
+
+func TestLoadConfig_OnEpicComplete(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doug.yaml")
+	writeFile(t, path, "on_epic_complete:\n  strategy: squash\n  base_branch: main\n")
+
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.OnEpicComplete.Strategy != config.EpicSquash || cfg.OnEpicComplete.BaseBranch != "main" {
+		t.Errorf("unexpected on_epic_complete: %+v", cfg.OnEpicComplete)
+	}
+
+	defaults, err := config.LoadConfig(filepath.Join(dir, "missing.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if defaults.OnEpicComplete.Strategy != config.EpicLeave {
+		t.Errorf("default strategy = %q, want %q", defaults.OnEpicComplete.Strategy, config.EpicLeave)
+	}
+
+	for yaml, want := range map[string]string{
+		"on_epic_complete:\n  strategy: rebase\n  base_branch: main\n": "rebase",
+		"on_epic_complete:\n  strategy: merge\n":                       "base_branch is required",
+	} {
+		writeFile(t, path, yaml)
+		if _, err := config.LoadConfig(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadConfig(%q): expected error containing %q, got: %v", yaml, want, err)
+		}
+	}
+}
+
+func TestLoadConfig_Rollback(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doug.yaml")
+	writeFile(t, path, "rollback:\n  protect: [\"testdata/*.db\", certs]\n  preserve_untracked: [.envrc, /.idea/]\n")
+
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.Rollback.Protect, []string{"testdata/*.db", "certs"}) ||
+		!reflect.DeepEqual(cfg.Rollback.PreserveUntracked, []string{".envrc", "/.idea/"}) {
+		t.Errorf("unexpected rollback: %+v", cfg.Rollback)
+	}
+
+	for yaml, want := range map[string]string{
+		"rollback:\n  protect: [\"!.doug/tasks.yaml\"]\n":    "negated",
+		"rollback:\n  preserve_untracked: [\"!.doug/\"]\n":   "negated",
+		"rollback:\n  protect: [\"\"]\n":                     "empty",
+		"rollback:\n  protect: [/etc/hosts]\n":               "relative",
+		"rollback:\n  protect: [\"../secrets\"]\n":           "inside the project",
+		"rollback:\n  preserve_untracked: [\"a/../../b\"]\n": "inside the project",
+		"rollback:\n  protect: [\"certs/[\"]\n":              "invalid glob",
+	} {
+		writeFile(t, path, yaml)
+		if _, err := config.LoadConfig(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadConfig(%q): expected error containing %q, got: %v", yaml, want, err)
+		}
+	}
+}
+
+func TestLoadConfig_NameTemplates(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doug.yaml")
+	writeFile(t, path, "branch_template: \"team/{{slug .EpicName}}\"\ncommit_template: \"{{.Kind}}: {{.Description}} ({{.Task}})\"\n")
+
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.BranchTemplate != "team/{{slug .EpicName}}" || cfg.CommitTemplate != "{{.Kind}}: {{.Description}} ({{.Task}})" {
+		t.Errorf("got branch_template=%q commit_template=%q", cfg.BranchTemplate, cfg.CommitTemplate)
+	}
+
+	defaults, err := config.LoadConfig(filepath.Join(dir, "missing.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if defaults.BranchTemplate != config.DefaultBranchTemplate || defaults.CommitTemplate != config.DefaultCommitTemplate {
+		t.Errorf("defaults = (%q, %q)", defaults.BranchTemplate, defaults.CommitTemplate)
+	}
+
+	for yaml, want := range map[string]string{
+		"branch_template: \"team/{{.Ticket}}\"\n": "branch_template",
+		"commit_template: \"{{.Kind\"\n":          "commit_template",
+	} {
+		writeFile(t, path, yaml)
+		if _, err := config.LoadConfig(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadConfig(%q): expected error containing %q, got: %v", yaml, want, err)
+		}
+	}
+}
+
+func TestLoadConfig_Transcript(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doug.yaml")
+	writeFile(t, path, "transcript_strip_ansi: false\ntranscript_max_bytes: 0\n")
+
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cfg.TranscriptStripANSI {
+		t.Error("TranscriptStripANSI = true, want false")
+	}
+	if cfg.TranscriptMaxBytes != 0 {
+		t.Errorf("TranscriptMaxBytes = %d, want 0", cfg.TranscriptMaxBytes)
+	}
+
+	defaults, err := config.LoadConfig(filepath.Join(dir, "missing.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if defaults.TranscriptStripANSI != config.DefaultTranscriptANSI || defaults.TranscriptMaxBytes != config.DefaultTranscriptMax {
+		t.Errorf("defaults = (%v, %d), want (%v, %d)", defaults.TranscriptStripANSI, defaults.TranscriptMaxBytes,
+			config.DefaultTranscriptANSI, config.DefaultTranscriptMax)
+	}
+}
+
+func TestLoadConfig_ResultSources(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "doug.yaml")
+	writeFile(t, path, "result_sources: [stdout, json]\n")
+
+	cfg, err := config.LoadConfig(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.ResultSources, []string{config.ResultStdout, config.ResultJSON}) {
+		t.Errorf("ResultSources = %q", cfg.ResultSources)
+	}
+
+	defaults, err := config.LoadConfig(filepath.Join(dir, "missing.yaml"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(defaults.ResultSources, config.DefaultResultSources) {
+		t.Errorf("default ResultSources = %q, want %q", defaults.ResultSources, config.DefaultResultSources)
+	}
+
+	for yaml, want := range map[string]string{
+		"result_sources: [frontmatter, xml]\n": "must be one of",
+		"result_sources: [json, json]\n":       "twice",
+		"result_sources: []\n":                 "result_sources",
+	} {
+		writeFile(t, path, yaml)
+		if _, err := config.LoadConfig(path); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("LoadConfig(%q): expected error containing %q, got: %v", yaml, want, err)
+		}
+	}
+}
+
 // TestLoadConfig_CLIFlagOverride demonstrates the CLI flag override pattern.
 // Cobra binds flags to a *OrchestratorConfig and sets field values after
 // LoadConfig returns, giving CLI flags the highest precedence.
@@ -211,6 +483,35 @@ func TestDetectBuildSystem(t *testing.T) {
 			},
 			expected: "go",
 		},
+		{
+			name: "Cargo.toml exists returns cargo",
+			setup: func(dir string) {
+				writeFile(t, filepath.Join(dir, "Cargo.toml"), "[package]\nname = \"foo\"\n")
+			},
+			expected: "cargo",
+		},
+		{
+			name: "package.json takes precedence over Cargo.toml",
+			setup: func(dir string) {
+				writeFile(t, filepath.Join(dir, "package.json"), "{}\n")
+				writeFile(t, filepath.Join(dir, "Cargo.toml"), "[package]\nname = \"foo\"\n")
+			},
+			expected: "npm",
+		},
+		{
+			name: "pyproject.toml exists returns python",
+			setup: func(dir string) {
+				writeFile(t, filepath.Join(dir, "pyproject.toml"), "[project]\nname = \"foo\"\n")
+			},
+			expected: "python",
+		},
+		{
+			name: "requirements.txt exists returns python",
+			setup: func(dir string) {
+				writeFile(t, filepath.Join(dir, "requirements.txt"), "requests\n")
+			},
+			expected: "python",
+		},
 		{
 			name:     "neither exists returns go default",
 			setup:    func(dir string) {},
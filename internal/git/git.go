@@ -19,7 +19,7 @@ var ErrNothingToCommit = errors.New("nothing to commit")
 //   - If branchName exists locally: git checkout branchName.
 //   - If branchName does not exist: git checkout -b branchName.
 func EnsureEpicBranch(branchName, projectRoot string) error {
-	current, err := currentBranch(projectRoot)
+	current, err := CurrentBranch(projectRoot)
 	if err != nil {
 		return fmt.Errorf("EnsureEpicBranch: get current branch: %w", err)
 	}
@@ -51,8 +51,9 @@ func EnsureEpicBranch(branchName, projectRoot string) error {
 	return nil
 }
 
-// currentBranch returns the name of the currently checked-out branch.
-func currentBranch(projectRoot string) (string, error) {
+// CurrentBranch returns the name of the currently checked-out branch, or
+// "HEAD" when HEAD is detached.
+func CurrentBranch(projectRoot string) (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--abbrev-ref", "HEAD")
 	cmd.Dir = projectRoot
 	out, err := cmd.Output()
@@ -77,32 +78,53 @@ func branchExists(branchName, projectRoot string) (bool, error) {
 type fileBackup struct {
 	relPath string
 	data    []byte
+	mode    os.FileMode
 }
 
+// builtinCleanExcludes are the untracked paths RollbackChanges never cleans,
+// whatever the caller asks for: orchestrator state, the knowledge base,
+// secrets and backups.
+var builtinCleanExcludes = []string{".doug/", "docs/kb/", ".env", "*.backup"}
+
 // RollbackChanges performs a safe rollback of the git working tree while
-// preserving files listed in protectedPaths across the reset.
+// preserving files matching protectedPaths across the reset.
+//
+// protectedPaths are filepath.Match globs relative to projectRoot (a plain
+// path is a glob matching itself); a matching directory protects every file
+// below it. preserveUntracked are extra gitignore-style patterns git clean
+// must leave alone, on top of builtinCleanExcludes.
 //
 // Steps:
-//  1. Read each file in protectedPaths into memory (skip missing files).
+//  1. Read each file matching protectedPaths into memory (no match is fine).
 //  2. Run git reset --hard HEAD to revert all tracked changes.
-//  3. Run git clean -fd with --exclude for logs/, docs/kb/, .env, and *.backup.
+//  3. Run git clean -fd with --exclude for preserveUntracked and the
+//     built-in .doug/, docs/kb/, .env and *.backup.
 //  4. Write the backed-up files back to their original locations.
 //
 // The clean (step 3) runs before the restore (step 4) so that protected files
 // survive even when they are untracked — git clean would otherwise delete them
 // if they were restored before the clean ran.
-func RollbackChanges(projectRoot string, protectedPaths []string) error {
+func RollbackChanges(projectRoot string, protectedPaths, preserveUntracked []string) error {
 	// Step 1: read protected files into memory.
-	backups := make([]fileBackup, 0, len(protectedPaths))
-	for _, rel := range protectedPaths {
-		data, err := os.ReadFile(filepath.Join(projectRoot, rel))
+	files, err := expandProtected(projectRoot, protectedPaths)
+	if err != nil {
+		return fmt.Errorf("RollbackChanges: %w", err)
+	}
+	backups := make([]fileBackup, 0, len(files))
+	for _, rel := range files {
+		path := filepath.Join(projectRoot, rel)
+		info, err := os.Stat(path)
 		if err != nil {
 			if errors.Is(err, os.ErrNotExist) {
 				continue
 			}
 			return fmt.Errorf("RollbackChanges: backup %q: %w", rel, err)
 		}
-		backups = append(backups, fileBackup{relPath: rel, data: data})
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("RollbackChanges: backup %q: %w", rel, err)
+		}
+		backups = append(backups, fileBackup{relPath: rel, data: data, mode: info.Mode().Perm()})
 	}
 
 	// Step 2: git reset --hard HEAD.
@@ -113,12 +135,12 @@ func RollbackChanges(projectRoot string, protectedPaths []string) error {
 	}
 
 	// Step 3: git clean -fd with excludes.
-	cleanCmd := exec.Command("git", "clean", "-fd",
-		"--exclude=.doug/",
-		"--exclude=docs/kb/",
-		"--exclude=.env",
-		"--exclude=*.backup",
-	)
+	// The built-in excludes go last so no caller pattern can override them.
+	cleanArgs := []string{"clean", "-fd"}
+	for _, pattern := range append(append([]string(nil), preserveUntracked...), builtinCleanExcludes...) {
+		cleanArgs = append(cleanArgs, "--exclude="+pattern)
+	}
+	cleanCmd := exec.Command("git", cleanArgs...)
 	cleanCmd.Dir = projectRoot
 	if out, err := cleanCmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("RollbackChanges: git clean: %w\n%s", err, strings.TrimSpace(string(out)))
@@ -130,7 +152,7 @@ func RollbackChanges(projectRoot string, protectedPaths []string) error {
 		if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
 			return fmt.Errorf("RollbackChanges: mkdir for %q: %w", b.relPath, err)
 		}
-		if err := os.WriteFile(dst, b.data, 0644); err != nil {
+		if err := os.WriteFile(dst, b.data, b.mode); err != nil {
 			return fmt.Errorf("RollbackChanges: restore %q: %w", b.relPath, err)
 		}
 	}
@@ -138,6 +160,51 @@ func RollbackChanges(projectRoot string, protectedPaths []string) error {
 	return nil
 }
 
+// expandProtected resolves the protectedPaths globs of RollbackChanges to the
+// files they match, relative to projectRoot, without duplicates. Directories
+// are walked. A pattern that matches nothing is skipped.
+func expandProtected(projectRoot string, patterns []string) ([]string, error) {
+	seen := make(map[string]bool)
+	var files []string
+	add := func(path string) {
+		rel, err := filepath.Rel(projectRoot, path)
+		if err != nil || seen[rel] {
+			return
+		}
+		seen[rel] = true
+		files = append(files, rel)
+	}
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(filepath.Join(projectRoot, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, fmt.Errorf("protected path %q: %w", pattern, err)
+		}
+		for _, m := range matches {
+			info, err := os.Stat(m)
+			if err != nil {
+				continue
+			}
+			if !info.IsDir() {
+				add(m)
+				continue
+			}
+			err = filepath.WalkDir(m, func(path string, d os.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if d.Type().IsRegular() {
+					add(path)
+				}
+				return nil
+			})
+			if err != nil {
+				return nil, fmt.Errorf("protected path %q: %w", pattern, err)
+			}
+		}
+	}
+	return files, nil
+}
+
 // Commit stages all changes with git add -A and creates a commit with message.
 // Returns ErrNothingToCommit (non-fatal) if there is nothing to commit.
 // All other errors are fatal.
@@ -126,7 +126,7 @@ func TestRollbackChanges_ProtectedFilePreserved(t *testing.T) {
 	// Modify the protected file (simulates agent writing state).
 	writeTestFile(t, dir, "project-state.yaml", "version: modified\n")
 
-	if err := git.RollbackChanges(dir, []string{"project-state.yaml"}); err != nil {
+	if err := git.RollbackChanges(dir, []string{"project-state.yaml"}, nil); err != nil {
 		t.Fatalf("RollbackChanges: %v", err)
 	}
 
@@ -147,7 +147,7 @@ func TestRollbackChanges_UnprotectedTrackedFileReverted(t *testing.T) {
 	// Modify the tracked file without protecting it.
 	writeTestFile(t, dir, "tracked.txt", "modified\n")
 
-	if err := git.RollbackChanges(dir, []string{}); err != nil {
+	if err := git.RollbackChanges(dir, []string{}, nil); err != nil {
 		t.Fatalf("RollbackChanges: %v", err)
 	}
 
@@ -165,7 +165,7 @@ func TestRollbackChanges_UntrackedFileRemovedByClean(t *testing.T) {
 	// Create an untracked file that is not in an excluded directory.
 	writeTestFile(t, dir, "untracked.txt", "should be removed\n")
 
-	if err := git.RollbackChanges(dir, []string{}); err != nil {
+	if err := git.RollbackChanges(dir, []string{}, nil); err != nil {
 		t.Fatalf("RollbackChanges: %v", err)
 	}
 
@@ -183,7 +183,7 @@ func TestRollbackChanges_UntrackedProtectedFilePreserved(t *testing.T) {
 	// so the restore step must happen AFTER the clean, not before.
 	writeTestFile(t, dir, "tasks.yaml", "status: todo\n")
 
-	if err := git.RollbackChanges(dir, []string{"tasks.yaml"}); err != nil {
+	if err := git.RollbackChanges(dir, []string{"tasks.yaml"}, nil); err != nil {
 		t.Fatalf("RollbackChanges: %v", err)
 	}
 
@@ -198,7 +198,7 @@ func TestRollbackChanges_MissingProtectedFileIsSkipped(t *testing.T) {
 	dir := initGitRepo(t)
 
 	// Protected path references a file that does not exist — must not error.
-	if err := git.RollbackChanges(dir, []string{"nonexistent.yaml"}); err != nil {
+	if err := git.RollbackChanges(dir, []string{"nonexistent.yaml"}, nil); err != nil {
 		t.Errorf("RollbackChanges with missing protected file should not error: %v", err)
 	}
 }
@@ -213,7 +213,7 @@ func TestRollbackChanges_MultipleProtectedFilesAllPreserved(t *testing.T) {
 	writeTestFile(t, dir, "project-state.yaml", "state: modified\n")
 	writeTestFile(t, dir, "tasks.yaml", "tasks: modified\n")
 
-	if err := git.RollbackChanges(dir, []string{"project-state.yaml", "tasks.yaml"}); err != nil {
+	if err := git.RollbackChanges(dir, []string{"project-state.yaml", "tasks.yaml"}, nil); err != nil {
 		t.Fatalf("RollbackChanges: %v", err)
 	}
 
@@ -225,6 +225,68 @@ func TestRollbackChanges_MultipleProtectedFilesAllPreserved(t *testing.T) {
 	}
 }
 
+func TestRollbackChanges_ProtectGlobAndDirectory(t *testing.T) {
+	dir := initGitRepo(t)
+	if err := os.MkdirAll(filepath.Join(dir, "certs", "dev"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, dir, "fixtures.db", "rows: 1\n")
+	gitAddCommit(t, dir, "add fixtures")
+
+	writeTestFile(t, dir, "fixtures.db", "rows: 2\n")
+	writeTestFile(t, dir, "certs/dev/server.pem", "cert\n")
+	if err := os.Chmod(filepath.Join(dir, "certs/dev/server.pem"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, dir, "scratch.txt", "gone\n")
+
+	if err := git.RollbackChanges(dir, []string{"*.db", "certs"}, nil); err != nil {
+		t.Fatalf("RollbackChanges: %v", err)
+	}
+
+	if got := readTestFile(t, dir, "fixtures.db"); got != "rows: 2\n" {
+		t.Errorf("fixtures.db = %q, want the protected local edit", got)
+	}
+	info, err := os.Stat(filepath.Join(dir, "certs/dev/server.pem"))
+	if err != nil {
+		t.Fatalf("certs/dev/server.pem was not preserved: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("certs/dev/server.pem mode = %v, want 0600", info.Mode().Perm())
+	}
+	if _, err := os.Stat(filepath.Join(dir, "scratch.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("scratch.txt should still be cleaned")
+	}
+}
+
+func TestRollbackChanges_PreserveUntracked(t *testing.T) {
+	dir := initGitRepo(t)
+	if err := os.MkdirAll(filepath.Join(dir, ".idea"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, ".doug"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, dir, ".envrc", "export A=1\n")
+	writeTestFile(t, dir, ".idea/workspace.xml", "<xml/>\n")
+	writeTestFile(t, dir, ".doug/project-state.yaml", "state: 1\n")
+	writeTestFile(t, dir, "scratch.txt", "gone\n")
+
+	// A negation cannot re-expose the built-in excludes.
+	if err := git.RollbackChanges(dir, nil, []string{".envrc", ".idea/", "!.doug/"}); err != nil {
+		t.Fatalf("RollbackChanges: %v", err)
+	}
+
+	for _, name := range []string{".envrc", ".idea/workspace.xml", ".doug/project-state.yaml"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s should be preserved: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "scratch.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("scratch.txt should still be cleaned")
+	}
+}
+
 // --- Commit ---
 
 func TestCommit_NothingToCommit_ReturnsErrNothingToCommit(t *testing.T) {
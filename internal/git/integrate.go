@@ -0,0 +1,137 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/robertgumeny/doug/internal/config"
+)
+
+// ErrMergeConflict is returned by IntegrateBranch when the branch does not
+// merge cleanly. The merge has been aborted and the branch checked out again.
+var ErrMergeConflict = errors.New("merge conflict")
+
+// ErrNotFastForward is returned by IntegrateBranch for config.EpicFastForward
+// when the base branch has diverged from the branch.
+var ErrNotFastForward = errors.New("not a fast-forward")
+
+// IntegrateBranch integrates branch into base according to strategy, one of
+// the on_epic_complete strategies: config.EpicLeave does nothing,
+// config.EpicMerge runs git merge --no-ff, config.EpicSquash git merge --squash
+// followed by one commit, and config.EpicFastForward git merge --ff-only.
+// message is the commit message for EpicMerge and EpicSquash and is ignored
+// otherwise.
+//
+// On success base is left checked out, so work that follows starts from the
+// integrated result. On failure the repository is restored to branch with a
+// clean working tree: a conflicting merge is aborted and reported as
+// ErrMergeConflict naming the conflicted files, and a diverged fast-forward
+// as ErrNotFastForward. A squash that introduces no changes is not an error.
+func IntegrateBranch(projectRoot, branch, base, strategy, message string) error {
+	switch strategy {
+	case config.EpicLeave:
+		return nil
+	case config.EpicMerge, config.EpicSquash, config.EpicFastForward:
+	default:
+		return fmt.Errorf("IntegrateBranch: unknown strategy %q", strategy)
+	}
+	if base == "" {
+		return errors.New("IntegrateBranch: base branch is empty")
+	}
+	if base == branch {
+		return fmt.Errorf("IntegrateBranch: branch %q cannot be integrated into itself", branch)
+	}
+	exists, err := branchExists(base, projectRoot)
+	if err != nil {
+		return fmt.Errorf("IntegrateBranch: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("IntegrateBranch: base branch %q does not exist", base)
+	}
+
+	if _, err := runGit(projectRoot, "checkout", base); err != nil {
+		return fmt.Errorf("IntegrateBranch: checkout %q: %w", base, err)
+	}
+
+	switch strategy {
+	case config.EpicMerge:
+		if _, err := runGit(projectRoot, "merge", "--no-ff", "-m", message, branch); err != nil {
+			return abortMerge(projectRoot, branch, base, err, "merge", "--abort")
+		}
+	case config.EpicSquash:
+		if _, err := runGit(projectRoot, "merge", "--squash", branch); err != nil {
+			// A squash merge records no MERGE_HEAD, so there is nothing for
+			// merge --abort to undo; reset the index and tree instead.
+			return abortMerge(projectRoot, branch, base, err, "reset", "--hard", "HEAD")
+		}
+		if err := Commit(message, projectRoot); err != nil && !errors.Is(err, ErrNothingToCommit) {
+			_, _ = runGit(projectRoot, "reset", "--hard", "HEAD")
+			_, _ = runGit(projectRoot, "checkout", branch)
+			return fmt.Errorf("IntegrateBranch: %w", err)
+		}
+	case config.EpicFastForward:
+		if _, err := runGit(projectRoot, "merge", "--ff-only", branch); err != nil {
+			_, _ = runGit(projectRoot, "checkout", branch)
+			return fmt.Errorf("IntegrateBranch: %s into %s: %w", branch, base, ErrNotFastForward)
+		}
+	}
+	return nil
+}
+
+// abortMerge undoes a failed merge with the given git arguments and checks
+// branch out again. mergeErr is reported as ErrMergeConflict when the merge
+// left conflicted paths, and returned as-is otherwise.
+func abortMerge(projectRoot, branch, base string, mergeErr error, abortArgs ...string) error {
+	conflicted, _ := runGit(projectRoot, "diff", "--name-only", "--diff-filter=U")
+	if _, err := runGit(projectRoot, abortArgs...); err != nil {
+		return fmt.Errorf("IntegrateBranch: abort merge of %s into %s: %w (merge error: %v)", branch, base, err, mergeErr)
+	}
+	if _, err := runGit(projectRoot, "checkout", branch); err != nil {
+		return fmt.Errorf("IntegrateBranch: checkout %q after aborted merge: %w", branch, err)
+	}
+
+	files := strings.Fields(conflicted)
+	if len(files) == 0 {
+		return fmt.Errorf("IntegrateBranch: merge %s into %s: %w", branch, base, mergeErr)
+	}
+	return fmt.Errorf("IntegrateBranch: %s into %s: %w in %s", branch, base, ErrMergeConflict, strings.Join(files, ", "))
+}
+
+// AddedLines returns the lines added to path between the merge base of from
+// and to, and to (git diff from...to), without their leading "+".
+func AddedLines(projectRoot, from, to, path string) ([]string, error) {
+	out, err := runGit(projectRoot, "diff", "--unified=0", "--no-color", from+"..."+to, "--", path)
+	if err != nil {
+		return nil, fmt.Errorf("AddedLines: %w", err)
+	}
+	var added []string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++") {
+			added = append(added, line[1:])
+		}
+	}
+	return added, nil
+}
+
+// runGit runs git with args in projectRoot and returns its trimmed stdout.
+// A failure includes git's combined output in the error.
+func runGit(projectRoot string, args ...string) (string, error) {
+	return runGitEnv(projectRoot, nil, args...)
+}
+
+// runGitEnv is runGit with an explicit environment; nil inherits doug's.
+func runGitEnv(projectRoot string, env []string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = projectRoot
+	cmd.Env = env
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String() + "\n" + string(out))
+		return "", fmt.Errorf("git %s: %w\n%s", args[0], err, msg)
+	}
+	return strings.TrimSpace(string(out)), nil
+}
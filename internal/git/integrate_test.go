@@ -0,0 +1,181 @@
+package git_test
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/robertgumeny/doug/internal/config"
+	"github.com/robertgumeny/doug/internal/git"
+)
+
+// setupEpicBranch creates feature/EPIC-1 in a fresh repository with one commit
+// that adds feature.txt and a CHANGELOG entry, and returns the repository and
+// the name of its base branch. feature/EPIC-1 is left checked out.
+func setupEpicBranch(t *testing.T) (dir, base string) {
+	t.Helper()
+	dir = initGitRepo(t)
+	writeTestFile(t, dir, "CHANGELOG.md", "# Changelog\n\n### Added\n")
+	gitAddCommit(t, dir, "add changelog")
+	base = currentBranchOf(t, dir)
+
+	if err := git.EnsureEpicBranch("feature/EPIC-1", dir); err != nil {
+		t.Fatalf("EnsureEpicBranch: %v", err)
+	}
+	writeTestFile(t, dir, "feature.txt", "feature\n")
+	writeTestFile(t, dir, "CHANGELOG.md", "# Changelog\n\n### Added\n- Add the feature\n")
+	gitAddCommit(t, dir, "feat: add feature")
+	return dir, base
+}
+
+// gitOutput runs git in dir and returns its trimmed stdout.
+func gitOutput(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %v: %v", args, err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// commitOnBase commits a change to README.md on base so it diverges from the
+// epic branch, then checks the epic branch out again.
+func commitOnBase(t *testing.T, dir, base, readme string) {
+	t.Helper()
+	gitOutput(t, dir, "checkout", base)
+	writeTestFile(t, dir, "README.md", readme)
+	gitAddCommit(t, dir, "change readme on base")
+	gitOutput(t, dir, "checkout", "feature/EPIC-1")
+}
+
+func TestIntegrateBranch_Leave_IsNoOp(t *testing.T) {
+	dir, base := setupEpicBranch(t)
+	before := gitOutput(t, dir, "rev-parse", base)
+
+	if err := git.IntegrateBranch(dir, "feature/EPIC-1", base, config.EpicLeave, ""); err != nil {
+		t.Fatalf("IntegrateBranch: %v", err)
+	}
+	if got := currentBranchOf(t, dir); got != "feature/EPIC-1" {
+		t.Errorf("current branch = %q, want feature/EPIC-1", got)
+	}
+	if after := gitOutput(t, dir, "rev-parse", base); after != before {
+		t.Errorf("base moved from %s to %s", before, after)
+	}
+}
+
+func TestIntegrateBranch_Merge_CreatesMergeCommit(t *testing.T) {
+	dir, base := setupEpicBranch(t)
+	commitOnBase(t, dir, base, "# changed on base\n")
+
+	if err := git.IntegrateBranch(dir, "feature/EPIC-1", base, config.EpicMerge, "chore: merge EPIC-1"); err != nil {
+		t.Fatalf("IntegrateBranch: %v", err)
+	}
+	if got := currentBranchOf(t, dir); got != base {
+		t.Errorf("current branch = %q, want %q", got, base)
+	}
+	if parents := strings.Fields(gitOutput(t, dir, "log", "-1", "--format=%P")); len(parents) != 2 {
+		t.Errorf("expected a merge commit with 2 parents, got %v", parents)
+	}
+	if msg := gitOutput(t, dir, "log", "-1", "--format=%s"); msg != "chore: merge EPIC-1" {
+		t.Errorf("merge message = %q", msg)
+	}
+	if got := readTestFile(t, dir, "feature.txt"); got != "feature\n" {
+		t.Errorf("feature.txt = %q", got)
+	}
+}
+
+func TestIntegrateBranch_Squash_CreatesSingleCommit(t *testing.T) {
+	dir, base := setupEpicBranch(t)
+	before := gitOutput(t, dir, "rev-parse", base)
+
+	if err := git.IntegrateBranch(dir, "feature/EPIC-1", base, config.EpicSquash, "feat: EPIC-1\n\n- Add the feature"); err != nil {
+		t.Fatalf("IntegrateBranch: %v", err)
+	}
+	if parent := gitOutput(t, dir, "log", "-1", "--format=%P"); parent != before {
+		t.Errorf("squash commit parent = %s, want previous base %s", parent, before)
+	}
+	if body := gitOutput(t, dir, "log", "-1", "--format=%B"); body != "feat: EPIC-1\n\n- Add the feature" {
+		t.Errorf("squash message = %q", body)
+	}
+	if got := readTestFile(t, dir, "feature.txt"); got != "feature\n" {
+		t.Errorf("feature.txt = %q", got)
+	}
+}
+
+func TestIntegrateBranch_FastForward(t *testing.T) {
+	dir, base := setupEpicBranch(t)
+	tip := gitOutput(t, dir, "rev-parse", "feature/EPIC-1")
+
+	if err := git.IntegrateBranch(dir, "feature/EPIC-1", base, config.EpicFastForward, ""); err != nil {
+		t.Fatalf("IntegrateBranch: %v", err)
+	}
+	if got := gitOutput(t, dir, "rev-parse", base); got != tip {
+		t.Errorf("base = %s, want epic tip %s", got, tip)
+	}
+}
+
+func TestIntegrateBranch_FastForward_DivergedReturnsErrNotFastForward(t *testing.T) {
+	dir, base := setupEpicBranch(t)
+	commitOnBase(t, dir, base, "# changed on base\n")
+
+	err := git.IntegrateBranch(dir, "feature/EPIC-1", base, config.EpicFastForward, "")
+	if !errors.Is(err, git.ErrNotFastForward) {
+		t.Fatalf("expected ErrNotFastForward, got: %v", err)
+	}
+	if got := currentBranchOf(t, dir); got != "feature/EPIC-1" {
+		t.Errorf("current branch = %q, want feature/EPIC-1", got)
+	}
+}
+
+func TestIntegrateBranch_Conflict_AbortsCleanly(t *testing.T) {
+	for _, strategy := range []string{config.EpicMerge, config.EpicSquash} {
+		t.Run(strategy, func(t *testing.T) {
+			dir, base := setupEpicBranch(t)
+			writeTestFile(t, dir, "README.md", "# changed on epic\n")
+			gitAddCommit(t, dir, "change readme on epic")
+			commitOnBase(t, dir, base, "# changed on base\n")
+			before := gitOutput(t, dir, "rev-parse", base)
+
+			err := git.IntegrateBranch(dir, "feature/EPIC-1", base, strategy, "integrate EPIC-1")
+			if !errors.Is(err, git.ErrMergeConflict) {
+				t.Fatalf("expected ErrMergeConflict, got: %v", err)
+			}
+			if !strings.Contains(err.Error(), "README.md") {
+				t.Errorf("expected conflicted file in error, got: %v", err)
+			}
+			if got := currentBranchOf(t, dir); got != "feature/EPIC-1" {
+				t.Errorf("current branch = %q, want feature/EPIC-1", got)
+			}
+			if status := gitOutput(t, dir, "status", "--porcelain"); status != "" {
+				t.Errorf("expected a clean working tree, got:\n%s", status)
+			}
+			if after := gitOutput(t, dir, "rev-parse", base); after != before {
+				t.Errorf("base moved from %s to %s", before, after)
+			}
+		})
+	}
+}
+
+func TestIntegrateBranch_MissingBaseReturnsError(t *testing.T) {
+	dir, _ := setupEpicBranch(t)
+	err := git.IntegrateBranch(dir, "feature/EPIC-1", "release", config.EpicMerge, "merge")
+	if err == nil || !strings.Contains(err.Error(), "release") {
+		t.Fatalf("expected error naming the missing base branch, got: %v", err)
+	}
+}
+
+func TestAddedLines_ReturnsLinesAddedOnBranch(t *testing.T) {
+	dir, base := setupEpicBranch(t)
+	commitOnBase(t, dir, base, "# changed on base\n")
+
+	lines, err := git.AddedLines(dir, base, "feature/EPIC-1", "CHANGELOG.md")
+	if err != nil {
+		t.Fatalf("AddedLines: %v", err)
+	}
+	if len(lines) != 1 || lines[0] != "- Add the feature" {
+		t.Errorf("AddedLines = %q, want [\"- Add the feature\"]", lines)
+	}
+}
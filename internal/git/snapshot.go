@@ -0,0 +1,231 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AttemptRefPrefix is the namespace holding snapshots of rejected attempts.
+// Refs outside refs/heads and refs/tags are not fetched or pushed by default,
+// so snapshots stay local to the machine that ran the attempt.
+const AttemptRefPrefix = "refs/doug/attempts/"
+
+// ErrNothingToSnapshot is returned by SnapshotWorkingTree when the working
+// tree matches HEAD, so there is no attempt worth keeping.
+var ErrNothingToSnapshot = errors.New("nothing to snapshot")
+
+// snapshotExcludes are left out of attempt snapshots: orchestrator state is
+// not the agent's work, and .env may hold secrets.
+var snapshotExcludes = []string{":(exclude).doug", ":(exclude).env"}
+
+// Snapshot is one attempt stored under AttemptRefPrefix.
+type Snapshot struct {
+	Ref     string
+	Hash    string
+	Epic    string
+	TaskID  string
+	Attempt int
+	// Seq tells apart snapshots of the same attempt number, which happen when
+	// an interrupt gives the attempt back or doug unblock resets the count:
+	// 1 for {n}, 2 for {n}-2, and so on.
+	Seq     int
+	Date    string // committer date, ISO 8601
+	Subject string
+}
+
+// ID returns the last component of the snapshot's ref, "3" or "3-2", which is
+// how doug attempts names it.
+func (s Snapshot) ID() string {
+	if s.Seq <= 1 {
+		return strconv.Itoa(s.Attempt)
+	}
+	return fmt.Sprintf("%d-%d", s.Attempt, s.Seq)
+}
+
+// AttemptRef returns the base ref for attempt n of task in epic:
+// refs/doug/attempts/{epic}/{task}/{n}. SnapshotWorkingTree appends -2, -3,
+// ... when that ref is already taken.
+func AttemptRef(epic, task string, n int) string {
+	return fmt.Sprintf("%s%s/%s/%d", AttemptRefPrefix, epic, task, n)
+}
+
+// SnapshotWorkingTree records the working tree, untracked files included, as
+// a commit whose parent is HEAD and points a new ref at it: ref itself, or
+// ref-2, ref-3, ... when ref already exists, so an earlier snapshot is never
+// overwritten. Ignored files, .doug/ and .env are left out. The branch, the
+// index and the working tree are not modified: the snapshot is built in a
+// temporary index.
+//
+// Returns the ref written and the snapshot commit hash, or
+// ErrNothingToSnapshot when the working tree matches HEAD.
+func SnapshotWorkingTree(projectRoot, ref, message string) (string, string, error) {
+	tmp, err := os.MkdirTemp("", "doug-snapshot-")
+	if err != nil {
+		return "", "", fmt.Errorf("SnapshotWorkingTree: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+	env := append(os.Environ(), "GIT_INDEX_FILE="+filepath.Join(tmp, "index"))
+
+	steps := [][]string{
+		{"read-tree", "HEAD"},
+		append([]string{"add", "-A", "--", "."}, snapshotExcludes...),
+	}
+	for _, args := range steps {
+		if _, err := runGitEnv(projectRoot, env, args...); err != nil {
+			return "", "", fmt.Errorf("SnapshotWorkingTree: %w", err)
+		}
+	}
+	tree, err := runGitEnv(projectRoot, env, "write-tree")
+	if err != nil {
+		return "", "", fmt.Errorf("SnapshotWorkingTree: %w", err)
+	}
+	head, err := runGit(projectRoot, "rev-parse", "HEAD^{tree}")
+	if err != nil {
+		return "", "", fmt.Errorf("SnapshotWorkingTree: %w", err)
+	}
+	if tree == head {
+		return "", "", ErrNothingToSnapshot
+	}
+
+	hash, err := runGit(projectRoot, "commit-tree", tree, "-p", "HEAD", "-m", message)
+	if err != nil {
+		return "", "", fmt.Errorf("SnapshotWorkingTree: %w", err)
+	}
+	for seq := 1; ; seq++ {
+		name := ref
+		if seq > 1 {
+			name = fmt.Sprintf("%s-%d", ref, seq)
+		}
+		// An empty old value makes update-ref fail instead of overwriting
+		// a ref that exists, even one created since the check below.
+		_, err := runGit(projectRoot, "update-ref", name, hash, "")
+		if err == nil {
+			return name, hash, nil
+		}
+		if _, verr := runGit(projectRoot, "rev-parse", "--verify", "--quiet", name); verr != nil {
+			return "", "", fmt.Errorf("SnapshotWorkingTree: %w", err)
+		}
+	}
+}
+
+// ListSnapshots returns the attempt snapshots for task in epic, ordered by
+// attempt number and then by Seq. An empty task lists every task in epic.
+func ListSnapshots(projectRoot, epic, task string) ([]Snapshot, error) {
+	pattern := AttemptRefPrefix + epic + "/"
+	if task != "" {
+		pattern += task + "/"
+	}
+	out, err := runGit(projectRoot, "for-each-ref",
+		"--format=%(refname)%00%(objectname)%00%(committerdate:iso-strict)%00%(subject)", pattern)
+	if err != nil {
+		return nil, fmt.Errorf("ListSnapshots: %w", err)
+	}
+
+	var snaps []Snapshot
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\x00")
+		if len(fields) != 4 {
+			continue
+		}
+		// refs/doug/attempts/{epic}/{task}/{n} or .../{n}-{seq}
+		parts := strings.Split(strings.TrimPrefix(fields[0], AttemptRefPrefix), "/")
+		if len(parts) != 3 {
+			continue
+		}
+		n, seq, ok := parseAttemptID(parts[2])
+		if !ok {
+			continue
+		}
+		snaps = append(snaps, Snapshot{
+			Ref:     fields[0],
+			Hash:    fields[1],
+			Epic:    parts[0],
+			TaskID:  parts[1],
+			Attempt: n,
+			Seq:     seq,
+			Date:    fields[2],
+			Subject: fields[3],
+		})
+	}
+	// for-each-ref sorts by refname, which puts attempt 10 before attempt 2.
+	sort.SliceStable(snaps, func(i, j int) bool {
+		if snaps[i].TaskID != snaps[j].TaskID {
+			return snaps[i].TaskID < snaps[j].TaskID
+		}
+		if snaps[i].Attempt != snaps[j].Attempt {
+			return snaps[i].Attempt < snaps[j].Attempt
+		}
+		return snaps[i].Seq < snaps[j].Seq
+	})
+	return snaps, nil
+}
+
+// parseAttemptID splits a Snapshot.ID, "{n}" or "{n}-{seq}", into its
+// attempt number and sequence. ok is false for anything else.
+func parseAttemptID(id string) (n, seq int, ok bool) {
+	attempt, suffix, found := strings.Cut(id, "-")
+	n, err := strconv.Atoi(attempt)
+	if err != nil || n < 1 {
+		return 0, 0, false
+	}
+	if !found {
+		return n, 1, true
+	}
+	seq, err = strconv.Atoi(suffix)
+	if err != nil || seq < 2 {
+		return 0, 0, false
+	}
+	return n, seq, true
+}
+
+// ShowSnapshot returns the snapshot commit's message and a summary of the
+// files it changes relative to its parent (git show --stat).
+func ShowSnapshot(projectRoot, ref string) (string, error) {
+	out, err := runGit(projectRoot, "show", "--stat", "--format=commit %H%nDate:   %cI%n%n%B", ref)
+	if err != nil {
+		return "", fmt.Errorf("ShowSnapshot: %w", err)
+	}
+	return out, nil
+}
+
+// DiffSnapshot returns the changes the attempt made, as a patch against the
+// commit it started from.
+func DiffSnapshot(projectRoot, ref string) (string, error) {
+	// Not runGit: trimming could drop a trailing blank context line and
+	// corrupt the patch.
+	cmd := exec.Command("git", "diff", "--binary", ref+"^", ref)
+	cmd.Dir = projectRoot
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("DiffSnapshot: git diff: %w\n%s", err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// RestoreSnapshot applies the attempt's changes (DiffSnapshot) to the working
+// tree. The index and branch are not touched. Nothing is written when the
+// patch does not apply cleanly, e.g. because the files changed since.
+func RestoreSnapshot(projectRoot, ref string) error {
+	patch, err := DiffSnapshot(projectRoot, ref)
+	if err != nil {
+		return fmt.Errorf("RestoreSnapshot: %w", err)
+	}
+	if patch == "" {
+		return nil
+	}
+	cmd := exec.Command("git", "apply", "--whitespace=nowarn", "-")
+	cmd.Dir = projectRoot
+	cmd.Stdin = strings.NewReader(patch)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("RestoreSnapshot: git apply: %w\n%s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
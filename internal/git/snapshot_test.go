@@ -0,0 +1,145 @@
+package git_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robertgumeny/doug/internal/git"
+)
+
+func TestSnapshotWorkingTree_KeepsTrackedAndUntrackedChanges(t *testing.T) {
+	dir := initGitRepo(t)
+	writeTestFile(t, dir, "README.md", "# changed by agent\n")
+	writeTestFile(t, dir, "new.go", "package main\n")
+	gitOutput(t, dir, "add", "new.go") // staged: the real index must survive
+	if err := os.MkdirAll(filepath.Join(dir, ".doug"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, dir, ".doug/project-state.yaml", "state\n")
+
+	head := gitOutput(t, dir, "rev-parse", "HEAD")
+	statusBefore := gitOutput(t, dir, "status", "--porcelain")
+	ref := git.AttemptRef("EPIC-1", "EPIC-1-001", 3)
+	if ref != "refs/doug/attempts/EPIC-1/EPIC-1-001/3" {
+		t.Fatalf("AttemptRef = %q", ref)
+	}
+
+	saved, hash, err := git.SnapshotWorkingTree(dir, ref, "attempt 3")
+	if err != nil {
+		t.Fatalf("SnapshotWorkingTree: %v", err)
+	}
+	if saved != ref {
+		t.Errorf("saved to %q, want %q", saved, ref)
+	}
+	if got := gitOutput(t, dir, "rev-parse", ref); got != hash {
+		t.Errorf("ref points at %s, want %s", got, hash)
+	}
+	if got := gitOutput(t, dir, "rev-parse", "HEAD"); got != head {
+		t.Errorf("HEAD moved from %s to %s", head, got)
+	}
+	if got := gitOutput(t, dir, "status", "--porcelain"); got != statusBefore {
+		t.Errorf("status changed:\n%s\nwant:\n%s", got, statusBefore)
+	}
+	if got := gitOutput(t, dir, "rev-parse", hash+"^"); got != head {
+		t.Errorf("snapshot parent = %s, want HEAD %s", got, head)
+	}
+	files := gitOutput(t, dir, "diff", "--name-only", hash+"^", hash)
+	if files != "README.md\nnew.go" {
+		t.Errorf("snapshot files = %q, want README.md and new.go only", files)
+	}
+}
+
+func TestSnapshotWorkingTree_CleanTreeReturnsErrNothingToSnapshot(t *testing.T) {
+	dir := initGitRepo(t)
+	_, _, err := git.SnapshotWorkingTree(dir, git.AttemptRef("EPIC-1", "EPIC-1-001", 1), "attempt 1")
+	if !errors.Is(err, git.ErrNothingToSnapshot) {
+		t.Fatalf("expected ErrNothingToSnapshot, got: %v", err)
+	}
+}
+
+func TestSnapshotWorkingTree_ExistingRefGetsSuffix(t *testing.T) {
+	dir := initGitRepo(t)
+	ref := git.AttemptRef("EPIC-1", "EPIC-1-001", 2)
+	var hashes []string
+	for i, want := range []string{ref, ref + "-2", ref + "-3"} {
+		writeTestFile(t, dir, "work.txt", strings.Repeat("x", i+1))
+		saved, hash, err := git.SnapshotWorkingTree(dir, ref, "attempt 2")
+		if err != nil {
+			t.Fatalf("SnapshotWorkingTree: %v", err)
+		}
+		if saved != want {
+			t.Errorf("snapshot %d saved to %q, want %q", i+1, saved, want)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	snaps, err := git.ListSnapshots(dir, "EPIC-1", "EPIC-1-001")
+	if err != nil {
+		t.Fatalf("ListSnapshots: %v", err)
+	}
+	if len(snaps) != 3 {
+		t.Fatalf("expected 3 snapshots, got %+v", snaps)
+	}
+	for i, s := range snaps {
+		if s.Attempt != 2 || s.Seq != i+1 || s.Hash != hashes[i] {
+			t.Errorf("snapshot %d = %+v, want attempt 2, seq %d, hash %s", i, s, i+1, hashes[i])
+		}
+	}
+	if got := snaps[1].ID(); got != "2-2" {
+		t.Errorf("ID() = %q, want 2-2", got)
+	}
+}
+
+func TestListSnapshots_OrdersByAttemptNumber(t *testing.T) {
+	dir := initGitRepo(t)
+	for _, n := range []int{10, 2, 1} {
+		writeTestFile(t, dir, "work.txt", strings.Repeat("x", n))
+		if _, _, err := git.SnapshotWorkingTree(dir, git.AttemptRef("EPIC-1", "EPIC-1-001", n), "attempt"); err != nil {
+			t.Fatalf("SnapshotWorkingTree: %v", err)
+		}
+	}
+	if _, _, err := git.SnapshotWorkingTree(dir, git.AttemptRef("EPIC-2", "EPIC-2-001", 1), "other epic"); err != nil {
+		t.Fatalf("SnapshotWorkingTree: %v", err)
+	}
+
+	snaps, err := git.ListSnapshots(dir, "EPIC-1", "EPIC-1-001")
+	if err != nil {
+		t.Fatalf("ListSnapshots: %v", err)
+	}
+	var got []int
+	for _, s := range snaps {
+		got = append(got, s.Attempt)
+	}
+	if len(got) != 3 || got[0] != 1 || got[1] != 2 || got[2] != 10 {
+		t.Errorf("attempts = %v, want [1 2 10]", got)
+	}
+}
+
+func TestRestoreSnapshot_ReappliesAttempt(t *testing.T) {
+	dir := initGitRepo(t)
+	writeTestFile(t, dir, "README.md", "# attempt\n")
+	writeTestFile(t, dir, "new.go", "package main\n")
+	ref := git.AttemptRef("EPIC-1", "EPIC-1-001", 1)
+	if _, _, err := git.SnapshotWorkingTree(dir, ref, "attempt 1"); err != nil {
+		t.Fatalf("SnapshotWorkingTree: %v", err)
+	}
+	if err := git.RollbackChanges(dir, nil, nil); err != nil {
+		t.Fatalf("RollbackChanges: %v", err)
+	}
+
+	if err := git.RestoreSnapshot(dir, ref); err != nil {
+		t.Fatalf("RestoreSnapshot: %v", err)
+	}
+	if got := readTestFile(t, dir, "README.md"); got != "# attempt\n" {
+		t.Errorf("README.md = %q", got)
+	}
+	if got := readTestFile(t, dir, "new.go"); got != "package main\n" {
+		t.Errorf("new.go = %q", got)
+	}
+	if staged := gitOutput(t, dir, "diff", "--cached", "--name-only"); staged != "" {
+		t.Errorf("expected nothing staged, got %q", staged)
+	}
+}
@@ -0,0 +1,122 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Trailer keys appended to orchestrator commits.
+const (
+	TrailerTaskID   = "Doug-Task-ID"
+	TrailerEpic     = "Doug-Epic"
+	TrailerAttempt  = "Doug-Attempt"
+	TrailerAgent    = "Doug-Agent"
+	TrailerSession  = "Doug-Session"
+	TrailerDuration = "Doug-Duration"
+)
+
+// Trailers describes the orchestrator run that produced a commit. Zero fields
+// are omitted from the commit message. Session is the session result file,
+// relative to the project root; Duration is formatted with
+// time.Duration.String (e.g. "1m35s").
+type Trailers struct {
+	TaskID   string
+	Epic     string
+	Attempt  int
+	Agent    string
+	Session  string
+	Duration time.Duration
+}
+
+// CommitTrailers pairs a commit hash with the trailers parsed from it.
+type CommitTrailers struct {
+	Hash string
+	Trailers
+}
+
+// AppendTrailers returns message followed by a blank line and the non-zero
+// fields of t as git trailers, in the order of the Trailer* constants.
+// message is returned unchanged when every field is zero.
+func AppendTrailers(message string, t Trailers) string {
+	var lines []string
+	add := func(key, value string) {
+		if value != "" {
+			lines = append(lines, key+": "+value)
+		}
+	}
+	add(TrailerTaskID, t.TaskID)
+	add(TrailerEpic, t.Epic)
+	if t.Attempt > 0 {
+		add(TrailerAttempt, strconv.Itoa(t.Attempt))
+	}
+	add(TrailerAgent, t.Agent)
+	add(TrailerSession, t.Session)
+	if t.Duration > 0 {
+		add(TrailerDuration, t.Duration.String())
+	}
+	if len(lines) == 0 {
+		return message
+	}
+	return strings.TrimRight(message, "\n") + "\n\n" + strings.Join(lines, "\n")
+}
+
+// ParseTrailers reads the Doug-* trailers from the last paragraph of a commit
+// message. Other trailers and malformed values are ignored. The boolean is
+// false when the message carries no Doug-* trailer.
+func ParseTrailers(message string) (Trailers, bool) {
+	var t Trailers
+	paragraphs := strings.Split(strings.TrimSpace(message), "\n\n")
+	last := paragraphs[len(paragraphs)-1]
+
+	found := false
+	for _, line := range strings.Split(last, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.TrimSpace(key) {
+		case TrailerTaskID:
+			t.TaskID = value
+		case TrailerEpic:
+			t.Epic = value
+		case TrailerAttempt:
+			t.Attempt, _ = strconv.Atoi(value)
+		case TrailerAgent:
+			t.Agent = value
+		case TrailerSession:
+			t.Session = value
+		case TrailerDuration:
+			t.Duration, _ = time.ParseDuration(value)
+		default:
+			continue
+		}
+		found = true
+	}
+	return t, found
+}
+
+// ReadTrailers returns the Doug-* trailers of every commit in revRange (any
+// range git log accepts, e.g. "main..feature/EPIC-1" or "HEAD"), newest
+// first. Commits without Doug-* trailers are skipped.
+func ReadTrailers(projectRoot, revRange string) ([]CommitTrailers, error) {
+	// %x00 separates the hash from the message and %x1e ends each record, so
+	// message bodies can contain anything but NUL and RS.
+	out, err := runGit(projectRoot, "log", "--format=%H%x00%B%x1e", revRange, "--")
+	if err != nil {
+		return nil, fmt.Errorf("ReadTrailers: %w", err)
+	}
+	var commits []CommitTrailers
+	for _, record := range strings.Split(out, "\x1e") {
+		hash, body, ok := strings.Cut(strings.TrimSpace(record), "\x00")
+		if !ok {
+			continue
+		}
+		if t, found := ParseTrailers(body); found {
+			commits = append(commits, CommitTrailers{Hash: hash, Trailers: t})
+		}
+	}
+	return commits, nil
+}
@@ -0,0 +1,83 @@
+package git_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/robertgumeny/doug/internal/git"
+)
+
+func TestAppendTrailers_RoundTrip(t *testing.T) {
+	want := git.Trailers{
+		TaskID:   "EPIC-1-002",
+		Epic:     "EPIC-1",
+		Attempt:  2,
+		Agent:    "claude",
+		Session:  ".doug/logs/sessions/EPIC-1/session-EPIC-1-002_attempt-2.md",
+		Duration: 95 * time.Second,
+	}
+	msg := git.AppendTrailers("feat: EPIC-1-002\n", want)
+
+	wantMsg := "feat: EPIC-1-002\n\n" +
+		"Doug-Task-ID: EPIC-1-002\n" +
+		"Doug-Epic: EPIC-1\n" +
+		"Doug-Attempt: 2\n" +
+		"Doug-Agent: claude\n" +
+		"Doug-Session: .doug/logs/sessions/EPIC-1/session-EPIC-1-002_attempt-2.md\n" +
+		"Doug-Duration: 1m35s"
+	if msg != wantMsg {
+		t.Errorf("message =\n%s\nwant\n%s", msg, wantMsg)
+	}
+
+	got, ok := git.ParseTrailers(msg)
+	if !ok || got != want {
+		t.Errorf("ParseTrailers = %+v, %v; want %+v, true", got, ok, want)
+	}
+}
+
+func TestAppendTrailers_OmitsZeroFields(t *testing.T) {
+	if got := git.AppendTrailers("chore: finalize EPIC-1", git.Trailers{}); got != "chore: finalize EPIC-1" {
+		t.Errorf("expected message unchanged, got %q", got)
+	}
+	got := git.AppendTrailers("chore: finalize EPIC-1", git.Trailers{Epic: "EPIC-1"})
+	if got != "chore: finalize EPIC-1\n\nDoug-Epic: EPIC-1" {
+		t.Errorf("message = %q", got)
+	}
+}
+
+func TestParseTrailers_OnlyReadsLastParagraph(t *testing.T) {
+	msg := "feat: x\n\nDoug-Epic: not-a-trailer\n\nSigned-off-by: Dev <dev@example.com>\nDoug-Epic: EPIC-2\nDoug-Attempt: many"
+	got, ok := git.ParseTrailers(msg)
+	if !ok {
+		t.Fatal("expected Doug trailers to be found")
+	}
+	if got.Epic != "EPIC-2" || got.Attempt != 0 {
+		t.Errorf("got %+v, want Epic EPIC-2 and malformed Attempt ignored", got)
+	}
+
+	if _, ok := git.ParseTrailers("feat: plain message"); ok {
+		t.Error("expected no trailers in a plain message")
+	}
+}
+
+func TestReadTrailers_SkipsCommitsWithoutTrailers(t *testing.T) {
+	dir := initGitRepo(t)
+	for i, epic := range []string{"EPIC-1", "EPIC-2"} {
+		writeTestFile(t, dir, "file.txt", epic)
+		msg := git.AppendTrailers("feat: change", git.Trailers{Epic: epic, Attempt: i + 1})
+		if err := git.Commit(msg, dir); err != nil {
+			t.Fatalf("Commit: %v", err)
+		}
+	}
+
+	commits, err := git.ReadTrailers(dir, "HEAD")
+	if err != nil {
+		t.Fatalf("ReadTrailers: %v", err)
+	}
+	if len(commits) != 2 {
+		t.Fatalf("expected 2 commits (initial commit skipped), got %d: %+v", len(commits), commits)
+	}
+	if commits[0].Epic != "EPIC-2" || commits[1].Epic != "EPIC-1" || commits[0].Hash == "" {
+		t.Errorf("expected newest first with hashes, got %+v", commits)
+	}
+}
@@ -0,0 +1,119 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// outsideDoug is the pathspec for everything in the repository except the
+// orchestrator's own .doug/ directory.
+var outsideDoug = []string{"--", ".", ":(exclude).doug"}
+
+// UncommittedChanges returns the git status --porcelain entries (e.g.
+// " M main.go", "?? notes.txt") for modified, staged and untracked files
+// outside .doug/. Ignored files are not reported. An empty result means the
+// working tree is clean.
+func UncommittedChanges(projectRoot string) ([]string, error) {
+	// Not runGit: trimming would eat the leading space of the first entry's
+	// status column.
+	cmd := exec.Command("git", append([]string{"status", "--porcelain", "--untracked-files=all"}, outsideDoug...)...)
+	cmd.Dir = projectRoot
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("UncommittedChanges: git status: %w\n%s", err, strings.TrimSpace(stderr.String()))
+	}
+	var entries []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if strings.TrimSpace(line) != "" {
+			entries = append(entries, line)
+		}
+	}
+	return entries, nil
+}
+
+// ChangedPaths returns the paths outside .doug/ that differ from HEAD —
+// modified, added, deleted or untracked but not ignored — sorted and
+// slash-separated.
+func ChangedPaths(projectRoot string) ([]string, error) {
+	diff, err := runGit(projectRoot, append([]string{"diff", "--name-only", "-z", "HEAD"}, outsideDoug...)...)
+	if err != nil {
+		return nil, fmt.Errorf("ChangedPaths: %w", err)
+	}
+	untracked, err := runGit(projectRoot, append([]string{"ls-files", "--others", "--exclude-standard", "-z"}, outsideDoug...)...)
+	if err != nil {
+		return nil, fmt.Errorf("ChangedPaths: %w", err)
+	}
+	var paths []string
+	for _, p := range strings.Split(diff+"\x00"+untracked, "\x00") {
+		if p != "" && !slices.Contains(paths, p) {
+			paths = append(paths, p)
+		}
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
+
+// StashChanges stashes the changes UncommittedChanges reports, untracked
+// files included, under message and returns the stash commit hash for
+// PopStash. .doug/ is left in place.
+func StashChanges(projectRoot, message string) (string, error) {
+	before, _ := runGit(projectRoot, "rev-parse", "--quiet", "--verify", "refs/stash")
+	args := append([]string{"stash", "push", "--include-untracked", "-m", message}, outsideDoug...)
+	if _, err := runGit(projectRoot, args...); err != nil {
+		return "", fmt.Errorf("StashChanges: %w", err)
+	}
+	after, err := runGit(projectRoot, "rev-parse", "--quiet", "--verify", "refs/stash")
+	if err != nil || after == before {
+		return "", fmt.Errorf("StashChanges: %w", ErrNothingToCommit)
+	}
+	return after, nil
+}
+
+// PopStash re-applies the stash StashChanges created and drops it. The stash
+// is looked up by hash because other stashes may have been pushed since. On
+// a conflict git keeps the stash, so nothing is lost.
+func PopStash(projectRoot, hash string) error {
+	out, err := runGit(projectRoot, "stash", "list", "--format=%H")
+	if err != nil {
+		return fmt.Errorf("PopStash: %w", err)
+	}
+	for i, h := range strings.Split(out, "\n") {
+		if h != hash {
+			continue
+		}
+		if _, err := runGit(projectRoot, "stash", "pop", "--quiet", fmt.Sprintf("stash@{%d}", i)); err != nil {
+			return fmt.Errorf("PopStash: %w", err)
+		}
+		return nil
+	}
+	return fmt.Errorf("PopStash: stash %s not found", hash)
+}
+
+// CheckoutBranch checks out the existing local branch. Git refuses, and
+// nothing changes, when local changes would be overwritten.
+func CheckoutBranch(projectRoot, branch string) error {
+	if _, err := runGit(projectRoot, "checkout", branch); err != nil {
+		return fmt.Errorf("CheckoutBranch: %w", err)
+	}
+	return nil
+}
+
+// CommitOutsideDoug stages and commits every change outside .doug/ with
+// message. Changes inside .doug/ stay uncommitted. Returns ErrNothingToCommit
+// when there is nothing outside .doug/ to commit.
+func CommitOutsideDoug(projectRoot, message string) error {
+	if _, err := runGit(projectRoot, append([]string{"add", "-A"}, outsideDoug...)...); err != nil {
+		return fmt.Errorf("CommitOutsideDoug: %w", err)
+	}
+	if _, err := runGit(projectRoot, append([]string{"diff", "--cached", "--quiet"}, outsideDoug...)...); err == nil {
+		return fmt.Errorf("%w", ErrNothingToCommit)
+	}
+	if _, err := runGit(projectRoot, append([]string{"commit", "-m", message}, outsideDoug...)...); err != nil {
+		return fmt.Errorf("CommitOutsideDoug: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package git_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/robertgumeny/doug/internal/git"
+)
+
+// dirtyRepo returns a repository with a modified README.md, an untracked
+// notes.txt and changes inside .doug/.
+func dirtyRepo(t *testing.T) string {
+	t.Helper()
+	dir := initGitRepo(t)
+	if err := os.MkdirAll(filepath.Join(dir, ".doug"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, dir, ".doug/project-state.yaml", "state: 1\n")
+	gitAddCommit(t, dir, "add state")
+
+	writeTestFile(t, dir, "README.md", "# edited\n")
+	writeTestFile(t, dir, "notes.txt", "notes\n")
+	writeTestFile(t, dir, ".doug/project-state.yaml", "state: 2\n")
+	writeTestFile(t, dir, ".doug/tasks.yaml", "tasks: []\n")
+	return dir
+}
+
+func TestUncommittedChanges_CleanTree(t *testing.T) {
+	dir := initGitRepo(t)
+
+	changes, err := git.UncommittedChanges(dir)
+	if err != nil {
+		t.Fatalf("UncommittedChanges: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("changes = %q, want none", changes)
+	}
+}
+
+func TestUncommittedChanges_IgnoresDougDir(t *testing.T) {
+	dir := dirtyRepo(t)
+
+	changes, err := git.UncommittedChanges(dir)
+	if err != nil {
+		t.Fatalf("UncommittedChanges: %v", err)
+	}
+	want := []string{" M README.md", "?? notes.txt"}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %q, want %q", changes, want)
+	}
+}
+
+func TestChangedPaths(t *testing.T) {
+	dir := dirtyRepo(t)
+	writeTestFile(t, dir, "with space.txt", "x\n")
+
+	paths, err := git.ChangedPaths(dir)
+	if err != nil {
+		t.Fatalf("ChangedPaths: %v", err)
+	}
+	want := []string{"README.md", "notes.txt", "with space.txt"}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("paths = %q, want %q", paths, want)
+	}
+}
+
+func TestStashChanges_PopStashRestores(t *testing.T) {
+	dir := dirtyRepo(t)
+
+	hash, err := git.StashChanges(dir, "doug run: test")
+	if err != nil {
+		t.Fatalf("StashChanges: %v", err)
+	}
+	if changes, _ := git.UncommittedChanges(dir); len(changes) != 0 {
+		t.Fatalf("changes after stash = %q, want none", changes)
+	}
+	if got := readTestFile(t, dir, ".doug/project-state.yaml"); got != "state: 2\n" {
+		t.Errorf(".doug/project-state.yaml = %q, want it left in place", got)
+	}
+
+	// A stash pushed later must not be popped in place of ours.
+	writeTestFile(t, dir, "other.txt", "other\n")
+	if _, err := git.StashChanges(dir, "someone else"); err != nil {
+		t.Fatalf("StashChanges (other): %v", err)
+	}
+
+	if err := git.PopStash(dir, hash); err != nil {
+		t.Fatalf("PopStash: %v", err)
+	}
+	if got := readTestFile(t, dir, "README.md"); got != "# edited\n" {
+		t.Errorf("README.md = %q, want the stashed edit", got)
+	}
+	if got := readTestFile(t, dir, "notes.txt"); got != "notes\n" {
+		t.Errorf("notes.txt = %q, want the stashed file", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "other.txt")); !os.IsNotExist(err) {
+		t.Errorf("other.txt exists; the other stash should not have been popped")
+	}
+	if got := gitOutput(t, dir, "stash", "list", "--format=%gs"); strings.Count(got, "\n") != 0 || !strings.HasSuffix(got, ": someone else") {
+		t.Errorf("stash list = %q, want only the other stash", got)
+	}
+}
+
+func TestCommitOutsideDoug_LeavesDougUncommitted(t *testing.T) {
+	dir := dirtyRepo(t)
+
+	if err := git.CommitOutsideDoug(dir, "chore: baseline"); err != nil {
+		t.Fatalf("CommitOutsideDoug: %v", err)
+	}
+	if got := gitOutput(t, dir, "log", "-1", "--format=%s"); got != "chore: baseline" {
+		t.Errorf("HEAD subject = %q, want chore: baseline", got)
+	}
+	if got := gitOutput(t, dir, "show", "--name-only", "--format=", "HEAD"); got != "README.md\nnotes.txt" {
+		t.Errorf("committed files = %q, want README.md and notes.txt", got)
+	}
+	if got := gitOutput(t, dir, "status", "--porcelain", "--untracked-files=all"); got != "M .doug/project-state.yaml\n?? .doug/tasks.yaml" {
+		t.Errorf("status = %q, want only .doug/ changes", got)
+	}
+
+	if err := git.CommitOutsideDoug(dir, "chore: again"); !errors.Is(err, git.ErrNothingToCommit) {
+		t.Errorf("second CommitOutsideDoug = %v, want ErrNothingToCommit", err)
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/robertgumeny/doug/internal/config"
+	"github.com/robertgumeny/doug/internal/git"
+	"github.com/robertgumeny/doug/internal/log"
+	"github.com/robertgumeny/doug/internal/orchestrator"
+	"github.com/robertgumeny/doug/internal/state"
+	"github.com/robertgumeny/doug/internal/types"
+)
+
+// maxFailureReportBytes caps how much of ACTIVE_FAILURE.md is carried into
+// the next attempt's briefing.
+const maxFailureReportBytes = 8 << 10
+
+// rejectAttempt stores why the current attempt was rejected so the next
+// ACTIVE_TASK.md can include it, persists state, and fires the task_retry
+// hook. A save failure is logged and otherwise ignored; the record is still in
+// memory and will be saved at the start of the next iteration. The returned
+// error comes from an abort-policy hook.
+func rejectAttempt(ctx *orchestrator.LoopContext, reason, output, report string) error {
+	orchestrator.RecordAttempt(ctx.State, types.AttemptRecord{
+		TaskID:        ctx.TaskID,
+		Attempt:       ctx.Attempts,
+		Reason:        reason,
+		Output:        strings.TrimSpace(output),
+		FailureReport: strings.TrimSpace(report),
+	})
+	if err := state.SaveProjectState(ctx.StatePath, ctx.State); err != nil {
+		log.Warning(fmt.Sprintf("could not save attempt history for task %s: %v", ctx.TaskID, err))
+	}
+	return FireHook(ctx, config.HookTaskRetry, reason)
+}
+
+// rollbackAttempt saves the attempt's work under git.AttemptRef (or a free
+// suffix of it when the attempt number was used before), then rolls
+// the working tree back. The snapshot is best-effort: a failure is logged and
+// never prevents the rollback. The rollback keeps protectedPaths plus the
+// rollback.protect and rollback.preserve_untracked paths from doug.yaml.
+func rollbackAttempt(ctx *orchestrator.LoopContext, reason string) error {
+	ref := git.AttemptRef(ctx.State.CurrentEpic.ID, ctx.TaskID, ctx.Attempts)
+	msg := git.AppendTrailers(fmt.Sprintf("doug: %s attempt %d rolled back: %s", ctx.TaskID, ctx.Attempts, reason), git.Trailers{
+		TaskID:   ctx.TaskID,
+		Epic:     ctx.State.CurrentEpic.ID,
+		Attempt:  ctx.Attempts,
+		Agent:    agentName(ctx.Config.AgentCommand),
+		Session:  sessionRelPath(ctx),
+		Duration: time.Since(ctx.TaskStartTime).Truncate(time.Second),
+	})
+	switch saved, _, err := git.SnapshotWorkingTree(ctx.ProjectRoot, ref, msg); {
+	case err == nil:
+		log.Info(fmt.Sprintf("attempt saved to %s — restore it with: doug attempts restore %s %s", saved, ctx.TaskID, path.Base(saved)))
+	case !errors.Is(err, git.ErrNothingToSnapshot):
+		log.Warning(fmt.Sprintf("could not save attempt before rollback: %v", err))
+	}
+	protect := slices.Concat(protectedPaths, ctx.Config.Rollback.Protect)
+	return git.RollbackChanges(ctx.ProjectRoot, protect, ctx.Config.Rollback.PreserveUntracked)
+}
+
+// consumeFailureReport returns the content of .doug/ACTIVE_FAILURE.md,
+// truncated to maxFailureReportBytes, and removes the file so a stale report
+// is never mistaken for the next attempt's. A missing or unreadable file
+// yields "".
+func consumeFailureReport(dougDir string) string {
+	path := filepath.Join(dougDir, "ACTIVE_FAILURE.md")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	if err := os.Remove(path); err != nil {
+		log.Warning(fmt.Sprintf("could not remove %s: %v", path, err))
+	}
+	if len(data) > maxFailureReportBytes {
+		return string(data[:maxFailureReportBytes]) + "\n\n[doug: failure report truncated]"
+	}
+	return string(data)
+}
@@ -7,7 +7,7 @@ import (
 	"path/filepath"
 	"time"
 
-	"github.com/robertgumeny/doug/internal/git"
+	"github.com/robertgumeny/doug/internal/config"
 	"github.com/robertgumeny/doug/internal/log"
 	"github.com/robertgumeny/doug/internal/metrics"
 	"github.com/robertgumeny/doug/internal/orchestrator"
@@ -21,7 +21,8 @@ import (
 //  1. Nested bug check — if the current task is already a bugfix, return a
 //     Tier 3 fatal error immediately (before any rollback). A bugfix task
 //     that itself reports BUG would cause a death spiral.
-//  2. Rollback uncommitted changes (non-fatal; logged as warning).
+//  2. Snapshot the attempt (see rollbackAttempt) and roll back uncommitted
+//     changes (non-fatal; logged as warning).
 //  3. Record task metrics (non-fatal; in-memory).
 //  4. Generate bug ID: "BUG-" + ctx.TaskID.
 //  5. Archive bug report from logs/ACTIVE_BUG.md to
@@ -32,7 +33,10 @@ import (
 //     For synthetic tasks (documentation, etc.), type is taken from ctx.TaskType
 //     directly — this avoids a tasks.yaml lookup that would always miss (CI-5 fix).
 //  8. Persist updated state.
+//  9. Fire the bug_scheduled hook.
 func HandleBug(ctx *orchestrator.LoopContext) error {
+	log.SetTaskContext(ctx.LogContext())
+
 	// 1. Nested bug check — must run before rollback (Tier 3; no self-correction).
 	if ctx.TaskType == types.TaskTypeBugfix {
 		return fmt.Errorf("nested bug detected: task %s (type %s) reported BUG; "+
@@ -40,8 +44,8 @@ func HandleBug(ctx *orchestrator.LoopContext) error {
 			ctx.TaskID, ctx.TaskType)
 	}
 
-	// 2. Rollback changes. Non-fatal — log warning and continue.
-	if err := git.RollbackChanges(ctx.ProjectRoot, protectedPaths); err != nil {
+	// 2. Snapshot and roll back changes. Non-fatal — log warning and continue.
+	if err := rollbackAttempt(ctx, "agent reported BUG"); err != nil {
 		log.Warning(fmt.Sprintf("rollback failed: %v", err))
 	}
 
@@ -75,7 +79,9 @@ func HandleBug(ctx *orchestrator.LoopContext) error {
 
 	log.Warning(fmt.Sprintf("task %s interrupted by bug — scheduled bugfix %s; will resume %s next",
 		ctx.TaskID, bugID, ctx.TaskID))
-	return nil
+
+	// 9. Fire bug_scheduled; the payload describes the interrupted task.
+	return FireHook(ctx, config.HookBugScheduled, bugID)
 }
 
 // resolveInterruptedType returns the TaskType for the task that was interrupted
@@ -103,7 +109,8 @@ func resolveInterruptedType(ctx *orchestrator.LoopContext) types.TaskType {
 }
 
 // archiveBugReport copies .doug/ACTIVE_BUG.md to
-// .doug/logs/bugs/{epic}/bug-{taskID}.md.
+// .doug/logs/bugs/{epic}/bug-{taskID}.md, followed by a pointer to the agent
+// transcript of the attempt that reported the bug.
 //
 // Returns a non-fatal error when:
 //   - .doug/ACTIVE_BUG.md does not exist
@@ -123,6 +130,7 @@ func archiveBugReport(ctx *orchestrator.LoopContext, bugID string) error {
 	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
 		return fmt.Errorf("mkdir for bug archive: %w", err)
 	}
+	data = appendTranscriptRef(ctx, data)
 	if err := os.WriteFile(dst, data, 0o644); err != nil {
 		return fmt.Errorf("write bug archive: %w", err)
 	}
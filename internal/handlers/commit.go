@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/robertgumeny/doug/internal/config"
+	"github.com/robertgumeny/doug/internal/git"
+	"github.com/robertgumeny/doug/internal/log"
+	"github.com/robertgumeny/doug/internal/naming"
+	"github.com/robertgumeny/doug/internal/orchestrator"
+	"github.com/robertgumeny/doug/internal/shellargs"
+	"github.com/robertgumeny/doug/internal/types"
+)
+
+// taskCommitMessage renders commit_template for the task in ctx and appends
+// its Doug-* trailers. durationSeconds is the time the task took.
+func taskCommitMessage(ctx *orchestrator.LoopContext, durationSeconds int) string {
+	data := naming.NameData{
+		Epic:     ctx.State.CurrentEpic.ID,
+		EpicName: ctx.State.CurrentEpic.Name,
+		Task:     ctx.TaskID,
+		Type:     string(ctx.TaskType),
+		Kind:     commitKind(ctx.TaskType),
+		Attempt:  ctx.Attempts,
+	}
+	for _, t := range ctx.Tasks.Epic.Tasks {
+		if t.ID == ctx.TaskID {
+			data.Description = t.Description
+			break
+		}
+	}
+	if ctx.SessionResult != nil {
+		data.Changelog = ctx.SessionResult.ChangelogEntry
+	}
+	return git.AppendTrailers(commitMessage(ctx, data), git.Trailers{
+		TaskID:   ctx.TaskID,
+		Epic:     ctx.State.CurrentEpic.ID,
+		Attempt:  ctx.Attempts,
+		Agent:    agentName(ctx.Config.AgentCommand),
+		Session:  sessionRelPath(ctx),
+		Duration: time.Duration(durationSeconds) * time.Second,
+	})
+}
+
+// commitKind returns the conventional commit type for taskType.
+func commitKind(taskType types.TaskType) string {
+	switch taskType {
+	case types.TaskTypeBugfix:
+		return "fix"
+	case types.TaskTypeDocumentation:
+		return "docs"
+	default:
+		return "feat"
+	}
+}
+
+// commitMessage renders ctx.Config.CommitTemplate with data. The template is
+// validated when doug.yaml is loaded, but a render that still fails for this
+// particular data (an empty message, say) falls back to the default template
+// rather than losing the commit.
+//
+// Merge and squash commits (data.Type "merge" or "squash") use
+// config.DefaultMergeTemplate and config.DefaultSquashTemplate while
+// commit_template is not customized.
+func commitMessage(ctx *orchestrator.LoopContext, data naming.NameData) string {
+	fallback := config.DefaultCommitTemplate
+	switch data.Type {
+	case config.EpicMerge:
+		fallback = config.DefaultMergeTemplate
+	case config.EpicSquash:
+		fallback = config.DefaultSquashTemplate
+	}
+	if tmpl := ctx.Config.CommitTemplate; tmpl != "" && tmpl != config.DefaultCommitTemplate {
+		msg, err := naming.RenderCommitMessage(tmpl, data)
+		if err == nil {
+			return msg
+		}
+		log.Warning(fmt.Sprintf("commit_template failed, using the default message: %v", err))
+	}
+	msg, _ := naming.RenderCommitMessage(fallback, data)
+	return msg
+}
+
+// agentName returns the program name of agentCommand ("claude" for
+// `claude -p "..."`), leaving the prompt out of the trailer.
+func agentName(agentCommand string) string {
+	args, err := shellargs.Split(agentCommand)
+	if err != nil || len(args) == 0 {
+		return ""
+	}
+	return filepath.Base(args[0])
+}
+
+// sessionRelPath returns the attempt's session file relative to the project
+// root, with forward slashes, or "" when there is none.
+func sessionRelPath(ctx *orchestrator.LoopContext) string {
+	if ctx.SessionPath == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(ctx.ProjectRoot, ctx.SessionPath)
+	if err != nil {
+		return filepath.ToSlash(ctx.SessionPath)
+	}
+	return filepath.ToSlash(rel)
+}
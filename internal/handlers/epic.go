@@ -3,28 +3,61 @@ package handlers
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/robertgumeny/doug/internal/config"
 	"github.com/robertgumeny/doug/internal/git"
 	"github.com/robertgumeny/doug/internal/log"
 	"github.com/robertgumeny/doug/internal/metrics"
+	"github.com/robertgumeny/doug/internal/naming"
+	"github.com/robertgumeny/doug/internal/notify"
 	"github.com/robertgumeny/doug/internal/orchestrator"
 	"github.com/robertgumeny/doug/internal/state"
+	"github.com/robertgumeny/doug/internal/types"
 )
 
 // HandleEpicComplete processes the EPIC_COMPLETE outcome after the KB synthesis
 // documentation task succeeds (or when kb_enabled is false and all feature tasks
 // are DONE).
 //
+// An epic with BLOCKED tasks is not complete, however the outcome was
+// reached: active_task is set to manual_review, completed_at is cleared, and
+// a fatal error is returned before anything is committed or integrated, so the
+// run stops instead of rolling over to the next backlog epic.
+//
 // Sequence:
-//  1. Print epic summary (metrics table).
-//  2. git add -A, then commit with the epic finalization message.
+//  1. Print epic summary (metrics table), compared with the epics archived in
+//     ctx.HistoryDir. An unreadable history only drops the comparison.
+//  2. git add -A, then commit with the epic finalization message rendered from
+//     commit_template (Type "epic", no Task) and the Doug-Epic, Doug-Agent
+//     and Doug-Duration (whole epic) trailers.
 //     ErrNothingToCommit is treated as success — all changes were already
 //     committed by prior task handlers.
 //     Any other commit failure is a Tier 3 exit: the error is returned
 //     explicitly so the caller surfaces it as a non-zero exit code (CI-6 fix).
-//  3. Print the completion banner.
+//  3. Integrate the epic branch into on_epic_complete.base_branch (see
+//     integrateEpic). A conflict or diverged fast-forward is aborted, leaves
+//     the epic branch checked out, and is returned as an error.
+//  4. Print the completion banner.
+//  5. Publish the epic_complete event and fire the epic_complete hook.
 func HandleEpicComplete(ctx *orchestrator.LoopContext) error {
+	log.SetTaskContext(ctx.LogContext())
+
+	if blocked := orchestrator.BlockedTasks(ctx.Tasks); len(blocked) > 0 {
+		ctx.State.CurrentEpic.CompletedAt = nil
+		ctx.State.ActiveTask = types.TaskPointer{
+			Type: types.TaskTypeManualReview,
+			ID:   blocked[0],
+		}
+		ctx.State.NextTask = types.TaskPointer{}
+		if err := state.SaveProjectState(ctx.StatePath, ctx.State); err != nil {
+			log.Warning(fmt.Sprintf("could not save state after setting manual review: %v", err))
+		}
+		return fmt.Errorf("HandleEpicComplete: epic %s is not complete: %w", ctx.State.CurrentEpic.ID, orchestrator.BlockedTasksError(ctx.Tasks))
+	}
+
 	if ctx.State.CurrentEpic.CompletedAt == nil || *ctx.State.CurrentEpic.CompletedAt == "" {
 		now := time.Now().UTC().Format(time.RFC3339)
 		ctx.State.CurrentEpic.CompletedAt = &now
@@ -34,11 +67,30 @@ func HandleEpicComplete(ctx *orchestrator.LoopContext) error {
 	}
 
 	// 1. Print the metrics summary for the completed epic.
-	metrics.PrintEpicSummary(ctx.State)
+	var history []types.EpicHistory
+	if ctx.HistoryDir != "" {
+		h, err := state.LoadEpicHistory(ctx.HistoryDir)
+		if err != nil {
+			log.Warning(fmt.Sprintf("epic history unavailable for comparison: %v", err))
+		}
+		history = h
+	}
+	metrics.PrintEpicSummary(ctx.State, history)
 
 	// 2. Commit any remaining changes with the finalization message.
 	epicID := ctx.State.CurrentEpic.ID
-	commitMsg := fmt.Sprintf("chore: finalize %s", epicID)
+	commitMsg := commitMessage(ctx, naming.NameData{
+		Epic:        epicID,
+		EpicName:    ctx.State.CurrentEpic.Name,
+		Type:        "epic",
+		Kind:        "chore",
+		Description: ctx.State.CurrentEpic.Name,
+	})
+	commitMsg = git.AppendTrailers(commitMsg, git.Trailers{
+		Epic:     epicID,
+		Agent:    agentName(ctx.Config.AgentCommand),
+		Duration: time.Duration(ctx.State.Metrics.TotalDurationSeconds) * time.Second,
+	})
 	if err := git.Commit(commitMsg, ctx.ProjectRoot); err != nil {
 		if !errors.Is(err, git.ErrNothingToCommit) {
 			// Tier 3: return an explicit error — callers must check this and
@@ -50,10 +102,82 @@ func HandleEpicComplete(ctx *orchestrator.LoopContext) error {
 		log.Info(fmt.Sprintf("no new changes to commit for %s finalization", epicID))
 	}
 
-	// 3. Print the completion banner.
+	// 3. Integrate the epic branch.
+	if err := integrateEpic(ctx); err != nil {
+		return fmt.Errorf("HandleEpicComplete: %w", err)
+	}
+
+	// 4. Print the completion banner.
 	log.Section(fmt.Sprintf("EPIC %s COMPLETE", epicID))
 	log.Success(fmt.Sprintf("epic %s (%s) completed successfully",
 		epicID, ctx.State.CurrentEpic.Name))
 
+	// 5. Publish and fire epic_complete.
+	publishEvent(ctx, notify.EpicComplete, "")
+	return FireHook(ctx, config.HookEpicComplete, "")
+}
+
+// integrateEpic applies the on_epic_complete strategy to the epic branch. The
+// merge and squash commit messages are rendered by commitMessage; a squash
+// passes the CHANGELOG.md entries added on the branch as .Changelog.
+func integrateEpic(ctx *orchestrator.LoopContext) error {
+	oc := ctx.Config.OnEpicComplete
+	if oc.Strategy == "" || oc.Strategy == config.EpicLeave {
+		return nil
+	}
+
+	epic := ctx.State.CurrentEpic
+	branch := epic.BranchName
+	var message string
+	switch oc.Strategy {
+	case config.EpicMerge, config.EpicSquash:
+		data := naming.NameData{
+			Epic:        epic.ID,
+			EpicName:    epic.Name,
+			Type:        oc.Strategy,
+			Kind:        "chore",
+			Description: epic.Name,
+			Branch:      branch,
+			Base:        oc.BaseBranch,
+		}
+		if oc.Strategy == config.EpicSquash {
+			data.Kind = "feat"
+			data.Changelog = changelogEntries(ctx, oc.BaseBranch)
+		}
+		message = commitMessage(ctx, data)
+	}
+
+	if err := git.IntegrateBranch(ctx.ProjectRoot, branch, oc.BaseBranch, oc.Strategy, message); err != nil {
+		if errors.Is(err, git.ErrMergeConflict) || errors.Is(err, git.ErrNotFastForward) {
+			log.Error(fmt.Sprintf("could not %s %s into %s; the epic branch is unchanged — integrate it by hand", oc.Strategy, branch, oc.BaseBranch))
+		}
+		return fmt.Errorf("integrate %s into %s: %w", branch, oc.BaseBranch, err)
+	}
+	log.Success(fmt.Sprintf("integrated %s into %s (%s); %s is checked out", branch, oc.BaseBranch, oc.Strategy, oc.BaseBranch))
 	return nil
 }
+
+// changelogEntries returns the changelog bullets the epic branch added since
+// it left base, one per line. An unreadable changelog diff yields "".
+func changelogEntries(ctx *orchestrator.LoopContext, base string) string {
+	if ctx.ChangelogPath == "" {
+		return ""
+	}
+	rel, err := filepath.Rel(ctx.ProjectRoot, ctx.ChangelogPath)
+	if err != nil {
+		log.Warning(fmt.Sprintf("changelog entries unavailable for squash message: %v", err))
+		return ""
+	}
+	added, err := git.AddedLines(ctx.ProjectRoot, base, ctx.State.CurrentEpic.BranchName, rel)
+	if err != nil {
+		log.Warning(fmt.Sprintf("changelog entries unavailable for squash message: %v", err))
+		return ""
+	}
+	var entries []string
+	for _, line := range added {
+		if strings.HasPrefix(strings.TrimSpace(line), "- ") {
+			entries = append(entries, strings.TrimSpace(line))
+		}
+	}
+	return strings.Join(entries, "\n")
+}
@@ -2,6 +2,7 @@ package handlers_test
 
 import (
 	"errors"
+	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -36,6 +37,7 @@ func epicCtx(dir string, st *types.ProjectState) *orchestrator.LoopContext {
 		TasksPath:     filepath.Join(dougDir, "tasks.yaml"),
 		DougDir:       dougDir,
 		LogsDir:       filepath.Join(dougDir, "logs"),
+		HistoryDir:    filepath.Join(dougDir, "history"),
 		ChangelogPath: filepath.Join(dir, "CHANGELOG.md"),
 	}
 }
@@ -164,6 +166,18 @@ func TestHandleEpicComplete_MetricsTablePrinted(t *testing.T) {
 	}
 }
 
+func TestHandleEpicComplete_UnreadableHistoryIsNonFatal(t *testing.T) {
+	// A corrupt archive only drops the history comparison from the summary.
+	dir := setupGitRepo(t)
+	st := makeEpicCompleteState()
+	ctx := epicCtx(dir, st)
+	writeFile(t, filepath.Join(ctx.HistoryDir, "EPIC-4.yaml"), "epic: [unclosed")
+
+	if err := handlers.HandleEpicComplete(ctx); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
 func TestHandleEpicComplete_SetsCompletedAtWhenMissing(t *testing.T) {
 	dir := setupGitRepo(t)
 	st := makeEpicCompleteState()
@@ -181,3 +195,138 @@ func TestHandleEpicComplete_SetsCompletedAtWhenMissing(t *testing.T) {
 		t.Fatal("expected completed_at to be populated when missing")
 	}
 }
+
+func TestHandleEpicComplete_CommitTemplate(t *testing.T) {
+	dir := setupGitRepo(t)
+	ctx := epicCtx(dir, makeEpicCompleteState())
+	ctx.Config.CommitTemplate = "{{.Kind}}: {{with .Task}}{{.}}{{else}}close out {{.Epic}} ({{.Description}}){{end}}"
+	writeFile(t, filepath.Join(dir, "docs", "kb", "article.md"), "# KB Article\n")
+
+	if err := handlers.HandleEpicComplete(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "chore: close out EPIC-5 (Handlers & Main Loop)"
+	if got := gitOut(t, dir, "log", "-1", "--format=%s"); got != want {
+		t.Errorf("finalize message = %q, want %q", got, want)
+	}
+}
+
+// gitOut runs git in dir and returns its trimmed stdout.
+func gitOut(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("git %v: %v", args, err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestHandleEpicComplete_SquashIntoBaseBranch(t *testing.T) {
+	dir := setupGitRepo(t)
+	base := gitOut(t, dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err := git.EnsureEpicBranch("feature/EPIC-5", dir); err != nil {
+		t.Fatalf("EnsureEpicBranch: %v", err)
+	}
+	st := makeEpicCompleteState()
+	ctx := epicCtx(dir, st)
+	ctx.Config.OnEpicComplete = config.EpicCompleteConfig{Strategy: config.EpicSquash, BaseBranch: base}
+
+	writeFile(t, filepath.Join(dir, "CHANGELOG.md"), "# Changelog\n\n## [Unreleased]\n\n### Added\n\n- Add the handlers\n\n### Fixed\n\n### Changed\n")
+
+	if err := handlers.HandleEpicComplete(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := gitOut(t, dir, "rev-parse", "--abbrev-ref", "HEAD"); got != base {
+		t.Errorf("current branch = %q, want base %q", got, base)
+	}
+	want := "feat: EPIC-5 — Handlers & Main Loop\n\n- Add the handlers"
+	if got := gitOut(t, dir, "log", "-1", "--format=%B"); got != want {
+		t.Errorf("squash message = %q, want %q", got, want)
+	}
+}
+
+func TestHandleEpicComplete_MergeUsesCommitTemplate(t *testing.T) {
+	dir := setupGitRepo(t)
+	base := gitOut(t, dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err := git.EnsureEpicBranch("feature/EPIC-5", dir); err != nil {
+		t.Fatalf("EnsureEpicBranch: %v", err)
+	}
+	st := makeEpicCompleteState()
+	ctx := epicCtx(dir, st)
+	ctx.Config.OnEpicComplete = config.EpicCompleteConfig{Strategy: config.EpicMerge, BaseBranch: base}
+	ctx.Config.CommitTemplate = "{{.Type}}: {{.Epic}}{{with .Base}} into {{.}}{{end}}"
+	writeFile(t, filepath.Join(dir, "notes.md"), "epic\n")
+
+	if err := handlers.HandleEpicComplete(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got, want := gitOut(t, dir, "log", "-1", "--format=%s"), "merge: EPIC-5 into "+base; got != want {
+		t.Errorf("merge commit = %q, want %q", got, want)
+	}
+	if got := gitOut(t, dir, "log", "-1", "--format=%s", "HEAD^2"); got != "epic: EPIC-5" {
+		t.Errorf("finalization commit = %q, want %q", got, "epic: EPIC-5")
+	}
+}
+
+func TestHandleEpicComplete_MergeConflictReturnsError(t *testing.T) {
+	dir := setupGitRepo(t)
+	base := gitOut(t, dir, "rev-parse", "--abbrev-ref", "HEAD")
+	writeFile(t, filepath.Join(dir, "notes.md"), "base\n")
+	gitOut(t, dir, "add", "-A")
+	gitOut(t, dir, "commit", "-m", "base notes")
+	gitOut(t, dir, "checkout", "-b", "feature/EPIC-5", "HEAD~1")
+
+	st := makeEpicCompleteState()
+	ctx := epicCtx(dir, st)
+	ctx.Config.OnEpicComplete = config.EpicCompleteConfig{Strategy: config.EpicMerge, BaseBranch: base}
+	writeFile(t, filepath.Join(dir, "notes.md"), "epic\n")
+
+	err := handlers.HandleEpicComplete(ctx)
+	if !errors.Is(err, git.ErrMergeConflict) {
+		t.Fatalf("expected ErrMergeConflict, got: %v", err)
+	}
+	if got := gitOut(t, dir, "rev-parse", "--abbrev-ref", "HEAD"); got != "feature/EPIC-5" {
+		t.Errorf("current branch = %q, want feature/EPIC-5", got)
+	}
+	if status := gitOut(t, dir, "status", "--porcelain"); status != "" {
+		t.Errorf("expected a clean working tree after abort, got:\n%s", status)
+	}
+}
+
+func TestHandleEpicComplete_BlockedTasks_RefusesToFinalize(t *testing.T) {
+	dir := setupGitRepo(t)
+	base := gitOut(t, dir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err := git.EnsureEpicBranch("feature/EPIC-5", dir); err != nil {
+		t.Fatalf("EnsureEpicBranch: %v", err)
+	}
+	baseHead := gitOut(t, dir, "rev-parse", base)
+	st := makeEpicCompleteState()
+	ctx := epicCtx(dir, st)
+	ctx.Tasks = makeTwoTaskTasks(types.StatusDone, types.StatusBlocked)
+	ctx.Config.OnEpicComplete = config.EpicCompleteConfig{Strategy: config.EpicMerge, BaseBranch: base}
+
+	writeFile(t, filepath.Join(dir, "feature.txt"), "work\n")
+
+	err := handlers.HandleEpicComplete(ctx)
+
+	if err == nil {
+		t.Fatal("expected error when the epic has BLOCKED tasks")
+	}
+	if !strings.Contains(err.Error(), "EPIC-5-002") {
+		t.Errorf("error should name the BLOCKED task; got: %v", err)
+	}
+	if st.CurrentEpic.CompletedAt != nil {
+		t.Errorf("completed_at should be cleared, got %q", *st.CurrentEpic.CompletedAt)
+	}
+	if st.ActiveTask.Type != types.TaskTypeManualReview || st.ActiveTask.ID != "EPIC-5-002" {
+		t.Errorf("ActiveTask = %s/%s, want manual_review/EPIC-5-002", st.ActiveTask.Type, st.ActiveTask.ID)
+	}
+	if got := gitOut(t, dir, "rev-parse", "--abbrev-ref", "HEAD"); got != "feature/EPIC-5" {
+		t.Errorf("current branch = %q, want feature/EPIC-5 (no integration)", got)
+	}
+	if got := gitOut(t, dir, "rev-parse", base); got != baseHead {
+		t.Errorf("base branch moved to %s, want %s", got, baseHead)
+	}
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/robertgumeny/doug/internal/notify"
+	"github.com/robertgumeny/doug/internal/orchestrator"
+)
+
+// publishEvent sends a run event describing the current iteration to
+// ctx.Events. It is a no-op when no sink is configured.
+func publishEvent(ctx *orchestrator.LoopContext, t notify.EventType, reason string) {
+	if ctx.Events == nil {
+		return
+	}
+	e := notify.Event{
+		Type:     t,
+		EpicID:   ctx.State.CurrentEpic.ID,
+		EpicName: ctx.State.CurrentEpic.Name,
+		TaskID:   ctx.TaskID,
+		TaskType: string(ctx.TaskType),
+		Attempt:  ctx.Attempts,
+		Reason:   reason,
+		Metrics: notify.Metrics{
+			TasksCompleted:       ctx.State.Metrics.TotalTasksCompleted,
+			TotalDurationSeconds: ctx.State.Metrics.TotalDurationSeconds,
+		},
+	}
+	if ctx.SessionResult != nil {
+		e.Outcome = string(ctx.SessionResult.Outcome)
+	}
+	if !ctx.TaskStartTime.IsZero() {
+		e.Metrics.TaskDurationSeconds = int(time.Since(ctx.TaskStartTime).Seconds())
+	}
+	ctx.Events.Publish(e)
+}
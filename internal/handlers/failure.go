@@ -7,40 +7,59 @@ import (
 	"path/filepath"
 	"time"
 
-	"github.com/robertgumeny/doug/internal/git"
+	"github.com/robertgumeny/doug/internal/config"
 	"github.com/robertgumeny/doug/internal/log"
 	"github.com/robertgumeny/doug/internal/metrics"
+	"github.com/robertgumeny/doug/internal/notify"
 	"github.com/robertgumeny/doug/internal/orchestrator"
 	"github.com/robertgumeny/doug/internal/state"
 	"github.com/robertgumeny/doug/internal/types"
 )
 
-// HandleFailure processes a FAILURE outcome reported by the agent.
+// HandleFailure processes a FAILURE outcome reported by the agent, or an agent
+// timeout (ctx.AgentTimedOut), which is handled identically.
 //
 // Sequence:
-//  1. Rollback uncommitted changes (rollback error is non-fatal; logged as warning).
+//  1. Snapshot the attempt to refs/doug/attempts/ and roll back uncommitted
+//     changes (rollback error is non-fatal; logged as warning).
 //  2. Record task metrics (non-fatal; in-memory).
 //  3. Check attempt count against config.MaxRetries.
-//     - Below max_retries: log retry warning, return nil (main loop continues).
+//     - Below max_retries: record the failure reason and the agent's
+//       ACTIVE_FAILURE.md for the next attempt's briefing, log retry warning,
+//       fire task_retry, return nil (main loop continues).
 //     - At or above max_retries: archive failure report from logs/ACTIVE_FAILURE.md
-//       (missing file is non-fatal), mark task BLOCKED in tasks.yaml, set
-//       active_task to manual_review in project-state.yaml, persist state, and
-//       return a fatal error that includes the task ID and retry count.
+//       (a stub when the agent wrote none), mark task BLOCKED in tasks.yaml, and
+//       publish and fire task_blocked.
+//       If another user-defined task is ready (depends_on all DONE), it becomes
+//       the active task and nil is returned so the loop continues. Otherwise
+//       set active_task to manual_review in project-state.yaml, persist state,
+//       and return a fatal error that includes the task ID and retry count.
 func HandleFailure(ctx *orchestrator.LoopContext) error {
-	// 1. Rollback changes. Non-fatal — log warning and continue.
-	if err := git.RollbackChanges(ctx.ProjectRoot, protectedPaths); err != nil {
+	log.SetTaskContext(ctx.LogContext())
+
+	// 1. Snapshot and roll back changes. Non-fatal — log warning and continue.
+	reason := ctx.FailureReason
+	if reason == "" {
+		reason = "agent reported FAILURE"
+	}
+	if err := rollbackAttempt(ctx, reason); err != nil {
 		log.Warning(fmt.Sprintf("rollback failed: %v", err))
 	}
 
-	// 2. Record metrics (non-fatal; in-memory only).
+	// 2. Record metrics (non-fatal; in-memory only). Timeouts are recorded
+	// under their own outcome so they can be told apart in reports.
+	outcome := "failure"
+	if ctx.AgentTimedOut {
+		outcome = "timeout"
+	}
 	duration := int(time.Since(ctx.TaskStartTime).Seconds())
-	metrics.RecordTaskMetrics(ctx.State, ctx.TaskID, "failure", duration)
+	metrics.RecordTaskMetrics(ctx.State, ctx.TaskID, outcome, duration)
 
 	// 3a. Below max_retries — schedule a retry.
 	if ctx.Attempts < ctx.Config.MaxRetries {
 		log.Warning(fmt.Sprintf("task %s failed (attempt %d/%d) — will retry",
 			ctx.TaskID, ctx.Attempts, ctx.Config.MaxRetries))
-		return nil
+		return rejectAttempt(ctx, reason, "", consumeFailureReport(ctx.DougDir))
 	}
 
 	// 3b. MAX_RETRIES reached — block the task.
@@ -52,6 +71,10 @@ func HandleFailure(ctx *orchestrator.LoopContext) error {
 		log.Warning(fmt.Sprintf("failure archive skipped: %v", err))
 	}
 
+	// The retry history has served its purpose; the archive keeps the report.
+	orchestrator.ClearAttempts(ctx.State, ctx.TaskID)
+	orchestrator.ClearNotes(ctx.State, ctx.TaskID)
+
 	// Mark task BLOCKED in tasks.yaml (skipped for synthetic tasks).
 	if !ctx.TaskType.IsSynthetic() {
 		if err := orchestrator.UpdateTaskStatus(ctx.Tasks, ctx.TaskID, types.StatusBlocked); err != nil {
@@ -61,6 +84,25 @@ func HandleFailure(ctx *orchestrator.LoopContext) error {
 		}
 	}
 
+	blockReason := fmt.Sprintf("failed %d/%d attempts", ctx.Attempts, ctx.Config.MaxRetries)
+	publishEvent(ctx, notify.TaskBlocked, blockReason)
+	hookErr := FireHook(ctx, config.HookTaskBlocked, blockReason)
+
+	// Keep going with independent work: if another task is ready (its
+	// dependencies are all DONE), make it active instead of stopping the run.
+	// A failed abort-policy task_blocked hook stops the run regardless.
+	if hookErr == nil && !ctx.TaskType.IsSynthetic() {
+		if nextID, _ := orchestrator.FindNextActiveTask(ctx.Tasks); nextID != "" {
+			orchestrator.InitializeTaskPointers(ctx.State, ctx.Tasks, ctx.Config.KBEnabled)
+			if err := state.SaveProjectState(ctx.StatePath, ctx.State); err != nil {
+				return fmt.Errorf("save state after blocking task %s: %w", ctx.TaskID, err)
+			}
+			log.Warning(fmt.Sprintf("task %s blocked — continuing with independent task %s",
+				ctx.TaskID, ctx.State.ActiveTask.ID))
+			return nil
+		}
+	}
+
 	// Set active_task to manual_review and persist state.
 	ctx.State.ActiveTask = types.TaskPointer{
 		Type: types.TaskTypeManualReview,
@@ -70,24 +112,30 @@ func HandleFailure(ctx *orchestrator.LoopContext) error {
 		log.Warning(fmt.Sprintf("could not save state after setting manual review: %v", err))
 	}
 
+	if hookErr != nil {
+		return hookErr
+	}
 	return fmt.Errorf("task %s blocked after %d attempts: requires manual review",
 		ctx.TaskID, ctx.Attempts)
 }
 
 // archiveFailureReport copies .doug/ACTIVE_FAILURE.md to
-// .doug/logs/failures/{epic}/failure-{taskID}.md.
+// .doug/logs/failures/{epic}/failure-{taskID}.md, followed by a pointer to the
+// agent transcript for the final attempt when one was recorded.
+//
+// When .doug/ACTIVE_FAILURE.md does not exist (a timeout, an unparseable
+// result, or an agent that never wrote a report) a short stub is archived
+// instead, so the transcript reference is still kept.
 //
-// Returns a non-fatal error when:
-//   - .doug/ACTIVE_FAILURE.md does not exist
-//   - any I/O error occurs during copy
+// Returns a non-fatal error when any I/O error occurs during copy.
 func archiveFailureReport(ctx *orchestrator.LoopContext) error {
 	src := filepath.Join(ctx.DougDir, "ACTIVE_FAILURE.md")
 	data, err := os.ReadFile(src)
 	if err != nil {
-		if errors.Is(err, os.ErrNotExist) {
-			return fmt.Errorf(".doug/ACTIVE_FAILURE.md not found — skipping archive")
+		if !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("read ACTIVE_FAILURE.md: %w", err)
 		}
-		return fmt.Errorf("read ACTIVE_FAILURE.md: %w", err)
+		data = []byte(fmt.Sprintf("# Failure Report: %s\n\nNo failure report was written by the agent.", ctx.TaskID))
 	}
 
 	epicID := ctx.State.CurrentEpic.ID
@@ -95,9 +143,27 @@ func archiveFailureReport(ctx *orchestrator.LoopContext) error {
 	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
 		return fmt.Errorf("mkdir for failure archive: %w", err)
 	}
+	data = appendTranscriptRef(ctx, data)
 	if err := os.WriteFile(dst, data, 0o644); err != nil {
 		return fmt.Errorf("write failure archive: %w", err)
 	}
 	log.Info(fmt.Sprintf("failure report archived to %s", dst))
 	return nil
 }
+
+// appendTranscriptRef appends a reference to ctx.TranscriptPath to an archived
+// report. The path is written relative to the project root when possible.
+// data is returned unchanged when no transcript exists.
+func appendTranscriptRef(ctx *orchestrator.LoopContext, data []byte) []byte {
+	if ctx.TranscriptPath == "" {
+		return data
+	}
+	if _, err := os.Stat(ctx.TranscriptPath); err != nil {
+		return data
+	}
+	ref := ctx.TranscriptPath
+	if rel, err := filepath.Rel(ctx.ProjectRoot, ref); err == nil {
+		ref = filepath.ToSlash(rel)
+	}
+	return append(data, []byte(fmt.Sprintf("\n\n---\n\nAgent transcript: `%s`\n", ref))...)
+}
@@ -8,7 +8,9 @@ import (
 	"time"
 
 	"github.com/robertgumeny/doug/internal/config"
+	"github.com/robertgumeny/doug/internal/git"
 	"github.com/robertgumeny/doug/internal/handlers"
+	"github.com/robertgumeny/doug/internal/notify"
 	"github.com/robertgumeny/doug/internal/orchestrator"
 	"github.com/robertgumeny/doug/internal/types"
 )
@@ -74,6 +76,239 @@ func TestHandleFailure_BelowMaxRetries_ReturnsNil(t *testing.T) {
 	}
 }
 
+func TestHandleFailure_SnapshotsAttemptBeforeRollback(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	ts := makeInProgressTasks("EPIC-5-001")
+	writeFile(t, filepath.Join(dir, "handler.go"), "package handlers\n")
+
+	ctx := failureCtx(dir, 2, "EPIC-5-001", types.TaskTypeFeature, st, ts)
+	if err := handlers.HandleFailure(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "handler.go")); !os.IsNotExist(err) {
+		t.Errorf("expected handler.go rolled back, stat err: %v", err)
+	}
+	snaps, err := git.ListSnapshots(dir, "EPIC-5", "EPIC-5-001")
+	if err != nil {
+		t.Fatalf("ListSnapshots: %v", err)
+	}
+	if len(snaps) != 1 || snaps[0].Attempt != 2 {
+		t.Fatalf("expected a snapshot of attempt 2, got %+v", snaps)
+	}
+	if !strings.Contains(snaps[0].Subject, "agent reported FAILURE") {
+		t.Errorf("expected the rollback reason in the subject, got %q", snaps[0].Subject)
+	}
+	if err := git.RestoreSnapshot(dir, snaps[0].Ref); err != nil {
+		t.Fatalf("RestoreSnapshot: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "handler.go")); err != nil {
+		t.Errorf("expected handler.go restored from the snapshot: %v", err)
+	}
+}
+
+func TestHandleFailure_RollbackHonorsConfiguredPaths(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	ts := makeInProgressTasks("EPIC-5-001")
+	writeFile(t, filepath.Join(dir, "local.db"), "rows\n")
+	writeFile(t, filepath.Join(dir, ".envrc"), "export A=1\n")
+	writeFile(t, filepath.Join(dir, "handler.go"), "package handlers\n")
+
+	ctx := failureCtx(dir, 2, "EPIC-5-001", types.TaskTypeFeature, st, ts)
+	ctx.Config.Rollback = config.RollbackConfig{
+		Protect:           []string{"*.db"},
+		PreserveUntracked: []string{".envrc"},
+	}
+	if err := handlers.HandleFailure(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, name := range []string{"local.db", ".envrc"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("expected %s to survive the rollback: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "handler.go")); !os.IsNotExist(err) {
+		t.Errorf("expected handler.go rolled back, stat err: %v", err)
+	}
+}
+
+func TestHandleFailure_BelowMaxRetries_RecordsFailureReport(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	ts := makeInProgressTasks("EPIC-5-001")
+	dougDir := filepath.Join(dir, ".doug")
+	writeFile(t, filepath.Join(dougDir, "ACTIVE_FAILURE.md"), "# Failure\n\nMissing fixture file.")
+
+	ctx := failureCtx(dir, 2, "EPIC-5-001", types.TaskTypeFeature, st, ts)
+	if err := handlers.HandleFailure(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := orchestrator.AttemptsFor(st, "EPIC-5-001")
+	if len(got) != 1 {
+		t.Fatalf("expected 1 recorded attempt, got %d", len(got))
+	}
+	if got[0].Attempt != 2 || got[0].Reason != "agent reported FAILURE" {
+		t.Errorf("unexpected record: %+v", got[0])
+	}
+	if !strings.Contains(got[0].FailureReport, "Missing fixture file.") {
+		t.Errorf("expected failure report in record, got %q", got[0].FailureReport)
+	}
+	if _, err := os.Stat(filepath.Join(dougDir, "ACTIVE_FAILURE.md")); !os.IsNotExist(err) {
+		t.Errorf("expected ACTIVE_FAILURE.md to be consumed, stat err: %v", err)
+	}
+}
+
+func TestHandleFailure_BelowMaxRetries_RecordsFailureReason(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	ts := makeInProgressTasks("EPIC-5-001")
+
+	ctx := failureCtx(dir, 1, "EPIC-5-001", types.TaskTypeFeature, st, ts)
+	ctx.AgentTimedOut = true
+	ctx.FailureReason = "agent timed out after 30s"
+	if err := handlers.HandleFailure(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := orchestrator.AttemptsFor(st, "EPIC-5-001")
+	if len(got) != 1 || got[0].Reason != "agent timed out after 30s" {
+		t.Errorf("expected timeout reason recorded, got %+v", got)
+	}
+}
+
+func TestHandleFailure_AfterUnblock_KeepsEarlierSnapshot(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	ts := makeInProgressTasks("EPIC-5-001")
+
+	writeFile(t, filepath.Join(dir, "first.go"), "package first\n")
+	ctx := failureCtx(dir, 1, "EPIC-5-001", types.TaskTypeFeature, st, ts)
+	ctx.Config.MaxRetries = 1
+	_ = handlers.HandleFailure(ctx) // blocks the task
+
+	// doug unblock resets the attempt count, so the next run is attempt 1 again.
+	if err := orchestrator.UnblockTask(st, ts, "EPIC-5-001", false); err != nil {
+		t.Fatalf("UnblockTask: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "second.go"), "package second\n")
+	if err := handlers.HandleFailure(failureCtx(dir, 1, "EPIC-5-001", types.TaskTypeFeature, st, ts)); err != nil {
+		t.Fatalf("HandleFailure: %v", err)
+	}
+
+	snaps, err := git.ListSnapshots(dir, "EPIC-5", "EPIC-5-001")
+	if err != nil {
+		t.Fatalf("ListSnapshots: %v", err)
+	}
+	if len(snaps) != 2 || snaps[0].ID() != "1" || snaps[1].ID() != "1-2" {
+		t.Fatalf("expected snapshots 1 and 1-2, got %+v", snaps)
+	}
+	for i, file := range []string{"first.go", "second.go"} {
+		diff, err := git.DiffSnapshot(dir, snaps[i].Ref)
+		if err != nil {
+			t.Fatalf("DiffSnapshot: %v", err)
+		}
+		if !strings.Contains(diff, file) {
+			t.Errorf("snapshot %s should hold %s:\n%s", snaps[i].ID(), file, diff)
+		}
+	}
+}
+
+func TestHandleFailure_AtMaxRetries_ClearsPreviousAttempts(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	st.PreviousAttempts = []types.AttemptRecord{{TaskID: "EPIC-5-001", Attempt: 4, Reason: "agent reported FAILURE"}}
+	ts := makeInProgressTasks("EPIC-5-001")
+
+	ctx := failureCtx(dir, 5, "EPIC-5-001", types.TaskTypeFeature, st, ts)
+	_ = handlers.HandleFailure(ctx)
+
+	if len(st.PreviousAttempts) != 0 {
+		t.Errorf("expected history cleared when task is blocked, got %+v", st.PreviousAttempts)
+	}
+}
+
+func TestHandleFailure_BelowMaxRetries_TaskRetryHookPolicy(t *testing.T) {
+	for policy, wantErr := range map[string]bool{config.HookWarn: false, config.HookAbort: true} {
+		t.Run(policy, func(t *testing.T) {
+			dir := setupGitRepo(t)
+			st := makeFeatureState()
+			ts := makeInProgressTasks("EPIC-5-001")
+			ctx := failureCtx(dir, 1, "EPIC-5-001", types.TaskTypeFeature, st, ts)
+			ctx.Config.Hooks = map[string][]config.HookConfig{
+				config.HookTaskRetry: {{Command: "git no-such-subcommand", TimeoutSeconds: 10, OnFailure: policy}},
+			}
+
+			err := handlers.HandleFailure(ctx)
+			if (err != nil) != wantErr {
+				t.Fatalf("HandleFailure error = %v, want error: %v", err, wantErr)
+			}
+			if wantErr && !strings.Contains(err.Error(), "task_retry hook") {
+				t.Errorf("expected hook error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestHandleFailure_AtMaxRetries_TaskBlockedHookAbortStopsRun(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	ts := makeTwoTaskTasks(types.StatusInProgress, types.StatusTODO)
+	ctx := failureCtx(dir, 5, "EPIC-5-001", types.TaskTypeFeature, st, ts)
+	ctx.Config.Hooks = map[string][]config.HookConfig{
+		config.HookTaskBlocked: {{Command: "git no-such-subcommand", TimeoutSeconds: 10, OnFailure: config.HookAbort}},
+	}
+
+	err := handlers.HandleFailure(ctx)
+	if err == nil || !strings.Contains(err.Error(), "task_blocked hook") {
+		t.Fatalf("expected task_blocked hook error, got: %v", err)
+	}
+	if st.ActiveTask.Type != types.TaskTypeManualReview {
+		t.Errorf("expected manual_review instead of continuing with EPIC-5-002, got %+v", st.ActiveTask)
+	}
+}
+
+// recordingSink collects published run events.
+type recordingSink struct{ events []notify.Event }
+
+func (r *recordingSink) Publish(e notify.Event) { r.events = append(r.events, e) }
+
+func TestHandleFailure_AtMaxRetries_PublishesTaskBlocked(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	ts := makeInProgressTasks("EPIC-5-001")
+	sink := &recordingSink{}
+	ctx := failureCtx(dir, 5, "EPIC-5-001", types.TaskTypeFeature, st, ts)
+	ctx.Events = sink
+
+	_ = handlers.HandleFailure(ctx)
+
+	if len(sink.events) != 1 {
+		t.Fatalf("expected 1 event, got %+v", sink.events)
+	}
+	e := sink.events[0]
+	if e.Type != notify.TaskBlocked || e.TaskID != "EPIC-5-001" || e.Attempt != 5 || e.EpicID != "EPIC-5" {
+		t.Errorf("unexpected event: %+v", e)
+	}
+}
+
+func TestHandleFailure_BelowMaxRetries_PublishesNothing(t *testing.T) {
+	dir := setupGitRepo(t)
+	sink := &recordingSink{}
+	ctx := failureCtx(dir, 1, "EPIC-5-001", types.TaskTypeFeature, makeFeatureState(), makeInProgressTasks("EPIC-5-001"))
+	ctx.Events = sink
+
+	if err := handlers.HandleFailure(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sink.events) != 0 {
+		t.Errorf("expected no events for a retry, got %+v", sink.events)
+	}
+}
+
 func TestHandleFailure_AtMaxRetries_ReturnsError(t *testing.T) {
 	dir := setupGitRepo(t)
 	st := makeFeatureState()
@@ -110,26 +345,34 @@ func TestHandleFailure_AtMaxRetries_ErrorContainsTaskIDAndCount(t *testing.T) {
 	}
 }
 
-func TestHandleFailure_AtMaxRetries_MissingActiveFail_ArchiveSkippedNonFatal(t *testing.T) {
+func TestHandleFailure_AtMaxRetries_MissingActiveFail_ArchivesStub(t *testing.T) {
 	dir := setupGitRepo(t)
 	st := makeFeatureState()
 	ts := makeInProgressTasks("EPIC-5-001")
 
-	// .doug/ACTIVE_FAILURE.md does not exist
+	// .doug/ACTIVE_FAILURE.md does not exist (e.g. the last attempt timed out)
+	dougDir := filepath.Join(dir, ".doug")
+	transcript := filepath.Join(dougDir, "logs", "sessions", "EPIC-5", "session-EPIC-5-001_attempt-5.log")
+	writeFile(t, transcript, "agent output\n")
 	ctx := failureCtx(dir, 5, "EPIC-5-001", types.TaskTypeFeature, st, ts)
+	ctx.TranscriptPath = transcript
 
-	// Should not panic or return an error solely because the archive file is missing
 	err := handlers.HandleFailure(ctx)
 
 	// Still returns an error (max retries reached), but the cause is the retry limit
-	// not the missing archive file
+	// not the missing report
 	if err == nil {
 		t.Fatal("expected non-nil error at max_retries")
 	}
-	// Archive destination should NOT be created
-	archiveDir := filepath.Join(dir, ".doug", "logs", "failures", "EPIC-5")
-	if _, statErr := os.Stat(archiveDir); statErr == nil {
-		t.Error("archive directory should not be created when ACTIVE_FAILURE.md is missing")
+	data, readErr := os.ReadFile(filepath.Join(dougDir, "logs", "failures", "EPIC-5", "failure-EPIC-5-001.md"))
+	if readErr != nil {
+		t.Fatalf("read failure archive: %v", readErr)
+	}
+	if !strings.Contains(string(data), "No failure report was written") {
+		t.Errorf("failure archive should say no report was written, got:\n%s", data)
+	}
+	if want := ".doug/logs/sessions/EPIC-5/session-EPIC-5-001_attempt-5.log"; !strings.Contains(string(data), want) {
+		t.Errorf("failure archive should reference transcript %q, got:\n%s", want, data)
 	}
 }
 
@@ -161,6 +404,31 @@ func TestHandleFailure_AtMaxRetries_ArchivesReportToCorrectPath(t *testing.T) {
 	}
 }
 
+func TestHandleFailure_AtMaxRetries_ArchiveReferencesTranscript(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	ts := makeInProgressTasks("EPIC-5-003")
+
+	dougDir := filepath.Join(dir, ".doug")
+	writeFile(t, filepath.Join(dougDir, "ACTIVE_FAILURE.md"), "# Failure\n\nDetailed failure report.")
+	transcript := filepath.Join(dougDir, "logs", "sessions", "EPIC-5", "session-EPIC-5-003_attempt-5.log")
+	writeFile(t, transcript, "agent output\n")
+
+	ctx := failureCtx(dir, 5, "EPIC-5-003", types.TaskTypeFeature, st, ts)
+	ctx.TranscriptPath = transcript
+
+	_ = handlers.HandleFailure(ctx)
+
+	data, err := os.ReadFile(filepath.Join(dougDir, "logs", "failures", "EPIC-5", "failure-EPIC-5-003.md"))
+	if err != nil {
+		t.Fatalf("read failure archive: %v", err)
+	}
+	want := ".doug/logs/sessions/EPIC-5/session-EPIC-5-003_attempt-5.log"
+	if !strings.Contains(string(data), want) {
+		t.Errorf("failure archive should reference transcript %q, got:\n%s", want, data)
+	}
+}
+
 func TestHandleFailure_AtMaxRetries_MarksTaskBlocked(t *testing.T) {
 	dir := setupGitRepo(t)
 	st := makeFeatureState()
@@ -225,6 +493,23 @@ func TestHandleFailure_MetricsRecorded(t *testing.T) {
 	}
 }
 
+func TestHandleFailure_AgentTimeout_RecordsTimeoutOutcome(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	ts := makeInProgressTasks("EPIC-5-001")
+
+	ctx := failureCtx(dir, 1, "EPIC-5-001", types.TaskTypeFeature, st, ts)
+	ctx.AgentTimedOut = true
+
+	if err := handlers.HandleFailure(ctx); err != nil {
+		t.Fatalf("expected nil error below max_retries, got: %v", err)
+	}
+	last := st.Metrics.Tasks[len(st.Metrics.Tasks)-1]
+	if last.Outcome != "timeout" {
+		t.Errorf("metric outcome: got %q, want %q", last.Outcome, "timeout")
+	}
+}
+
 func TestHandleFailure_AboveMaxRetries_AlsoBlocks(t *testing.T) {
 	// attempts > max_retries (e.g., 7 with MaxRetries=5) should also block.
 	dir := setupGitRepo(t)
@@ -275,3 +560,29 @@ func TestHandleFailure_SyntheticTask_DoesNotMarkBlocked(t *testing.T) {
 		}
 	}
 }
+
+func TestHandleFailure_AtMaxRetries_ContinuesWithIndependentTask(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	ts := &types.Tasks{
+		Epic: types.EpicDefinition{
+			ID: "EPIC-5",
+			Tasks: []types.Task{
+				{ID: "EPIC-5-001", Type: types.TaskTypeFeature, Status: types.StatusInProgress, UserDefined: true},
+				{ID: "EPIC-5-002", Type: types.TaskTypeFeature, Status: types.StatusTODO, DependsOn: []string{"EPIC-5-001"}, UserDefined: true},
+				{ID: "EPIC-5-003", Type: types.TaskTypeFeature, Status: types.StatusTODO, UserDefined: true},
+			},
+		},
+	}
+	ctx := failureCtx(dir, 5, "EPIC-5-001", types.TaskTypeFeature, st, ts)
+
+	if err := handlers.HandleFailure(ctx); err != nil {
+		t.Fatalf("expected nil error while independent work remains, got: %v", err)
+	}
+	if ts.Epic.Tasks[0].Status != types.StatusBlocked {
+		t.Errorf("failed task status: got %q, want BLOCKED", ts.Epic.Tasks[0].Status)
+	}
+	if st.ActiveTask.ID != "EPIC-5-003" || st.ActiveTask.Attempts != 0 {
+		t.Errorf("ActiveTask: got %+v, want EPIC-5-003 with 0 attempts", st.ActiveTask)
+	}
+}
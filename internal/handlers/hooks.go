@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"github.com/robertgumeny/doug/internal/hooks"
+	"github.com/robertgumeny/doug/internal/orchestrator"
+)
+
+// FireHook runs the hooks configured for event with a payload describing the
+// current iteration. reason is free text explaining the event (e.g. why an
+// attempt was rejected) and may be empty. A non-nil error means an
+// abort-policy hook failed and the run should stop.
+func FireHook(ctx *orchestrator.LoopContext, event, reason string) error {
+	if ctx.Config == nil || len(ctx.Config.Hooks[event]) == 0 {
+		return nil
+	}
+	p := hooks.Payload{
+		Event:          event,
+		ProjectRoot:    ctx.ProjectRoot,
+		EpicID:         ctx.CurrentEpic.ID,
+		EpicName:       ctx.CurrentEpic.Name,
+		TaskID:         ctx.TaskID,
+		TaskType:       string(ctx.TaskType),
+		Attempt:        ctx.Attempts,
+		MaxRetries:     ctx.Config.MaxRetries,
+		Reason:         reason,
+		TranscriptPath: ctx.TranscriptPath,
+	}
+	if ctx.SessionResult != nil {
+		p.Outcome = string(ctx.SessionResult.Outcome)
+	}
+	return hooks.Run(ctx.ProjectRoot, ctx.Config.Hooks, p)
+}
@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/robertgumeny/doug/internal/log"
+	"github.com/robertgumeny/doug/internal/orchestrator"
+	"github.com/robertgumeny/doug/internal/state"
+)
+
+// HandleInterrupt cleans up after the agent, or the verification of its
+// SUCCESS (see Interrupted), was stopped by SIGINT or SIGTERM.
+//
+// Sequence:
+//  1. Snapshot and roll back uncommitted changes unless keepChanges is set,
+//     in which case the working tree is left exactly as the agent left it for
+//     inspection.
+//  2. Decrement active_task.attempts so the interrupted attempt does not count
+//     against max_retries; the next doug run repeats the same attempt number.
+//  3. Persist project-state.yaml.
+//
+// No metrics are recorded: an interrupted attempt has no outcome. Returns a
+// non-nil error only when rollback or the state save fails.
+func HandleInterrupt(ctx *orchestrator.LoopContext, keepChanges bool) error {
+	log.SetTaskContext(ctx.LogContext())
+
+	// 1. Rollback (or keep) the agent's partial work.
+	if keepChanges {
+		log.Warning("--keep-changes set — leaving the working tree untouched")
+	} else if err := rollbackAttempt(ctx, "interrupted"); err != nil {
+		return fmt.Errorf("rollback after interrupt: %w", err)
+	}
+
+	// 2. Give the attempt back.
+	if ctx.State.ActiveTask.ID == ctx.TaskID && ctx.State.ActiveTask.Attempts > 0 {
+		ctx.State.ActiveTask.Attempts--
+	}
+
+	// 3. Persist state so the next run resumes on the same attempt.
+	if err := state.SaveProjectState(ctx.StatePath, ctx.State); err != nil {
+		return fmt.Errorf("save state after interrupt: %w", err)
+	}
+
+	log.Warning(fmt.Sprintf("task %s attempt %d interrupted — not counted against max_retries",
+		ctx.TaskID, ctx.Attempts))
+	return nil
+}
@@ -0,0 +1,103 @@
+package handlers_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robertgumeny/doug/internal/git"
+	"github.com/robertgumeny/doug/internal/handlers"
+	"github.com/robertgumeny/doug/internal/state"
+	"github.com/robertgumeny/doug/internal/types"
+)
+
+func TestHandleInterrupt_RollsBackAndReturnsAttempt(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	st.ActiveTask.Attempts = 2
+	ts := makeInProgressTasks("EPIC-5-001")
+	ctx := failureCtx(dir, 2, "EPIC-5-001", types.TaskTypeFeature, st, ts)
+
+	writeFile(t, filepath.Join(dir, "CHANGELOG.md"), "half-written by agent\n")
+	writeFile(t, filepath.Join(dir, "scratch.go"), "package scratch\n")
+
+	if err := handlers.HandleInterrupt(ctx, false); err != nil {
+		t.Fatalf("HandleInterrupt: %v", err)
+	}
+
+	if got := st.ActiveTask.Attempts; got != 1 {
+		t.Errorf("attempts: got %d, want 1", got)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "CHANGELOG.md")); string(data) == "half-written by agent\n" {
+		t.Error("tracked file should have been rolled back")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "scratch.go")); !os.IsNotExist(err) {
+		t.Error("untracked file should have been removed by rollback")
+	}
+
+	saved, err := state.LoadProjectState(ctx.StatePath)
+	if err != nil {
+		t.Fatalf("load saved state: %v", err)
+	}
+	if saved.ActiveTask.Attempts != 1 {
+		t.Errorf("persisted attempts: got %d, want 1", saved.ActiveTask.Attempts)
+	}
+	if len(st.Metrics.Tasks) != 0 {
+		t.Errorf("interrupted attempt should not record metrics, got %d", len(st.Metrics.Tasks))
+	}
+}
+
+func TestHandleInterrupt_KeepChangesLeavesTreeUntouched(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	ts := makeInProgressTasks("EPIC-5-001")
+	ctx := failureCtx(dir, 1, "EPIC-5-001", types.TaskTypeFeature, st, ts)
+
+	writeFile(t, filepath.Join(dir, "scratch.go"), "package scratch\n")
+
+	if err := handlers.HandleInterrupt(ctx, true); err != nil {
+		t.Fatalf("HandleInterrupt: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "scratch.go")); err != nil {
+		t.Errorf("--keep-changes should preserve agent output: %v", err)
+	}
+	if got := st.ActiveTask.Attempts; got != 0 {
+		t.Errorf("attempts: got %d, want 0", got)
+	}
+}
+
+func TestHandleInterrupt_RetriedAttemptKeepsEarlierSnapshot(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	st.ActiveTask.Attempts = 2
+	ts := makeInProgressTasks("EPIC-5-001")
+
+	// The interrupt gives attempt 2 back, so the retry is attempt 2 again.
+	writeFile(t, filepath.Join(dir, "first.go"), "package first\n")
+	if err := handlers.HandleInterrupt(failureCtx(dir, 2, "EPIC-5-001", types.TaskTypeFeature, st, ts), false); err != nil {
+		t.Fatalf("HandleInterrupt: %v", err)
+	}
+	writeFile(t, filepath.Join(dir, "second.go"), "package second\n")
+	if err := handlers.HandleFailure(failureCtx(dir, 2, "EPIC-5-001", types.TaskTypeFeature, st, ts)); err != nil {
+		t.Fatalf("HandleFailure: %v", err)
+	}
+
+	snaps, err := git.ListSnapshots(dir, "EPIC-5", "EPIC-5-001")
+	if err != nil {
+		t.Fatalf("ListSnapshots: %v", err)
+	}
+	if len(snaps) != 2 || snaps[0].ID() != "2" || snaps[1].ID() != "2-2" {
+		t.Fatalf("expected snapshots 2 and 2-2, got %+v", snaps)
+	}
+	if !strings.Contains(snaps[0].Subject, "interrupted") {
+		t.Errorf("first snapshot was overwritten: %q", snaps[0].Subject)
+	}
+	if err := git.RestoreSnapshot(dir, snaps[0].Ref); err != nil {
+		t.Fatalf("RestoreSnapshot: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "first.go")); err != nil {
+		t.Errorf("expected first.go restored from the interrupted attempt: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/robertgumeny/doug/internal/git"
+	"github.com/robertgumeny/doug/internal/log"
+	"github.com/robertgumeny/doug/internal/orchestrator"
+	"github.com/robertgumeny/doug/internal/types"
+)
+
+// reconcileFilesChanged returns the files git sees changed outside .doug/ and
+// warns about any disagreement with the files_changed the agent reported:
+// files it claimed but did not touch, and files it touched but did not list.
+// Nothing is compared when the agent reported no files. A git error is logged
+// and yields nil; the self-report is advisory and never blocks a task.
+func reconcileFilesChanged(ctx *orchestrator.LoopContext) []string {
+	actual, err := git.ChangedPaths(ctx.ProjectRoot)
+	if err != nil {
+		log.Warning(fmt.Sprintf("could not list changed files: %v", err))
+		return nil
+	}
+	if ctx.SessionResult == nil || len(ctx.SessionResult.FilesChanged) == 0 {
+		return actual
+	}
+
+	var reported []string
+	for _, f := range ctx.SessionResult.FilesChanged {
+		if f = cleanReportedPath(f); f != "" {
+			reported = append(reported, f)
+		}
+	}
+	var claimed, unlisted []string
+	for _, f := range reported {
+		if !slices.Contains(actual, f) {
+			claimed = append(claimed, f)
+		}
+	}
+	for _, f := range actual {
+		if !slices.Contains(reported, f) {
+			unlisted = append(unlisted, f)
+		}
+	}
+	if len(claimed) > 0 {
+		log.Warning(fmt.Sprintf("files_changed lists files git shows unchanged: %s", strings.Join(claimed, ", ")))
+	}
+	if len(unlisted) > 0 {
+		log.Warning(fmt.Sprintf("files changed but missing from files_changed: %s", strings.Join(unlisted, ", ")))
+	}
+	return actual
+}
+
+// cleanReportedPath normalises a path from files_changed to the form git
+// reports: slash-separated, relative, without a leading "./".
+func cleanReportedPath(p string) string {
+	p = strings.TrimSpace(strings.ReplaceAll(p, "\\", "/"))
+	if p == "" {
+		return ""
+	}
+	return strings.TrimPrefix(path.Clean(p), "./")
+}
+
+// unmetCriteria returns the acceptance criteria the agent reported as not met.
+func unmetCriteria(result *types.SessionResult) []types.CriterionResult {
+	if result == nil {
+		return nil
+	}
+	var unmet []types.CriterionResult
+	for _, c := range result.AcceptanceCriteria {
+		if !c.Met {
+			unmet = append(unmet, c)
+		}
+	}
+	return unmet
+}
+
+// describeCriteria renders criteria as a Markdown list, with the agent's
+// evidence where it gave any.
+func describeCriteria(criteria []types.CriterionResult) string {
+	var sb strings.Builder
+	for _, c := range criteria {
+		sb.WriteString("- " + strings.TrimSpace(c.Criterion))
+		if e := strings.TrimSpace(c.Evidence); e != "" {
+			sb.WriteString(" — " + e)
+		}
+		sb.WriteString("\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -9,9 +9,11 @@ import (
 	"time"
 
 	"github.com/robertgumeny/doug/internal/changelog"
+	"github.com/robertgumeny/doug/internal/config"
 	"github.com/robertgumeny/doug/internal/git"
 	"github.com/robertgumeny/doug/internal/log"
 	"github.com/robertgumeny/doug/internal/metrics"
+	"github.com/robertgumeny/doug/internal/notify"
 	"github.com/robertgumeny/doug/internal/orchestrator"
 	"github.com/robertgumeny/doug/internal/state"
 	"github.com/robertgumeny/doug/internal/types"
@@ -33,6 +35,11 @@ const (
 	// EpicComplete means the KB synthesis documentation task completed
 	// successfully. The caller should invoke HandleEpicComplete next.
 	EpicComplete
+
+	// Interrupted means a signal arrived while a verification step ran. The
+	// step's failure says nothing about the agent's work, so nothing was
+	// rolled back or recorded; the caller should invoke HandleInterrupt.
+	Interrupted
 )
 
 // SuccessResult is returned by HandleSuccess to direct the main loop.
@@ -50,58 +57,121 @@ var protectedPaths = []string{
 // HandleSuccess processes a SUCCESS outcome reported by the agent.
 //
 // Sequence:
-//  1. Install new dependencies if the session result lists any.
-//  2. Verify build — on failure: rollback, return Retry.
-//  3. Verify tests  — on failure: rollback, return Retry.
-//  4. Record task metrics in state (non-fatal, in-memory).
-//  5. Update CHANGELOG.md (non-fatal; logs warning on error).
-//  6. Mark user-defined task DONE in tasks.yaml.
-//  7. For documentation tasks: set current_epic.completed_at, save state,
+//  1. Reconcile the agent's files_changed with git (warn on mismatch); if it
+//     reports an unmet acceptance criterion: rollback, record a "rejected"
+//     metric and the attempt, fire task_retry, return Retry.
+//  2. Install new dependencies if the session result lists any.
+//  3. Verify build — on failure: rollback, record a "rejected" metric and the
+//     attempt, fire task_retry, return Retry.
+//  4. Verify tests  — on failure: rollback, record a "rejected" metric and the
+//     attempt, fire task_retry, return Retry.
+//  5. Verify lint (config.Lint) — enforce: rollback, return Retry on failure;
+//     warn: log the failure and continue; off: skip.
+//  6. Record task metrics in state (non-fatal, in-memory).
+//  7. Update CHANGELOG.md (non-fatal; logs warning on error).
+//  8. Mark user-defined task DONE in tasks.yaml.
+//  9. For documentation tasks: set current_epic.completed_at, save state,
 //     commit, return EpicComplete.
-//  8. For feature/bugfix tasks: inject KB_UPDATE or advance task pointers.
-//  9. Persist state.
-// 10. Commit — on failure: log warning, return Retry (non-fatal).
-// 11. Return Continue.
+// 10. For feature/bugfix tasks: inject KB_UPDATE or advance task pointers.
+//     When no task is left to advance to but some are BLOCKED, set
+//     active_task to manual_review instead.
+// 11. Clear the task's previous-attempt history and notes, persist state.
+// 12. Commit — on failure: log warning, fire task_retry, return Retry
+//     (non-fatal). On success publish and fire task_done.
+// 13. If manual review was set in step 10, return a fatal error naming the
+//     BLOCKED tasks (and the TODO tasks waiting on them) so the run stops.
+// 14. Return Continue.
+//
+// A verification step (2-5) that fails because doug was interrupted returns
+// Interrupted instead: Ctrl-C reaches the build and test processes too, since
+// they share doug's process group. Every rollback first snapshots the attempt
+// (see rollbackAttempt).
 func HandleSuccess(ctx *orchestrator.LoopContext) (SuccessResult, error) {
-	// 1. Install new dependencies if any were added by the agent.
+	log.SetTaskContext(ctx.LogContext())
+
+	// 1. Check the agent's self-report before spending time on verification.
+	changed := reconcileFilesChanged(ctx)
+	if unmet := unmetCriteria(ctx.SessionResult); len(unmet) > 0 {
+		list := describeCriteria(unmet)
+		log.Error(fmt.Sprintf("agent reported SUCCESS with %d unmet acceptance criteria:\n%s", len(unmet), list))
+		if rbErr := rollbackAttempt(ctx, "acceptance criteria not met"); rbErr != nil {
+			return SuccessResult{Kind: Retry}, fmt.Errorf("rollback after unmet acceptance criteria: %w", rbErr)
+		}
+		duration := int(time.Since(ctx.TaskStartTime).Seconds())
+		metrics.RecordTaskMetrics(ctx.State, ctx.TaskID, "rejected", duration)
+		metrics.RecordSessionReport(ctx.State, ctx.TaskID, ctx.SessionResult, changed)
+		return SuccessResult{Kind: Retry}, rejectAttempt(ctx, "acceptance criteria not met", list, "")
+	}
+
+	// 2. Install new dependencies if any were added by the agent.
 	if len(ctx.SessionResult.DependenciesAdded) > 0 {
 		log.Info(fmt.Sprintf("installing new dependencies: %v", ctx.SessionResult.DependenciesAdded))
 		if err := ctx.BuildSystem.Install(); err != nil {
+			if verificationInterrupted(ctx) {
+				return SuccessResult{Kind: Interrupted}, nil
+			}
 			log.Error(fmt.Sprintf("dependency install failed: %v", err))
-			if rbErr := git.RollbackChanges(ctx.ProjectRoot, protectedPaths); rbErr != nil {
+			if rbErr := rollbackAttempt(ctx, "dependency install failed"); rbErr != nil {
 				return SuccessResult{Kind: Retry}, fmt.Errorf("rollback after dependency install failure: %w", rbErr)
 			}
-			return SuccessResult{Kind: Retry}, nil
+			return SuccessResult{Kind: Retry}, rejectVerification(ctx, "dependency install failed", err)
 		}
 	}
 
-	// 2. Verify build.
+	// 3. Verify build.
 	log.Info("verifying build")
 	if err := ctx.BuildSystem.Build(); err != nil {
+		if verificationInterrupted(ctx) {
+			return SuccessResult{Kind: Interrupted}, nil
+		}
 		log.Error(fmt.Sprintf("build verification failed:\n%v", err))
-		if rbErr := git.RollbackChanges(ctx.ProjectRoot, protectedPaths); rbErr != nil {
+		if rbErr := rollbackAttempt(ctx, "build verification failed"); rbErr != nil {
 			return SuccessResult{Kind: Retry}, fmt.Errorf("rollback after build failure: %w", rbErr)
 		}
-		return SuccessResult{Kind: Retry}, nil
+		return SuccessResult{Kind: Retry}, rejectVerification(ctx, "build verification failed", err)
 	}
 	log.Success("build passed")
 
-	// 3. Verify tests.
+	// 4. Verify tests.
 	log.Info("verifying tests")
 	if err := ctx.BuildSystem.Test(); err != nil {
+		if verificationInterrupted(ctx) {
+			return SuccessResult{Kind: Interrupted}, nil
+		}
 		log.Error(fmt.Sprintf("test verification failed:\n%v", err))
-		if rbErr := git.RollbackChanges(ctx.ProjectRoot, protectedPaths); rbErr != nil {
+		if rbErr := rollbackAttempt(ctx, "test verification failed"); rbErr != nil {
 			return SuccessResult{Kind: Retry}, fmt.Errorf("rollback after test failure: %w", rbErr)
 		}
-		return SuccessResult{Kind: Retry}, nil
+		return SuccessResult{Kind: Retry}, rejectVerification(ctx, "test verification failed", err)
 	}
 	log.Success("tests passed")
 
-	// 4. Record task metrics (in-memory; non-fatal if the task ID is odd).
+	// 5. Verify lint according to the configured mode.
+	if config.LintEnabled(ctx.Config.Lint) {
+		log.Info("verifying lint")
+		if err := ctx.BuildSystem.Lint(); err != nil {
+			if verificationInterrupted(ctx) {
+				return SuccessResult{Kind: Interrupted}, nil
+			}
+			if ctx.Config.Lint == config.LintEnforce {
+				log.Error(fmt.Sprintf("lint verification failed:\n%v", err))
+				if rbErr := rollbackAttempt(ctx, "lint verification failed"); rbErr != nil {
+					return SuccessResult{Kind: Retry}, fmt.Errorf("rollback after lint failure: %w", rbErr)
+				}
+				return SuccessResult{Kind: Retry}, rejectVerification(ctx, "lint verification failed", err)
+			}
+			log.Warning(fmt.Sprintf("lint failed (lint: warn — accepting task):\n%v", err))
+		} else {
+			log.Success("lint passed")
+		}
+	}
+
+	// 6. Record task metrics (in-memory; non-fatal if the task ID is odd).
 	duration := int(time.Since(ctx.TaskStartTime).Seconds())
 	metrics.RecordTaskMetrics(ctx.State, ctx.TaskID, "success", duration)
+	metrics.RecordSessionReport(ctx.State, ctx.TaskID, ctx.SessionResult, changed)
 
-	// 5. Update CHANGELOG.md (non-fatal).
+	// 7. Update CHANGELOG.md (non-fatal).
 	if ctx.SessionResult.ChangelogEntry != "" {
 		if err := changelog.UpdateChangelog(
 			ctx.ChangelogPath,
@@ -112,7 +182,7 @@ func HandleSuccess(ctx *orchestrator.LoopContext) (SuccessResult, error) {
 		}
 	}
 
-	// 6. Mark user-defined task as DONE (synthetic tasks are never in tasks.yaml).
+	// 8. Mark user-defined task as DONE (synthetic tasks are never in tasks.yaml).
 	if !ctx.TaskType.IsSynthetic() {
 		if err := orchestrator.UpdateTaskStatus(ctx.Tasks, ctx.TaskID, types.StatusDone); err != nil {
 			log.Warning(fmt.Sprintf("could not mark task %s done: %v", ctx.TaskID, err))
@@ -122,21 +192,29 @@ func HandleSuccess(ctx *orchestrator.LoopContext) (SuccessResult, error) {
 		}
 	}
 
-	// 7. Documentation (KB synthesis) task: set completed_at, commit, return EpicComplete.
+	// 9. Documentation (KB synthesis) task: set completed_at, commit, return EpicComplete.
 	if ctx.TaskType == types.TaskTypeDocumentation {
 		now := time.Now().UTC().Format(time.RFC3339)
 		ctx.State.CurrentEpic.CompletedAt = &now
+		orchestrator.ClearAttempts(ctx.State, ctx.TaskID)
+		orchestrator.ClearNotes(ctx.State, ctx.TaskID)
 		if err := state.SaveProjectState(ctx.StatePath, ctx.State); err != nil {
 			return SuccessResult{Kind: Retry}, fmt.Errorf("save state after docs completion: %w", err)
 		}
-		if err := git.Commit("docs: "+ctx.TaskID, ctx.ProjectRoot); err != nil {
+		if err := git.Commit(taskCommitMessage(ctx, duration), ctx.ProjectRoot); err != nil {
 			log.Warning(fmt.Sprintf("git commit failed for docs task %s: %v", ctx.TaskID, err))
-			return SuccessResult{Kind: Retry}, nil
+			return SuccessResult{Kind: Retry}, FireHook(ctx, config.HookTaskRetry, "git commit failed")
+		}
+		publishEvent(ctx, notify.TaskDone, "")
+		if err := FireHook(ctx, config.HookTaskDone, ""); err != nil {
+			return SuccessResult{}, err
 		}
 		return SuccessResult{Kind: EpicComplete}, nil
 	}
 
-	// 8. Advance task pointers or inject KB synthesis.
+	// 10. Advance task pointers or inject KB synthesis. With nothing left to
+	// advance to, BLOCKED tasks mean the epic is unfinished, not complete.
+	var blocked []string
 	if orchestrator.NeedsKBSynthesis(ctx.State, ctx.Tasks, ctx.Config.KBEnabled) {
 		log.Info("all feature tasks complete — scheduling KB synthesis")
 		ctx.State.ActiveTask = types.TaskPointer{
@@ -144,34 +222,69 @@ func HandleSuccess(ctx *orchestrator.LoopContext) (SuccessResult, error) {
 			ID:   "KB_UPDATE",
 		}
 		ctx.State.NextTask = types.TaskPointer{}
-	} else {
-		orchestrator.AdvanceToNextTask(ctx.State, ctx.Tasks)
+	} else if !orchestrator.AdvanceToNextTask(ctx.State, ctx.Tasks) {
+		if blocked = orchestrator.BlockedTasks(ctx.Tasks); len(blocked) > 0 {
+			ctx.State.ActiveTask = types.TaskPointer{
+				Type: types.TaskTypeManualReview,
+				ID:   blocked[0],
+			}
+			ctx.State.NextTask = types.TaskPointer{}
+		}
 	}
 
-	// 9. Persist updated state.
+	// 11. Persist updated state, dropping the retry history and human notes of
+	// the finished task.
+	orchestrator.ClearAttempts(ctx.State, ctx.TaskID)
+	orchestrator.ClearNotes(ctx.State, ctx.TaskID)
 	if err := state.SaveProjectState(ctx.StatePath, ctx.State); err != nil {
 		return SuccessResult{Kind: Retry}, fmt.Errorf("save state: %w", err)
 	}
 
-	// 10. Commit all changes for this task.
-	commitMsg := taskCommitMessage(ctx.TaskType, ctx.TaskID)
+	// 12. Commit all changes for this task.
+	commitMsg := taskCommitMessage(ctx, duration)
 	if err := git.Commit(commitMsg, ctx.ProjectRoot); err != nil {
 		log.Warning(fmt.Sprintf("git commit failed for task %s: %v", ctx.TaskID, err))
-		return SuccessResult{Kind: Retry}, nil
+		return SuccessResult{Kind: Retry}, FireHook(ctx, config.HookTaskRetry, "git commit failed")
 	}
 
 	log.Success(fmt.Sprintf("task %s committed", ctx.TaskID))
+	publishEvent(ctx, notify.TaskDone, "")
+	if err := FireHook(ctx, config.HookTaskDone, ""); err != nil {
+		return SuccessResult{}, err
+	}
+
+	// 13. Stop when only BLOCKED work is left.
+	if len(blocked) > 0 {
+		return SuccessResult{}, orchestrator.BlockedTasksError(ctx.Tasks)
+	}
+
 	return SuccessResult{Kind: Continue}, nil
 }
 
-// taskCommitMessage returns a conventional commit message for the given task type.
-func taskCommitMessage(taskType types.TaskType, taskID string) string {
-	switch taskType {
-	case types.TaskTypeBugfix:
-		return "fix: " + taskID
-	case types.TaskTypeDocumentation:
-		return "docs: " + taskID
-	default:
-		return "feat: " + taskID
+// signalGrace is how long verificationInterrupted waits for a signal that
+// killed a verification step to be delivered to doug as well.
+const signalGrace = 100 * time.Millisecond
+
+// verificationInterrupted reports whether ctx.Interrupt received a signal,
+// waiting up to signalGrace for one. It is called only after a verification
+// step failed, so the wait never delays a passing task.
+func verificationInterrupted(ctx *orchestrator.LoopContext) bool {
+	if ctx.Interrupt == nil {
+		return false
+	}
+	select {
+	case sig := <-ctx.Interrupt:
+		log.Warning(fmt.Sprintf("received %s during verification — the failed step is not held against the agent", sig))
+		return true
+	case <-time.After(signalGrace):
+		return false
 	}
 }
+
+// rejectVerification records a "rejected" task metric for an attempt whose
+// SUCCESS claim failed verification, then hands off to rejectAttempt.
+func rejectVerification(ctx *orchestrator.LoopContext, reason string, err error) error {
+	duration := int(time.Since(ctx.TaskStartTime).Seconds())
+	metrics.RecordTaskMetrics(ctx.State, ctx.TaskID, "rejected", duration)
+	return rejectAttempt(ctx, reason, err.Error(), "")
+}
@@ -6,11 +6,14 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/robertgumeny/doug/internal/config"
+	"github.com/robertgumeny/doug/internal/git"
 	"github.com/robertgumeny/doug/internal/handlers"
+	"github.com/robertgumeny/doug/internal/notify"
 	"github.com/robertgumeny/doug/internal/orchestrator"
 	"github.com/robertgumeny/doug/internal/types"
 )
@@ -23,12 +26,14 @@ type mockBuildSystem struct {
 	installErr  error
 	buildErr    error
 	testErr     error
+	lintErr     error
 	initialized bool
 }
 
 func (m *mockBuildSystem) Install() error      { return m.installErr }
 func (m *mockBuildSystem) Build() error        { return m.buildErr }
 func (m *mockBuildSystem) Test() error         { return m.testErr }
+func (m *mockBuildSystem) Lint() error         { return m.lintErr }
 func (m *mockBuildSystem) IsInitialized() bool { return m.initialized }
 
 // ---------------------------------------------------------------------------
@@ -190,6 +195,126 @@ func TestHandleSuccess_BuildFails_ReturnsRetry(t *testing.T) {
 	}
 }
 
+func TestHandleSuccess_BuildFails_RecordsPreviousAttempt(t *testing.T) {
+	dir := setupGitRepo(t)
+	bs := &mockBuildSystem{buildErr: fmt.Errorf("exit status 1\nmain.go:3: undefined: Foo")}
+	st := makeFeatureState()
+	ts := makeTwoTaskTasks(types.StatusInProgress, types.StatusTODO)
+	ctx := baseCtx(dir, bs, st, ts)
+
+	if _, err := handlers.HandleSuccess(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := orchestrator.AttemptsFor(st, "EPIC-5-001")
+	if len(got) != 1 {
+		t.Fatalf("expected 1 recorded attempt, got %d", len(got))
+	}
+	if got[0].Attempt != 1 || got[0].Reason != "build verification failed" {
+		t.Errorf("unexpected record: %+v", got[0])
+	}
+	if !strings.Contains(got[0].Output, "undefined: Foo") {
+		t.Errorf("expected build output in record, got %q", got[0].Output)
+	}
+
+	data, err := os.ReadFile(ctx.StatePath)
+	if err != nil {
+		t.Fatalf("read state: %v", err)
+	}
+	if !strings.Contains(string(data), "previous_attempts") {
+		t.Errorf("expected previous_attempts persisted, got:\n%s", data)
+	}
+}
+
+func TestHandleSuccess_BuildInterrupted_ReturnsInterrupted(t *testing.T) {
+	dir := setupGitRepo(t)
+	bs := &mockBuildSystem{buildErr: fmt.Errorf("signal: interrupt")}
+	st := makeFeatureState()
+	ts := makeTwoTaskTasks(types.StatusInProgress, types.StatusTODO)
+	ctx := baseCtx(dir, bs, st, ts)
+	interrupts := make(chan os.Signal, 1)
+	interrupts <- os.Interrupt
+	ctx.Interrupt = interrupts
+	writeFile(t, filepath.Join(dir, "feature.go"), "package main\n")
+
+	result, err := handlers.HandleSuccess(ctx)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Kind != handlers.Interrupted {
+		t.Errorf("expected Interrupted, got %v", result.Kind)
+	}
+	if got := orchestrator.AttemptsFor(st, "EPIC-5-001"); len(got) != 0 {
+		t.Errorf("interrupted verification recorded attempts: %+v", got)
+	}
+	if len(st.Metrics.Tasks) != 0 {
+		t.Errorf("interrupted verification recorded metrics: %+v", st.Metrics.Tasks)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "feature.go")); err != nil {
+		t.Errorf("the agent's work should be left for HandleInterrupt: %v", err)
+	}
+}
+
+func TestHandleSuccess_BuildFailsWithoutSignal_ReturnsRetry(t *testing.T) {
+	dir := setupGitRepo(t)
+	bs := &mockBuildSystem{buildErr: fmt.Errorf("compilation error")}
+	st := makeFeatureState()
+	ts := makeTwoTaskTasks(types.StatusInProgress, types.StatusTODO)
+	ctx := baseCtx(dir, bs, st, ts)
+	ctx.Interrupt = make(chan os.Signal, 1)
+
+	result, err := handlers.HandleSuccess(ctx)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Kind != handlers.Retry {
+		t.Errorf("expected Retry, got %v", result.Kind)
+	}
+}
+
+func TestHandleSuccess_ClearsPreviousAttempts(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	st.PreviousAttempts = []types.AttemptRecord{
+		{TaskID: "EPIC-5-001", Attempt: 1, Reason: "test verification failed"},
+		{TaskID: "EPIC-5-009", Attempt: 1, Reason: "agent reported FAILURE"},
+	}
+	ts := makeTwoTaskTasks(types.StatusInProgress, types.StatusTODO)
+	ctx := baseCtx(dir, &mockBuildSystem{}, st, ts)
+
+	if _, err := handlers.HandleSuccess(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := orchestrator.AttemptsFor(st, "EPIC-5-001"); len(got) != 0 {
+		t.Errorf("expected history for completed task cleared, got %+v", got)
+	}
+	if got := orchestrator.AttemptsFor(st, "EPIC-5-009"); len(got) != 1 {
+		t.Errorf("expected history for other task kept, got %+v", got)
+	}
+}
+
+func TestHandleSuccess_PublishesTaskDone(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	ts := makeTwoTaskTasks(types.StatusInProgress, types.StatusTODO)
+	sink := &recordingSink{}
+	ctx := baseCtx(dir, &mockBuildSystem{}, st, ts)
+	ctx.Events = sink
+
+	if _, err := handlers.HandleSuccess(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sink.events) != 1 || sink.events[0].Type != notify.TaskDone {
+		t.Fatalf("expected one task_done event, got %+v", sink.events)
+	}
+	if e := sink.events[0]; e.Outcome != "SUCCESS" || e.Metrics.TasksCompleted != 1 {
+		t.Errorf("unexpected event: %+v", e)
+	}
+}
+
 func TestHandleSuccess_TestsFail_ReturnsRetry(t *testing.T) {
 	dir := setupGitRepo(t)
 	bs := &mockBuildSystem{testErr: fmt.Errorf("test failure: TestFoo")}
@@ -207,6 +332,63 @@ func TestHandleSuccess_TestsFail_ReturnsRetry(t *testing.T) {
 	}
 }
 
+func TestHandleSuccess_LintFails_Enforce_ReturnsRetry(t *testing.T) {
+	dir := setupGitRepo(t)
+	bs := &mockBuildSystem{lintErr: fmt.Errorf("vet: unreachable code")}
+	st := makeFeatureState()
+	ts := makeTwoTaskTasks(types.StatusInProgress, types.StatusTODO)
+	ctx := baseCtx(dir, bs, st, ts)
+	ctx.Config.Lint = config.LintEnforce
+
+	result, err := handlers.HandleSuccess(ctx)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Kind != handlers.Retry {
+		t.Errorf("expected Retry, got %v", result.Kind)
+	}
+	if ts.Epic.Tasks[0].Status != types.StatusInProgress {
+		t.Errorf("task must not be marked DONE on enforced lint failure, got %s", ts.Epic.Tasks[0].Status)
+	}
+}
+
+func TestHandleSuccess_LintFails_Warn_ReturnsContinue(t *testing.T) {
+	dir := setupGitRepo(t)
+	bs := &mockBuildSystem{lintErr: fmt.Errorf("vet: unreachable code")}
+	st := makeFeatureState()
+	ts := makeTwoTaskTasks(types.StatusInProgress, types.StatusTODO)
+	ctx := baseCtx(dir, bs, st, ts)
+	ctx.Config.Lint = config.LintWarn
+
+	result, err := handlers.HandleSuccess(ctx)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Kind != handlers.Continue {
+		t.Errorf("expected Continue, got %v", result.Kind)
+	}
+}
+
+func TestHandleSuccess_LintOff_DoesNotRunLint(t *testing.T) {
+	dir := setupGitRepo(t)
+	bs := &mockBuildSystem{lintErr: fmt.Errorf("lint must not run")}
+	st := makeFeatureState()
+	ts := makeTwoTaskTasks(types.StatusInProgress, types.StatusTODO)
+	ctx := baseCtx(dir, bs, st, ts)
+	ctx.Config.Lint = config.LintOff
+
+	result, err := handlers.HandleSuccess(ctx)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Kind != handlers.Continue {
+		t.Errorf("expected Continue, got %v", result.Kind)
+	}
+}
+
 func TestHandleSuccess_DepsInstallFails_ReturnsRetry(t *testing.T) {
 	dir := setupGitRepo(t)
 	bs := &mockBuildSystem{installErr: fmt.Errorf("go mod download: network error")}
@@ -269,6 +451,65 @@ func TestHandleSuccess_FeatureTask_MoreTasksRemain_ReturnsContinue(t *testing.T)
 	}
 }
 
+func TestHandleSuccess_CommitTemplate(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	ts := makeTwoTaskTasks(types.StatusInProgress, types.StatusTODO)
+	ts.Epic.Tasks[0].Description = "Add the success handler"
+	ctx := baseCtx(dir, &mockBuildSystem{}, st, ts)
+	ctx.Attempts = 2
+	ctx.Config.CommitTemplate = "{{.Kind}}({{.Epic}}): {{.Description}} [{{.Task}}, attempt {{.Attempt}}]\n\n{{.Changelog}}"
+	ctx.SessionResult = &types.SessionResult{
+		Outcome:        types.OutcomeSuccess,
+		ChangelogEntry: "Add HandleSuccess",
+	}
+
+	if _, err := handlers.HandleSuccess(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "feat(EPIC-5): Add the success handler [EPIC-5-001, attempt 2]\n\nAdd HandleSuccess\n\nDoug-Task-ID:"
+	if got := gitOut(t, dir, "log", "-1", "--format=%B"); !strings.HasPrefix(got, want) {
+		t.Errorf("commit message = %q, want prefix %q", got, want)
+	}
+}
+
+func TestHandleSuccess_CommitTrailers(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	ts := makeTwoTaskTasks(types.StatusInProgress, types.StatusTODO)
+	ctx := baseCtx(dir, &mockBuildSystem{}, st, ts)
+	ctx.Attempts = 3
+	ctx.Config.AgentCommand = `/usr/local/bin/claude -p "complete .doug/ACTIVE_TASK.md"`
+	ctx.SessionPath = filepath.Join(dir, ".doug", "logs", "sessions", "EPIC-5", "session-EPIC-5-001_attempt-3.md")
+	ctx.TaskStartTime = time.Now().Add(-95 * time.Second)
+
+	if _, err := handlers.HandleSuccess(ctx); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	commits, err := git.ReadTrailers(dir, "HEAD")
+	if err != nil {
+		t.Fatalf("ReadTrailers: %v", err)
+	}
+	if len(commits) != 1 {
+		t.Fatalf("expected 1 commit with trailers, got %d", len(commits))
+	}
+	got := commits[0].Trailers
+	want := git.Trailers{
+		TaskID:   "EPIC-5-001",
+		Epic:     "EPIC-5",
+		Attempt:  3,
+		Agent:    "claude",
+		Session:  ".doug/logs/sessions/EPIC-5/session-EPIC-5-001_attempt-3.md",
+		Duration: got.Duration,
+	}
+	if got != want {
+		t.Errorf("trailers = %+v, want %+v", got, want)
+	}
+	if got.Duration < 95*time.Second {
+		t.Errorf("Duration = %v, want at least 95s", got.Duration)
+	}
+}
+
 func TestHandleSuccess_LastFeatureTask_KBEnabled_InjectsKBUpdate(t *testing.T) {
 	dir := setupGitRepo(t)
 	bs := &mockBuildSystem{}
@@ -371,6 +612,26 @@ func TestHandleSuccess_DocumentationTask_ReturnsEpicComplete(t *testing.T) {
 	}
 }
 
+func TestHandleSuccess_DocumentationTask_TaskDoneHookAbort_ReturnsZeroResult(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeDocsState()
+	ctx := baseCtx(dir, &mockBuildSystem{}, st, makeSingleTaskDone())
+	ctx.TaskID = "KB_UPDATE"
+	ctx.TaskType = types.TaskTypeDocumentation
+	ctx.Config.Hooks = map[string][]config.HookConfig{
+		config.HookTaskDone: {{Command: "git no-such-subcommand", TimeoutSeconds: 10, OnFailure: config.HookAbort}},
+	}
+
+	result, err := handlers.HandleSuccess(ctx)
+
+	if err == nil || !strings.Contains(err.Error(), "task_done hook") {
+		t.Fatalf("expected task_done hook error, got: %v", err)
+	}
+	if result != (handlers.SuccessResult{}) {
+		t.Errorf("expected a zero result alongside the hook error, got %+v", result)
+	}
+}
+
 func TestHandleSuccess_CommitFails_ReturnsRetry(t *testing.T) {
 	dir := setupGitRepo(t)
 	bs := &mockBuildSystem{}
@@ -430,6 +691,76 @@ func TestHandleSuccess_MetricsRecorded(t *testing.T) {
 	}
 }
 
+func TestHandleSuccess_UnmetAcceptanceCriteria_ReturnsRetry(t *testing.T) {
+	dir := setupGitRepo(t)
+	// A failing build proves the criteria check runs first.
+	bs := &mockBuildSystem{buildErr: errors.New("build broken")}
+	st := makeFeatureState()
+	ts := makeTwoTaskTasks(types.StatusInProgress, types.StatusTODO)
+	ctx := baseCtx(dir, bs, st, ts)
+	writeFile(t, filepath.Join(dir, "refund.go"), "package refund\n")
+	ctx.SessionResult.AcceptanceCriteria = []types.CriterionResult{
+		{Criterion: "Partial refunds are supported", Met: true},
+		{Criterion: "Refunds are audited", Evidence: "audit table not migrated yet"},
+	}
+
+	result, err := handlers.HandleSuccess(ctx)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Kind != handlers.Retry {
+		t.Errorf("expected Retry, got %v", result.Kind)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "refund.go")); !os.IsNotExist(err) {
+		t.Errorf("expected refund.go rolled back, stat err: %v", err)
+	}
+	got := orchestrator.AttemptsFor(st, "EPIC-5-001")
+	if len(got) != 1 || got[0].Reason != "acceptance criteria not met" ||
+		!strings.Contains(got[0].Output, "Refunds are audited — audit table not migrated yet") {
+		t.Errorf("unexpected attempt record: %+v", got)
+	}
+	if strings.Contains(got[0].Output, "Partial refunds") {
+		t.Errorf("met criteria should not be listed: %q", got[0].Output)
+	}
+	last := st.Metrics.Tasks[len(st.Metrics.Tasks)-1]
+	if last.Outcome != "rejected" || last.CriteriaMet != 1 || last.CriteriaTotal != 2 {
+		t.Errorf("unexpected metric: %+v", last)
+	}
+}
+
+func TestHandleSuccess_RecordsSelfReportInMetrics(t *testing.T) {
+	dir := setupGitRepo(t)
+	bs := &mockBuildSystem{}
+	st := makeFeatureState()
+	ts := makeTwoTaskTasks(types.StatusInProgress, types.StatusTODO)
+	ctx := baseCtx(dir, bs, st, ts)
+	writeFile(t, filepath.Join(dir, "refund.go"), "package refund\n")
+	writeFile(t, filepath.Join(dir, "refund_test.go"), "package refund\n")
+	ctx.SessionResult.FilesChanged = []string{"./refund.go", "missing.go"}
+	ctx.SessionResult.TestsAdded = []string{"TestRefund"}
+	ctx.SessionResult.AcceptanceCriteria = []types.CriterionResult{{Criterion: "Refunds work", Met: true}}
+	ctx.SessionResult.Confidence = types.ConfidenceHigh
+	ctx.SessionResult.NotesForNextTask = "refund_test.go needs more cases"
+
+	result, err := handlers.HandleSuccess(ctx)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Kind != handlers.Continue {
+		t.Fatalf("expected Continue, got %v", result.Kind)
+	}
+	last := st.Metrics.Tasks[len(st.Metrics.Tasks)-1]
+	if strings.Join(last.FilesChanged, ",") != "refund.go,refund_test.go" {
+		t.Errorf("files_changed should come from git, got %v", last.FilesChanged)
+	}
+	if last.CriteriaMet != 1 || last.CriteriaTotal != 1 || last.Confidence != types.ConfidenceHigh ||
+		last.NotesForNextTask != "refund_test.go needs more cases" || len(last.TestsAdded) != 1 {
+		t.Errorf("unexpected metric: %+v", last)
+	}
+}
+
 func TestHandleSuccess_BuildFails_RollbackError_ReturnsRetryWithError(t *testing.T) {
 	// When rollback itself fails, HandleSuccess returns (Retry, non-nil error).
 	// We simulate this by making the ProjectRoot a non-git dir so rollback fails.
@@ -466,3 +797,62 @@ func TestHandleSuccess_BuildFails_RollbackError_ReturnsRetryWithError(t *testing
 		t.Error("expected non-nil error when rollback fails, got nil")
 	}
 }
+
+func TestHandleSuccess_RemainingTasksStalled_ReturnsError(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	ts := &types.Tasks{
+		Epic: types.EpicDefinition{
+			ID: "EPIC-5",
+			Tasks: []types.Task{
+				{ID: "EPIC-5-000", Type: types.TaskTypeFeature, Status: types.StatusBlocked, UserDefined: true},
+				{ID: "EPIC-5-001", Type: types.TaskTypeFeature, Status: types.StatusInProgress, UserDefined: true},
+				{ID: "EPIC-5-002", Type: types.TaskTypeFeature, Status: types.StatusTODO, DependsOn: []string{"EPIC-5-000"}, UserDefined: true},
+			},
+		},
+	}
+	st.NextTask = types.TaskPointer{}
+	ctx := baseCtx(dir, &mockBuildSystem{}, st, ts)
+
+	result, err := handlers.HandleSuccess(ctx)
+
+	if err == nil {
+		t.Fatal("expected error when remaining tasks wait on a BLOCKED dependency")
+	}
+	if !strings.Contains(err.Error(), "EPIC-5-002") {
+		t.Errorf("error should name the stalled task; got: %v", err)
+	}
+	if result != (handlers.SuccessResult{}) {
+		t.Errorf("expected a zero result alongside the fatal error, got %+v", result)
+	}
+	if ts.Epic.Tasks[1].Status != types.StatusDone {
+		t.Errorf("completed task status: got %q, want DONE (the task itself was committed)", ts.Epic.Tasks[1].Status)
+	}
+}
+
+func TestHandleSuccess_LastRunnableTask_OthersBlocked_KBDisabled_SetsManualReview(t *testing.T) {
+	dir := setupGitRepo(t)
+	st := makeFeatureState()
+	ts := makeTwoTaskTasks(types.StatusInProgress, types.StatusBlocked)
+	st.NextTask = types.TaskPointer{}
+	ctx := baseCtx(dir, &mockBuildSystem{}, st, ts)
+	ctx.Config.KBEnabled = false
+
+	result, err := handlers.HandleSuccess(ctx)
+
+	if err == nil {
+		t.Fatal("expected error when the only remaining task is BLOCKED")
+	}
+	if !strings.Contains(err.Error(), "EPIC-5-002") {
+		t.Errorf("error should name the BLOCKED task; got: %v", err)
+	}
+	if result != (handlers.SuccessResult{}) {
+		t.Errorf("expected a zero result alongside the fatal error, got %+v", result)
+	}
+	if st.ActiveTask.Type != types.TaskTypeManualReview || st.ActiveTask.ID != "EPIC-5-002" {
+		t.Errorf("ActiveTask: got %s/%s, want manual_review/EPIC-5-002", st.ActiveTask.Type, st.ActiveTask.ID)
+	}
+	if ts.Epic.Tasks[0].Status != types.StatusDone {
+		t.Errorf("completed task status: got %q, want DONE", ts.Epic.Tasks[0].Status)
+	}
+}
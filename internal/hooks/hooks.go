@@ -0,0 +1,125 @@
+// Package hooks runs the user commands bound to orchestrator lifecycle events
+// in the hooks section of doug.yaml.
+//
+// Each hook is run without a shell in the project root, with the event
+// Payload as JSON on stdin and DOUG_HOOK_EVENT set in its environment. A hook
+// that exits non-zero or outlives its timeout has failed; its on_failure
+// policy decides whether that is logged (warn) or stops the run (abort).
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/robertgumeny/doug/internal/config"
+	"github.com/robertgumeny/doug/internal/log"
+	"github.com/robertgumeny/doug/internal/shellargs"
+)
+
+// outputTailLines is how much hook output is kept in a failure message.
+const outputTailLines = 50
+
+// waitDelay bounds how long a hook's leftover children may hold its output
+// pipes open after the hook itself has exited or been killed.
+const waitDelay = 5 * time.Second
+
+// Payload is the JSON document a hook receives on stdin. Fields that do not
+// apply to an event are omitted.
+type Payload struct {
+	Event          string `json:"event"`
+	Timestamp      string `json:"timestamp"`
+	ProjectRoot    string `json:"project_root"`
+	EpicID         string `json:"epic_id"`
+	EpicName       string `json:"epic_name,omitempty"`
+	TaskID         string `json:"task_id,omitempty"`
+	TaskType       string `json:"task_type,omitempty"`
+	Attempt        int    `json:"attempt,omitempty"`
+	MaxRetries     int    `json:"max_retries,omitempty"`
+	Outcome        string `json:"outcome,omitempty"`
+	Reason         string `json:"reason,omitempty"`
+	TranscriptPath string `json:"transcript_path,omitempty"`
+}
+
+// Run fires every hook configured for p.Event, in order, from dir. Failures of
+// warn-policy hooks are logged; the first failing abort-policy hook stops the
+// sequence and its error is returned. An event with no hooks is a no-op.
+func Run(dir string, hooks map[string][]config.HookConfig, p Payload) error {
+	list := hooks[p.Event]
+	if len(list) == 0 {
+		return nil
+	}
+	if p.Timestamp == "" {
+		p.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	stdin, err := json.Marshal(p)
+	if err != nil {
+		return fmt.Errorf("encode %s hook payload: %w", p.Event, err)
+	}
+
+	for _, h := range list {
+		log.Info(fmt.Sprintf("running %s hook: %s", p.Event, h.Command))
+		if err := runHook(dir, p.Event, h, stdin); err != nil {
+			err = fmt.Errorf("%s hook %q failed: %w", p.Event, h.Command, err)
+			if h.OnFailure == config.HookAbort {
+				return err
+			}
+			log.Warning(err.Error())
+		}
+	}
+	return nil
+}
+
+// runHook runs a single hook command with stdin as its input, killing it once
+// h.TimeoutSeconds elapse (0 means DefaultHookTimeout).
+func runHook(dir, event string, h config.HookConfig, stdin []byte) error {
+	parts, err := shellargs.Split(strings.TrimSpace(h.Command))
+	if err != nil {
+		return err
+	}
+	if len(parts) == 0 {
+		return errors.New("empty command")
+	}
+
+	seconds := h.TimeoutSeconds
+	if seconds <= 0 {
+		seconds = config.DefaultHookTimeout
+	}
+	timeout := time.Duration(seconds) * time.Second
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, parts[0], parts[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Env = append(os.Environ(), "DOUG_HOOK_EVENT="+event)
+	cmd.WaitDelay = waitDelay
+
+	out, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return withOutput(fmt.Errorf("timed out after %s", timeout), out)
+	}
+	if err != nil {
+		return withOutput(err, out)
+	}
+	return nil
+}
+
+// withOutput appends the last outputTailLines lines of out to err.
+func withOutput(err error, out []byte) error {
+	text := strings.TrimRight(string(out), "\n")
+	if text == "" {
+		return err
+	}
+	lines := strings.Split(text, "\n")
+	if len(lines) > outputTailLines {
+		lines = lines[len(lines)-outputTailLines:]
+	}
+	return fmt.Errorf("%w\n%s", err, strings.Join(lines, "\n"))
+}
@@ -0,0 +1,118 @@
+package hooks_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/robertgumeny/doug/internal/config"
+	"github.com/robertgumeny/doug/internal/hooks"
+)
+
+// TestMain lets the test binary double as a hook command. HOOK_TEST_MODE
+// selects the behavior:
+//   - record: copy stdin and DOUG_HOOK_EVENT to the file named by HOOK_TEST_OUT
+//   - fail:   print a message and exit 3
+//   - sleep:  sleep for 10 seconds
+func TestMain(m *testing.M) {
+	switch os.Getenv("HOOK_TEST_MODE") {
+	case "record":
+		data, _ := io.ReadAll(os.Stdin)
+		out := os.Getenv("DOUG_HOOK_EVENT") + "\n" + string(data)
+		if err := os.WriteFile(os.Getenv("HOOK_TEST_OUT"), []byte(out), 0o644); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	case "fail":
+		fmt.Println("fixture refresh failed")
+		os.Exit(3)
+	case "sleep":
+		time.Sleep(10 * time.Second)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+func hookCfg(event, onFailure string, timeout int) map[string][]config.HookConfig {
+	return map[string][]config.HookConfig{
+		event: {{Command: os.Args[0], TimeoutSeconds: timeout, OnFailure: onFailure}},
+	}
+}
+
+func TestRun_PassesPayloadOnStdin(t *testing.T) {
+	outPath := filepath.Join(t.TempDir(), "payload")
+	t.Setenv("HOOK_TEST_MODE", "record")
+	t.Setenv("HOOK_TEST_OUT", outPath)
+
+	err := hooks.Run(t.TempDir(), hookCfg(config.HookTaskDone, config.HookWarn, 10), hooks.Payload{
+		Event:    config.HookTaskDone,
+		EpicID:   "EPIC-1",
+		TaskID:   "EPIC-1-002",
+		TaskType: "feature",
+		Attempt:  2,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("hook did not run: %v", err)
+	}
+	event, body, _ := strings.Cut(string(data), "\n")
+	if event != config.HookTaskDone {
+		t.Errorf("DOUG_HOOK_EVENT = %q, want %q", event, config.HookTaskDone)
+	}
+	var p hooks.Payload
+	if err := json.Unmarshal([]byte(body), &p); err != nil {
+		t.Fatalf("stdin is not a JSON payload: %v: %q", err, body)
+	}
+	if p.TaskID != "EPIC-1-002" || p.Attempt != 2 || p.EpicID != "EPIC-1" {
+		t.Errorf("unexpected payload: %+v", p)
+	}
+	if p.Timestamp == "" {
+		t.Error("expected timestamp to be filled in")
+	}
+}
+
+func TestRun_NoHooksForEvent(t *testing.T) {
+	if err := hooks.Run(t.TempDir(), hookCfg(config.HookTaskDone, config.HookAbort, 10), hooks.Payload{Event: config.HookPreTask}); err != nil {
+		t.Errorf("expected nil for an event without hooks, got: %v", err)
+	}
+}
+
+func TestRun_FailurePolicy(t *testing.T) {
+	t.Setenv("HOOK_TEST_MODE", "fail")
+
+	if err := hooks.Run(t.TempDir(), hookCfg(config.HookTaskRetry, config.HookWarn, 10), hooks.Payload{Event: config.HookTaskRetry}); err != nil {
+		t.Errorf("warn policy should not return an error, got: %v", err)
+	}
+
+	err := hooks.Run(t.TempDir(), hookCfg(config.HookTaskRetry, config.HookAbort, 10), hooks.Payload{Event: config.HookTaskRetry})
+	if err == nil {
+		t.Fatal("abort policy should return the hook error")
+	}
+	for _, want := range []string{"task_retry hook", "exit status 3", "fixture refresh failed"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error should contain %q, got: %v", want, err)
+		}
+	}
+}
+
+func TestRun_Timeout(t *testing.T) {
+	t.Setenv("HOOK_TEST_MODE", "sleep")
+
+	start := time.Now()
+	err := hooks.Run(t.TempDir(), hookCfg(config.HookPreTask, config.HookAbort, 1), hooks.Payload{Event: config.HookPreTask})
+	if err == nil || !strings.Contains(err.Error(), "timed out after 1s") {
+		t.Fatalf("expected timeout error, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 8*time.Second {
+		t.Errorf("hook was not killed at its timeout (took %s)", elapsed)
+	}
+}
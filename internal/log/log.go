@@ -1,10 +1,19 @@
-// Package log provides colored terminal output for the doug orchestrator.
-// All output uses ANSI escape codes; no external dependencies are required.
+// Package log provides terminal output for the doug orchestrator.
+//
+// Two formats are supported: text (the default), which prints ANSI-colored
+// [INFO]/[WARNING] lines, and json, which prints one JSON object per event for
+// log aggregators. Colors are dropped when disabled with SetColor. Events carry
+// the task context set with SetTaskContext so every JSON line is attributable.
 package log
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 )
 
 // ANSI escape codes for terminal colors.
@@ -20,28 +29,148 @@ const (
 // sectionLine is the unicode box-draw separator matching the Bash orchestrator.
 const sectionLine = "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"
 
+// Format selects how log events are written to stdout.
+type Format string
+
+const (
+	// FormatText prints human-readable lines, colored when enabled.
+	FormatText Format = "text"
+	// FormatJSON prints one JSON object per line.
+	FormatJSON Format = "json"
+)
+
+// TaskContext identifies the task a log event belongs to. Zero fields are
+// omitted from JSON output.
+type TaskContext struct {
+	EpicID  string
+	TaskID  string
+	Attempt int
+}
+
 // OsExit is the function called by Fatal to terminate the process.
 // It is a package-level variable so tests can replace it without subprocess overhead.
 var OsExit = os.Exit
 
+// mu guards the output settings below and serializes writes, since the agent
+// heartbeat logs from its own goroutine.
+var (
+	mu      sync.Mutex
+	format  = FormatText
+	color   = true
+	taskCtx TaskContext
+)
+
+// jsonEvent is the wire shape of a FormatJSON line.
+type jsonEvent struct {
+	Timestamp string `json:"timestamp"`
+	Level     string `json:"level"`
+	Message   string `json:"message"`
+	Epic      string `json:"epic,omitempty"`
+	Task      string `json:"task,omitempty"`
+	Attempt   int    `json:"attempt,omitempty"`
+	Data      any    `json:"data,omitempty"`
+}
+
+// ParseFormat validates a --log-format / DOUG_LOG_FORMAT value. An empty
+// string selects FormatText.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(s))) {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	}
+	return "", fmt.Errorf("invalid log format %q: must be text or json", s)
+}
+
+// SetFormat selects the output format for all subsequent events.
+func SetFormat(f Format) {
+	mu.Lock()
+	defer mu.Unlock()
+	format = f
+}
+
+// CurrentFormat returns the format selected with SetFormat.
+func CurrentFormat() Format {
+	mu.Lock()
+	defer mu.Unlock()
+	return format
+}
+
+// SetColor enables or disables ANSI colors in FormatText output.
+func SetColor(enabled bool) {
+	mu.Lock()
+	defer mu.Unlock()
+	color = enabled
+}
+
+// ColorSupported reports whether colored output is appropriate: NO_COLOR is
+// unset (see https://no-color.org) and stdout is a terminal.
+func ColorSupported() bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	fi, err := os.Stdout.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// SetTaskContext attaches tc to every subsequent event until it is replaced
+// or cleared.
+func SetTaskContext(tc TaskContext) {
+	mu.Lock()
+	defer mu.Unlock()
+	taskCtx = tc
+}
+
+// ClearTaskContext removes the task context set by SetTaskContext.
+func ClearTaskContext() {
+	SetTaskContext(TaskContext{})
+}
+
 // Info prints a white [INFO] message to stdout.
 func Info(msg string) {
-	fmt.Printf("%s[INFO]%s %s\n", colorWhite, colorReset, msg)
+	emit("info", "[INFO]", colorWhite, msg)
 }
 
 // Success prints a green [SUCCESS] message to stdout.
 func Success(msg string) {
-	fmt.Printf("%s[SUCCESS]%s %s\n", colorGreen, colorReset, msg)
+	emit("success", "[SUCCESS]", colorGreen, msg)
 }
 
 // Warning prints a yellow [WARNING] message to stdout.
 func Warning(msg string) {
-	fmt.Printf("%s[WARNING]%s %s\n", colorYellow, colorReset, msg)
+	emit("warning", "[WARNING]", colorYellow, msg)
 }
 
 // Error prints a red [ERROR] message to stdout.
 func Error(msg string) {
-	fmt.Printf("%s[ERROR]%s %s\n", colorRed, colorReset, msg)
+	emit("error", "[ERROR]", colorRed, msg)
+}
+
+// InfoData prints an info event carrying structured data. In FormatJSON data
+// is the event's "data" member; in FormatText it follows msg as sorted
+// key=value pairs.
+func InfoData(msg string, data map[string]any) {
+	mu.Lock()
+	defer mu.Unlock()
+	if format == FormatJSON {
+		writeEvent(jsonEvent{Level: "info", Message: msg, Data: data})
+		return
+	}
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	sb.WriteString(msg)
+	for _, k := range keys {
+		sb.WriteString(fmt.Sprintf(" %s=%v", k, data[k]))
+	}
+	fmt.Printf("%s %s\n", paint(colorWhite, "[INFO]"), sb.String())
 }
 
 // Fatal prints a red [ERROR] message then exits with status 1.
@@ -52,8 +181,53 @@ func Fatal(msg string) {
 
 // Section prints a cyan unicode box-draw separator with a title,
 // matching the visual style of the Bash orchestrator's log_section.
+// In FormatJSON it is an ordinary info event carrying the title.
 func Section(title string) {
-	fmt.Printf("\n%s%s%s\n", colorCyan, sectionLine, colorReset)
-	fmt.Printf("%s%s%s\n", colorCyan, title, colorReset)
-	fmt.Printf("%s%s%s\n\n", colorCyan, sectionLine, colorReset)
+	mu.Lock()
+	defer mu.Unlock()
+	if format == FormatJSON {
+		writeJSON("info", title)
+		return
+	}
+	fmt.Printf("\n%s\n", paint(colorCyan, sectionLine))
+	fmt.Printf("%s\n", paint(colorCyan, title))
+	fmt.Printf("%s\n\n", paint(colorCyan, sectionLine))
+}
+
+// emit writes one event in the configured format.
+func emit(level, label, colorCode, msg string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if format == FormatJSON {
+		writeJSON(level, msg)
+		return
+	}
+	fmt.Printf("%s %s\n", paint(colorCode, label), msg)
+}
+
+// writeJSON prints a jsonEvent for the current task context. Callers hold mu.
+func writeJSON(level, msg string) {
+	writeEvent(jsonEvent{Level: level, Message: msg})
+}
+
+// writeEvent stamps ev with the time and task context and prints it as one
+// line. Callers hold mu.
+func writeEvent(ev jsonEvent) {
+	ev.Timestamp = time.Now().UTC().Format(time.RFC3339Nano)
+	ev.Epic, ev.Task, ev.Attempt = taskCtx.EpicID, taskCtx.TaskID, taskCtx.Attempt
+	data, err := json.Marshal(ev)
+	if err != nil {
+		// Only Data can fail to marshal; keep the event without it.
+		ev.Data = nil
+		data, _ = json.Marshal(ev)
+	}
+	fmt.Printf("%s\n", data)
+}
+
+// paint wraps s in colorCode when colors are enabled. Callers hold mu.
+func paint(colorCode, s string) string {
+	if !color {
+		return s
+	}
+	return colorCode + s + colorReset
 }
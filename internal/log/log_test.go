@@ -2,10 +2,12 @@ package log_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"io"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/robertgumeny/doug/internal/log"
 )
@@ -90,3 +92,110 @@ func TestSection(t *testing.T) {
 		t.Errorf("Section output missing title: %q", out)
 	}
 }
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]log.Format{"": log.FormatText, "text": log.FormatText, "JSON": log.FormatJSON} {
+		got, err := log.ParseFormat(in)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := log.ParseFormat("xml"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestJSONFormat(t *testing.T) {
+	log.SetFormat(log.FormatJSON)
+	log.SetTaskContext(log.TaskContext{EpicID: "EPIC-1", TaskID: "EPIC-1-002", Attempt: 3})
+	defer func() {
+		log.SetFormat(log.FormatText)
+		log.ClearTaskContext()
+	}()
+
+	out := captureOutput(func() {
+		log.Warning("build failed:\nline two")
+		log.Section("ITERATION 1")
+	})
+
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one JSON object per event, got %d lines: %q", len(lines), out)
+	}
+	var ev map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &ev); err != nil {
+		t.Fatalf("line is not JSON: %v: %q", err, lines[0])
+	}
+	want := map[string]any{
+		"level":   "warning",
+		"message": "build failed:\nline two",
+		"epic":    "EPIC-1",
+		"task":    "EPIC-1-002",
+		"attempt": float64(3),
+	}
+	for k, v := range want {
+		if ev[k] != v {
+			t.Errorf("%s = %v, want %v", k, ev[k], v)
+		}
+	}
+	if _, err := time.Parse(time.RFC3339Nano, ev["timestamp"].(string)); err != nil {
+		t.Errorf("timestamp not RFC 3339: %v", ev["timestamp"])
+	}
+	if strings.Contains(out, "\033[") || strings.Contains(out, "━") {
+		t.Errorf("JSON output contains terminal decoration: %q", out)
+	}
+}
+
+func TestJSONFormat_OmitsEmptyTaskContext(t *testing.T) {
+	log.SetFormat(log.FormatJSON)
+	defer log.SetFormat(log.FormatText)
+
+	out := captureOutput(func() { log.Info("starting") })
+	if strings.Contains(out, `"task"`) || strings.Contains(out, `"attempt"`) {
+		t.Errorf("expected task fields omitted without context, got %q", out)
+	}
+}
+
+func TestInfoData(t *testing.T) {
+	data := map[string]any{"total_tasks": 2, "ratio": 1.5}
+
+	out := captureOutput(func() { log.InfoData("epic summary", data) })
+	if !strings.Contains(out, "[INFO]") || !strings.Contains(out, "epic summary ratio=1.5 total_tasks=2") {
+		t.Errorf("text output = %q, want sorted key=value pairs", out)
+	}
+
+	log.SetFormat(log.FormatJSON)
+	defer log.SetFormat(log.FormatText)
+	out = captureOutput(func() { log.InfoData("epic summary", data) })
+	var ev struct {
+		Level   string         `json:"level"`
+		Message string         `json:"message"`
+		Data    map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(strings.TrimSpace(out)), &ev); err != nil {
+		t.Fatalf("output is not one JSON object: %v: %q", err, out)
+	}
+	if ev.Level != "info" || ev.Message != "epic summary" || ev.Data["total_tasks"] != float64(2) {
+		t.Errorf("event = %+v", ev)
+	}
+	if log.CurrentFormat() != log.FormatJSON {
+		t.Errorf("CurrentFormat = %q, want json", log.CurrentFormat())
+	}
+}
+
+func TestSetColor(t *testing.T) {
+	log.SetColor(false)
+	defer log.SetColor(true)
+
+	out := captureOutput(func() { log.Info("plain") })
+	if out != "[INFO] plain\n" {
+		t.Errorf("expected uncolored output, got %q", out)
+	}
+}
+
+func TestColorSupported_NoColor(t *testing.T) {
+	t.Setenv("NO_COLOR", "1")
+	if log.ColorSupported() {
+		t.Error("expected colors disabled when NO_COLOR is set")
+	}
+}
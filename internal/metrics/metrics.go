@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/robertgumeny/doug/internal/log"
 	"github.com/robertgumeny/doug/internal/types"
 )
 
@@ -25,6 +26,45 @@ func RecordTaskMetrics(state *types.ProjectState, taskID string, outcome string,
 	UpdateMetricTotals(state)
 }
 
+// RecordSessionReport copies the agent's optional self-report in result onto
+// the most recent TaskMetric for taskID, together with filesChanged, the
+// files git saw change. Call it right after RecordTaskMetrics. It does
+// nothing when no metric for taskID has been recorded.
+func RecordSessionReport(state *types.ProjectState, taskID string, result *types.SessionResult, filesChanged []string) {
+	for i := len(state.Metrics.Tasks) - 1; i >= 0; i-- {
+		m := &state.Metrics.Tasks[i]
+		if m.TaskID != taskID {
+			continue
+		}
+		m.FilesChanged = filesChanged
+		if result != nil {
+			m.TestsAdded = result.TestsAdded
+			m.CriteriaTotal = len(result.AcceptanceCriteria)
+			m.CriteriaMet = 0
+			for _, c := range result.AcceptanceCriteria {
+				if c.Met {
+					m.CriteriaMet++
+				}
+			}
+			m.Confidence = result.Confidence
+			m.NotesForNextTask = result.NotesForNextTask
+		}
+		return
+	}
+}
+
+// RecordIntervention appends an Intervention for a manual change to taskID
+// (action names the command, e.g. "unblock") to state.Metrics.Interventions.
+// Interventions are not task attempts and do not affect the totals.
+func RecordIntervention(state *types.ProjectState, taskID, action, note string) {
+	state.Metrics.Interventions = append(state.Metrics.Interventions, types.Intervention{
+		TaskID:     taskID,
+		Action:     action,
+		Note:       note,
+		RecordedAt: time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
 // UpdateMetricTotals recalculates TotalTasksCompleted and TotalDurationSeconds
 // from the full Tasks slice in state.Metrics. It overwrites any previously
 // stored totals, making it safe to call multiple times.
@@ -37,10 +77,75 @@ func UpdateMetricTotals(state *types.ProjectState) {
 	state.Metrics.TotalDurationSeconds = total
 }
 
+// HistoryWindow is how many of the most recent archived epics PrintEpicSummary
+// compares the completed epic against.
+const HistoryWindow = 3
+
+// Comparison relates an epic's average time per task to a baseline of
+// archived epics.
+type Comparison struct {
+	Epics              int     // archived epics in the baseline
+	BaselineAvgSeconds int     // their mean time per task
+	Ratio              float64 // this epic's average divided by the baseline
+}
+
+// CompareWithHistory compares state's average time per task with the pooled
+// average of the last window epics in history (oldest first, as returned by
+// state.LoadEpicHistory). Archived epics without tasks, and any archive of the
+// current epic itself, are skipped. ok is false when either side has no
+// recorded time to compare.
+func CompareWithHistory(state *types.ProjectState, history []types.EpicHistory, window int) (c Comparison, ok bool) {
+	if state.Metrics.TotalTasksCompleted == 0 || state.Metrics.TotalDurationSeconds == 0 {
+		return Comparison{}, false
+	}
+
+	tasks, seconds := 0, 0
+	for i := len(history) - 1; i >= 0 && c.Epics < window; i-- {
+		h := history[i]
+		if h.Epic.ID == state.CurrentEpic.ID || h.Metrics.TotalTasksCompleted == 0 {
+			continue
+		}
+		c.Epics++
+		tasks += h.Metrics.TotalTasksCompleted
+		seconds += h.Metrics.TotalDurationSeconds
+	}
+	if c.Epics == 0 || seconds == 0 {
+		return Comparison{}, false
+	}
+
+	baseline := float64(seconds) / float64(tasks)
+	current := float64(state.Metrics.TotalDurationSeconds) / float64(state.Metrics.TotalTasksCompleted)
+	c.BaselineAvgSeconds = int(baseline)
+	c.Ratio = current / baseline
+	return c, true
+}
+
+// String describes c in a sentence fragment, e.g.
+// "2.0x longer per task than the last 3 epics (45s avg)".
+func (c Comparison) String() string {
+	epics := "epic"
+	if c.Epics != 1 {
+		epics = "epics"
+	}
+	baseline := fmt.Sprintf("(%s avg)", FormatDuration(c.BaselineAvgSeconds))
+	switch {
+	case c.Ratio >= 1.05:
+		return fmt.Sprintf("%.1fx longer per task than the last %d %s %s", c.Ratio, c.Epics, epics, baseline)
+	case c.Ratio <= 0.95:
+		return fmt.Sprintf("%.1fx faster per task than the last %d %s %s", 1/c.Ratio, c.Epics, epics, baseline)
+	default:
+		return fmt.Sprintf("on par with the last %d %s %s", c.Epics, epics, baseline)
+	}
+}
+
 // PrintEpicSummary prints a box-draw table to stdout summarizing the completed
 // epic: total tasks, total wall time (formatted as h/m/s), and average time
-// per task.
-func PrintEpicSummary(state *types.ProjectState) {
+// per task. When history holds archived epics, the average is also compared
+// with the last HistoryWindow of them (see CompareWithHistory).
+//
+// With the json log format the table would break the one-event-per-line
+// output, so the summary is logged as a single "epic summary" event instead.
+func PrintEpicSummary(state *types.ProjectState, history []types.EpicHistory) {
 	total := state.Metrics.TotalTasksCompleted
 	totalSec := state.Metrics.TotalDurationSeconds
 
@@ -48,8 +153,24 @@ func PrintEpicSummary(state *types.ProjectState) {
 	if total > 0 {
 		avgSec = totalSec / total
 	}
+	comparison, compared := CompareWithHistory(state, history, HistoryWindow)
 
-	totalFmt := formatDuration(totalSec)
+	if log.CurrentFormat() == log.FormatJSON {
+		data := map[string]any{
+			"total_tasks":              total,
+			"total_duration_seconds":   totalSec,
+			"average_seconds_per_task": avgSec,
+		}
+		if compared {
+			data["history_epics"] = comparison.Epics
+			data["history_average_seconds_per_task"] = comparison.BaselineAvgSeconds
+			data["history_ratio"] = comparison.Ratio
+		}
+		log.InfoData("epic summary", data)
+		return
+	}
+
+	totalFmt := FormatDuration(totalSec)
 	avgFmt := fmt.Sprintf("%ds per task", avgSec)
 
 	const line = "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━"
@@ -59,12 +180,15 @@ func PrintEpicSummary(state *types.ProjectState) {
 	fmt.Printf("  %-22s %d\n", "Total Tasks:", total)
 	fmt.Printf("  %-22s %s\n", "Total Time:", totalFmt)
 	fmt.Printf("  %-22s %s\n", "Average Time:", avgFmt)
+	if compared {
+		fmt.Printf("  %-22s %s\n", "Vs History:", comparison)
+	}
 	fmt.Printf("%s\n\n", line)
 }
 
-// formatDuration converts a duration in seconds to a human-readable string.
+// FormatDuration converts a duration in seconds to a human-readable string.
 // Examples: "0s", "45s", "3m 15s", "1h 2m 30s".
-func formatDuration(seconds int) string {
+func FormatDuration(seconds int) string {
 	if seconds <= 0 {
 		return "0s"
 	}
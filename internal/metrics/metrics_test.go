@@ -1,9 +1,14 @@
 package metrics_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
 	"strings"
 	"testing"
 
+	"github.com/robertgumeny/doug/internal/log"
 	"github.com/robertgumeny/doug/internal/metrics"
 	"github.com/robertgumeny/doug/internal/types"
 )
@@ -46,6 +51,38 @@ func TestRecordTaskMetrics_AppendsEntry(t *testing.T) {
 	}
 }
 
+func TestRecordSessionReport_AnnotatesLatestMetric(t *testing.T) {
+	state := emptyState()
+	metrics.RecordTaskMetrics(state, "EPIC-1-001", "rejected", 30)
+	metrics.RecordTaskMetrics(state, "EPIC-1-001", "success", 60)
+
+	result := &types.SessionResult{
+		TestsAdded: []string{"TestRefund"},
+		AcceptanceCriteria: []types.CriterionResult{
+			{Criterion: "a", Met: true},
+			{Criterion: "b", Met: true},
+			{Criterion: "c"},
+		},
+		Confidence:       types.ConfidenceMedium,
+		NotesForNextTask: "watch the audit log",
+	}
+	metrics.RecordSessionReport(state, "EPIC-1-001", result, []string{"refund.go"})
+
+	first, last := state.Metrics.Tasks[0], state.Metrics.Tasks[1]
+	if first.CriteriaTotal != 0 || first.FilesChanged != nil {
+		t.Errorf("earlier metric should be untouched: %+v", first)
+	}
+	if last.CriteriaMet != 2 || last.CriteriaTotal != 3 {
+		t.Errorf("criteria: got %d/%d, want 2/3", last.CriteriaMet, last.CriteriaTotal)
+	}
+	if len(last.FilesChanged) != 1 || len(last.TestsAdded) != 1 || last.Confidence != types.ConfidenceMedium || last.NotesForNextTask != "watch the audit log" {
+		t.Errorf("unexpected report fields: %+v", last)
+	}
+
+	// No metric for the task: nothing to annotate, no panic.
+	metrics.RecordSessionReport(state, "EPIC-1-002", result, nil)
+}
+
 func TestRecordTaskMetrics_CallsUpdateMetricTotals(t *testing.T) {
 	state := emptyState()
 
@@ -141,7 +178,7 @@ func TestUpdateMetricTotals_OverwritesPreviousTotals(t *testing.T) {
 func TestPrintEpicSummary_NoTasks(t *testing.T) {
 	state := emptyState()
 	// Should not panic when there are no tasks (zero-division guard).
-	metrics.PrintEpicSummary(state)
+	metrics.PrintEpicSummary(state, nil)
 }
 
 func TestPrintEpicSummary_WithTasks(t *testing.T) {
@@ -157,5 +194,110 @@ func TestPrintEpicSummary_WithTasks(t *testing.T) {
 		},
 	}
 	// Should not panic with non-zero totals.
-	metrics.PrintEpicSummary(state)
+	metrics.PrintEpicSummary(state, nil)
+}
+
+func TestPrintEpicSummary_WithHistory(t *testing.T) {
+	state := &types.ProjectState{
+		CurrentEpic: types.EpicState{ID: "EPIC-2"},
+		Metrics:     types.Metrics{TotalTasksCompleted: 2, TotalDurationSeconds: 120},
+	}
+	history := []types.EpicHistory{{
+		Epic:    types.EpicState{ID: "EPIC-1"},
+		Metrics: types.Metrics{TotalTasksCompleted: 2, TotalDurationSeconds: 60},
+	}}
+	// Should not panic when printing the comparison line.
+	metrics.PrintEpicSummary(state, history)
+}
+
+func TestPrintEpicSummary_JSONLogFormat(t *testing.T) {
+	state := &types.ProjectState{
+		CurrentEpic: types.EpicState{ID: "EPIC-2"},
+		Metrics:     types.Metrics{TotalTasksCompleted: 2, TotalDurationSeconds: 120},
+	}
+	history := []types.EpicHistory{historyEpic("EPIC-1", 2, 60)}
+
+	log.SetFormat(log.FormatJSON)
+	defer log.SetFormat(log.FormatText)
+	r, w, _ := os.Pipe()
+	stdout := os.Stdout
+	os.Stdout = w
+	metrics.PrintEpicSummary(state, history)
+	w.Close()
+	os.Stdout = stdout
+	out, _ := io.ReadAll(r)
+
+	var ev struct {
+		Message string         `json:"message"`
+		Data    map[string]any `json:"data"`
+	}
+	if err := json.Unmarshal(bytes.TrimSpace(out), &ev); err != nil {
+		t.Fatalf("summary is not a single JSON event: %v\n%s", err, out)
+	}
+	if ev.Message != "epic summary" || ev.Data["total_tasks"] != float64(2) ||
+		ev.Data["average_seconds_per_task"] != float64(60) || ev.Data["history_ratio"] != float64(2) {
+		t.Errorf("event = %+v", ev)
+	}
+}
+
+// ---------------------------------------------------------------------------
+// CompareWithHistory
+// ---------------------------------------------------------------------------
+
+func historyEpic(id string, tasks, seconds int) types.EpicHistory {
+	return types.EpicHistory{
+		Epic:    types.EpicState{ID: id},
+		Metrics: types.Metrics{TotalTasksCompleted: tasks, TotalDurationSeconds: seconds},
+	}
+}
+
+func TestCompareWithHistory_UsesLastWindowEpics(t *testing.T) {
+	state := &types.ProjectState{
+		CurrentEpic: types.EpicState{ID: "EPIC-5"},
+		Metrics:     types.Metrics{TotalTasksCompleted: 4, TotalDurationSeconds: 400}, // 100s per task
+	}
+	history := []types.EpicHistory{
+		historyEpic("EPIC-1", 1, 1000), // outside the window
+		historyEpic("EPIC-2", 2, 100),
+		historyEpic("EPIC-3", 0, 0), // no tasks: skipped
+		historyEpic("EPIC-4", 3, 150),
+		historyEpic("EPIC-5", 4, 400), // the current epic itself: skipped
+	}
+
+	// A window of 2 pools EPIC-4 and EPIC-2: 250s over 5 tasks.
+	c, ok := metrics.CompareWithHistory(state, history, 2)
+	if !ok {
+		t.Fatal("expected a comparison")
+	}
+	if c.Epics != 2 || c.BaselineAvgSeconds != 50 || c.Ratio != 2 {
+		t.Errorf("got %+v, want 2 epics, 50s baseline, ratio 2", c)
+	}
+	if got := c.String(); got != "2.0x longer per task than the last 2 epics (50s avg)" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestCompareWithHistory_NoBaseline(t *testing.T) {
+	state := &types.ProjectState{Metrics: types.Metrics{TotalTasksCompleted: 1, TotalDurationSeconds: 10}}
+	if _, ok := metrics.CompareWithHistory(state, nil, metrics.HistoryWindow); ok {
+		t.Error("expected no comparison without history")
+	}
+	if _, ok := metrics.CompareWithHistory(emptyState(), []types.EpicHistory{historyEpic("EPIC-1", 1, 10)}, metrics.HistoryWindow); ok {
+		t.Error("expected no comparison when the current epic has no metrics")
+	}
+}
+
+func TestComparison_String(t *testing.T) {
+	tests := []struct {
+		c    metrics.Comparison
+		want string
+	}{
+		{metrics.Comparison{Epics: 1, BaselineAvgSeconds: 90, Ratio: 0.5}, "2.0x faster per task than the last 1 epic (1m 30s avg)"},
+		{metrics.Comparison{Epics: 3, BaselineAvgSeconds: 30, Ratio: 1.02}, "on par with the last 3 epics (30s avg)"},
+	}
+	for _, tt := range tests {
+		if got := tt.c.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
 }
@@ -0,0 +1,238 @@
+package metrics
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/robertgumeny/doug/internal/types"
+)
+
+// Report formats accepted by WriteReport.
+const (
+	FormatJSON     = "json"
+	FormatCSV      = "csv"
+	FormatMarkdown = "markdown"
+)
+
+// knownOutcomes are the outcomes recorded by the handlers, in the column order
+// used by the CSV and Markdown reports. Any other outcome found in the data is
+// appended after these, sorted.
+var knownOutcomes = []string{"success", "failure", "rejected", "timeout", "bug"}
+
+// Report is the cross-epic metrics report rendered by doug report.
+// JSON tags define the --format json output contract.
+type Report struct {
+	Epics []EpicReport `json:"epics"`
+}
+
+// EpicReport summarizes one epic. Attempts counts every recorded outcome;
+// RetryRate is the share of tasks that needed more than one attempt.
+// Interventions counts manual changes such as doug unblock.
+type EpicReport struct {
+	ID                   string         `json:"id"`
+	Name                 string         `json:"name"`
+	StartedAt            string         `json:"started_at,omitempty"`
+	CompletedAt          string         `json:"completed_at,omitempty"`
+	Archived             bool           `json:"archived"`
+	Tasks                []TaskReport   `json:"tasks"`
+	Attempts             int            `json:"attempts"`
+	TotalDurationSeconds int            `json:"total_duration_seconds"`
+	Outcomes             map[string]int `json:"outcomes"`
+	RetryRate            float64        `json:"retry_rate"`
+	Interventions        int            `json:"interventions"`
+}
+
+// TaskReport summarizes the recorded attempts of one task. FinalOutcome is
+// the outcome of the most recent attempt.
+type TaskReport struct {
+	ID              string         `json:"id"`
+	Attempts        int            `json:"attempts"`
+	DurationSeconds int            `json:"duration_seconds"`
+	FinalOutcome    string         `json:"final_outcome"`
+	Outcomes        map[string]int `json:"outcomes"`
+}
+
+// BuildReport summarizes each epic's metrics. archived epics come first, in
+// the order given, followed by current when it has an ID and is not already
+// among the archived epics.
+func BuildReport(archived []types.EpicHistory, current *types.ProjectState) Report {
+	r := Report{Epics: []EpicReport{}}
+	seen := make(map[string]bool, len(archived))
+	for _, h := range archived {
+		seen[h.Epic.ID] = true
+		r.Epics = append(r.Epics, buildEpicReport(h.Epic, h.Metrics, true))
+	}
+	if current != nil && current.CurrentEpic.ID != "" && !seen[current.CurrentEpic.ID] {
+		r.Epics = append(r.Epics, buildEpicReport(current.CurrentEpic, current.Metrics, false))
+	}
+	return r
+}
+
+// buildEpicReport groups m.Tasks by task ID, preserving first-seen order.
+func buildEpicReport(epic types.EpicState, m types.Metrics, archived bool) EpicReport {
+	er := EpicReport{
+		ID:            epic.ID,
+		Name:          epic.Name,
+		StartedAt:     epic.StartedAt,
+		Archived:      archived,
+		Tasks:         []TaskReport{},
+		Outcomes:      map[string]int{},
+		Interventions: len(m.Interventions),
+	}
+	if epic.CompletedAt != nil {
+		er.CompletedAt = *epic.CompletedAt
+	}
+
+	index := map[string]int{}
+	for _, tm := range m.Tasks {
+		i, ok := index[tm.TaskID]
+		if !ok {
+			i = len(er.Tasks)
+			index[tm.TaskID] = i
+			er.Tasks = append(er.Tasks, TaskReport{ID: tm.TaskID, Outcomes: map[string]int{}})
+		}
+		t := &er.Tasks[i]
+		t.Attempts++
+		t.DurationSeconds += tm.DurationSeconds
+		t.FinalOutcome = tm.Outcome
+		t.Outcomes[tm.Outcome]++
+
+		er.Attempts++
+		er.TotalDurationSeconds += tm.DurationSeconds
+		er.Outcomes[tm.Outcome]++
+	}
+
+	retried := 0
+	for _, t := range er.Tasks {
+		if t.Attempts > 1 {
+			retried++
+		}
+	}
+	if len(er.Tasks) > 0 {
+		er.RetryRate = float64(retried) / float64(len(er.Tasks))
+	}
+	return er
+}
+
+// WriteReport renders r to w in format (FormatJSON, FormatCSV or
+// FormatMarkdown).
+func WriteReport(w io.Writer, r Report, format string) error {
+	switch format {
+	case FormatJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case FormatCSV:
+		return writeCSV(w, r)
+	case FormatMarkdown:
+		return writeMarkdown(w, r)
+	default:
+		return fmt.Errorf("invalid report format %q: must be one of: json, csv, markdown", format)
+	}
+}
+
+// writeCSV writes one row per task, with one column per outcome, so the
+// report can be pivoted in a spreadsheet.
+func writeCSV(w io.Writer, r Report) error {
+	outcomes := outcomeColumns(r)
+	cw := csv.NewWriter(w)
+	header := []string{"epic_id", "epic_name", "archived", "task_id", "attempts", "duration_seconds", "final_outcome"}
+	header = append(header, outcomes...)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, e := range r.Epics {
+		for _, t := range e.Tasks {
+			row := []string{
+				e.ID, e.Name, strconv.FormatBool(e.Archived), t.ID,
+				strconv.Itoa(t.Attempts), strconv.Itoa(t.DurationSeconds), t.FinalOutcome,
+			}
+			for _, o := range outcomes {
+				row = append(row, strconv.Itoa(t.Outcomes[o]))
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeMarkdown writes a section per epic with a summary line and a task table.
+func writeMarkdown(w io.Writer, r Report) error {
+	outcomes := outcomeColumns(r)
+	var sb strings.Builder
+	sb.WriteString("# doug metrics report\n")
+	if len(r.Epics) == 0 {
+		sb.WriteString("\nNo metrics recorded yet.\n")
+	}
+	for _, e := range r.Epics {
+		title := e.ID
+		if e.Name != "" {
+			title += " — " + e.Name
+		}
+		status := "in progress"
+		if e.Archived {
+			status = "archived"
+		} else if e.CompletedAt != "" {
+			status = "complete"
+		}
+		sb.WriteString(fmt.Sprintf("\n## %s (%s)\n\n", title, status))
+		sb.WriteString(fmt.Sprintf("- Tasks: %d\n", len(e.Tasks)))
+		sb.WriteString(fmt.Sprintf("- Attempts: %d\n", e.Attempts))
+		sb.WriteString(fmt.Sprintf("- Total time: %s\n", FormatDuration(e.TotalDurationSeconds)))
+		sb.WriteString(fmt.Sprintf("- Retry rate: %.0f%%\n", e.RetryRate*100))
+		if e.Interventions > 0 {
+			sb.WriteString(fmt.Sprintf("- Manual interventions: %d\n", e.Interventions))
+		}
+		if len(e.Tasks) == 0 {
+			continue
+		}
+
+		sb.WriteString("\n| Task | Attempts | Duration | Final outcome |")
+		for _, o := range outcomes {
+			sb.WriteString(" " + o + " |")
+		}
+		sb.WriteString("\n|---|---:|---:|---|")
+		for range outcomes {
+			sb.WriteString("---:|")
+		}
+		sb.WriteString("\n")
+		for _, t := range e.Tasks {
+			sb.WriteString(fmt.Sprintf("| %s | %d | %s | %s |", t.ID, t.Attempts, FormatDuration(t.DurationSeconds), t.FinalOutcome))
+			for _, o := range outcomes {
+				sb.WriteString(fmt.Sprintf(" %d |", t.Outcomes[o]))
+			}
+			sb.WriteString("\n")
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// outcomeColumns returns knownOutcomes followed by any other outcome present
+// in r, sorted.
+func outcomeColumns(r Report) []string {
+	cols := append([]string(nil), knownOutcomes...)
+	known := map[string]bool{}
+	for _, o := range knownOutcomes {
+		known[o] = true
+	}
+	var extra []string
+	for _, e := range r.Epics {
+		for o := range e.Outcomes {
+			if !known[o] {
+				known[o] = true
+				extra = append(extra, o)
+			}
+		}
+	}
+	sort.Strings(extra)
+	return append(cols, extra...)
+}
@@ -0,0 +1,134 @@
+package metrics_test
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/robertgumeny/doug/internal/metrics"
+	"github.com/robertgumeny/doug/internal/types"
+)
+
+func reportFixture() metrics.Report {
+	completed := "2026-03-02T00:00:00Z"
+	archived := []types.EpicHistory{{
+		Epic: types.EpicState{ID: "EPIC-1", Name: "First", StartedAt: "2026-03-01T00:00:00Z", CompletedAt: &completed},
+		Metrics: types.Metrics{Tasks: []types.TaskMetric{
+			{TaskID: "EPIC-1-001", Outcome: "rejected", DurationSeconds: 30},
+			{TaskID: "EPIC-1-001", Outcome: "success", DurationSeconds: 60},
+			{TaskID: "EPIC-1-002", Outcome: "success", DurationSeconds: 90},
+		}},
+	}}
+	current := &types.ProjectState{
+		CurrentEpic: types.EpicState{ID: "EPIC-2", Name: "Second"},
+		Metrics: types.Metrics{Tasks: []types.TaskMetric{
+			{TaskID: "EPIC-2-001", Outcome: "timeout", DurationSeconds: 10},
+		}},
+	}
+	return metrics.BuildReport(archived, current)
+}
+
+func TestBuildReport_GroupsAttemptsByTask(t *testing.T) {
+	r := reportFixture()
+	if len(r.Epics) != 2 {
+		t.Fatalf("got %d epics, want 2", len(r.Epics))
+	}
+
+	e := r.Epics[0]
+	if e.ID != "EPIC-1" || !e.Archived || e.CompletedAt == "" {
+		t.Errorf("first epic = %+v, want archived EPIC-1 with completed_at", e)
+	}
+	if len(e.Tasks) != 2 {
+		t.Fatalf("got %d tasks, want 2", len(e.Tasks))
+	}
+	task := e.Tasks[0]
+	if task.ID != "EPIC-1-001" || task.Attempts != 2 || task.DurationSeconds != 90 || task.FinalOutcome != "success" {
+		t.Errorf("task = %+v, want EPIC-1-001 with 2 attempts, 90s, final success", task)
+	}
+	if e.Attempts != 3 || e.TotalDurationSeconds != 180 {
+		t.Errorf("epic attempts=%d duration=%d, want 3 and 180", e.Attempts, e.TotalDurationSeconds)
+	}
+	if e.Outcomes["success"] != 2 || e.Outcomes["rejected"] != 1 {
+		t.Errorf("outcomes = %v, want success:2 rejected:1", e.Outcomes)
+	}
+	if e.RetryRate != 0.5 {
+		t.Errorf("RetryRate = %v, want 0.5", e.RetryRate)
+	}
+
+	if cur := r.Epics[1]; cur.ID != "EPIC-2" || cur.Archived {
+		t.Errorf("second epic = %+v, want current EPIC-2", cur)
+	}
+}
+
+func TestBuildReport_SkipsCurrentWhenArchived(t *testing.T) {
+	archived := []types.EpicHistory{{Epic: types.EpicState{ID: "EPIC-1"}}}
+	current := &types.ProjectState{CurrentEpic: types.EpicState{ID: "EPIC-1"}}
+
+	if r := metrics.BuildReport(archived, current); len(r.Epics) != 1 {
+		t.Errorf("got %d epics, want 1", len(r.Epics))
+	}
+	if r := metrics.BuildReport(nil, nil); len(r.Epics) != 0 {
+		t.Errorf("got %d epics for no data, want 0", len(r.Epics))
+	}
+}
+
+func TestWriteReport_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := metrics.WriteReport(&buf, reportFixture(), metrics.FormatJSON); err != nil {
+		t.Fatalf("WriteReport: %v", err)
+	}
+	var decoded metrics.Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, buf.String())
+	}
+	if len(decoded.Epics) != 2 || decoded.Epics[0].RetryRate != 0.5 {
+		t.Errorf("decoded = %+v", decoded)
+	}
+}
+
+func TestWriteReport_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := metrics.WriteReport(&buf, reportFixture(), metrics.FormatCSV); err != nil {
+		t.Fatalf("WriteReport: %v", err)
+	}
+	rows, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	if len(rows) != 4 {
+		t.Fatalf("got %d rows, want header + 3 tasks", len(rows))
+	}
+	want := "epic_id,epic_name,archived,task_id,attempts,duration_seconds,final_outcome,success,failure,rejected,timeout,bug"
+	if got := strings.Join(rows[0], ","); got != want {
+		t.Errorf("header = %q, want %q", got, want)
+	}
+	if got := strings.Join(rows[1], ","); got != "EPIC-1,First,true,EPIC-1-001,2,90,success,1,0,1,0,0" {
+		t.Errorf("first row = %q", got)
+	}
+}
+
+func TestWriteReport_Markdown(t *testing.T) {
+	var buf bytes.Buffer
+	if err := metrics.WriteReport(&buf, reportFixture(), metrics.FormatMarkdown); err != nil {
+		t.Fatalf("WriteReport: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{
+		"## EPIC-1 — First (archived)",
+		"## EPIC-2 — Second (in progress)",
+		"- Retry rate: 50%",
+		"| EPIC-1-001 | 2 | 1m 30s | success |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("markdown output missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteReport_UnknownFormat(t *testing.T) {
+	if err := metrics.WriteReport(&bytes.Buffer{}, metrics.Report{}, "xml"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
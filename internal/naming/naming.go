@@ -0,0 +1,127 @@
+// Package naming renders the branch_template and commit_template settings
+// from doug.yaml into git branch names and commit messages.
+package naming
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// NameData holds the variables available to branch and commit message
+// templates. Branch names are rendered before any task runs, so only Epic and
+// EpicName are set for them.
+type NameData struct {
+	Epic        string // epic ID
+	EpicName    string // epic name
+	Task        string // task ID; empty for branch names and the epic finalization commit
+	Type        string // task type (feature, bugfix, documentation); "epic", "merge" or "squash" for epic commits
+	Kind        string // conventional commit type: feat, fix, docs or chore
+	Description string // task description; the epic name for epic commits
+	Changelog   string // changelog entry reported by the agent; the epic's entries for a squash
+	Attempt     int    // attempt number of the committed task
+	Branch      string // epic branch being merged or squashed
+	Base        string // branch it is integrated into
+}
+
+// sampleNameData is rendered by the Validate functions to catch references to
+// unknown variables before a run starts.
+var sampleNameData = NameData{
+	Epic:        "EPIC-1",
+	EpicName:    "Sample Epic",
+	Task:        "EPIC-1-001",
+	Type:        "feature",
+	Kind:        "feat",
+	Description: "Sample task",
+	Changelog:   "Add a sample feature",
+	Attempt:     1,
+	Branch:      "feature/EPIC-1",
+	Base:        "main",
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// templateFuncs are the helper functions available to name templates.
+var templateFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+	// slug lowercases s and joins its runs of letters and digits with "-".
+	"slug": func(s string) string {
+		return strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	},
+}
+
+// RenderBranchName executes the branch name template text with d. The result
+// is trimmed and must be a valid git branch name.
+func RenderBranchName(text string, d NameData) (string, error) {
+	name, err := render("branch", text, d)
+	if err != nil {
+		return "", fmt.Errorf("RenderBranchName: %w", err)
+	}
+	if err := checkBranchName(name); err != nil {
+		return "", fmt.Errorf("RenderBranchName: %w", err)
+	}
+	return name, nil
+}
+
+// RenderCommitMessage executes the commit message template text with d. The
+// result is trimmed and must not be empty.
+func RenderCommitMessage(text string, d NameData) (string, error) {
+	msg, err := render("commit", text, d)
+	if err != nil {
+		return "", fmt.Errorf("RenderCommitMessage: %w", err)
+	}
+	if msg == "" {
+		return "", errors.New("RenderCommitMessage: template rendered an empty message")
+	}
+	return msg, nil
+}
+
+// ValidateBranchTemplate reports whether text parses and renders a valid
+// branch name for a sample epic.
+func ValidateBranchTemplate(text string) error {
+	_, err := RenderBranchName(text, sampleNameData)
+	return err
+}
+
+// ValidateCommitTemplate reports whether text parses and renders a non-empty
+// message for a sample task.
+func ValidateCommitTemplate(text string) error {
+	_, err := RenderCommitMessage(text, sampleNameData)
+	return err
+}
+
+// render parses and executes text with d and returns the trimmed output.
+func render(name, text string, d NameData) (string, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, d); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// checkBranchName applies the git check-ref-format rules that a rendered
+// template can plausibly break.
+func checkBranchName(name string) error {
+	switch {
+	case name == "":
+		return errors.New("template rendered an empty branch name")
+	case strings.ContainsAny(name, " \t\n~^:?*[\\"),
+		strings.Contains(name, ".."),
+		strings.Contains(name, "@{"),
+		strings.Contains(name, "//"),
+		strings.HasPrefix(name, "-"),
+		strings.HasPrefix(name, "/"),
+		strings.HasSuffix(name, "/"),
+		strings.HasSuffix(name, "."),
+		strings.HasSuffix(name, ".lock"):
+		return fmt.Errorf("invalid branch name %q", name)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package naming_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/robertgumeny/doug/internal/naming"
+)
+
+func TestRenderBranchName(t *testing.T) {
+	d := naming.NameData{Epic: "PAY-42", EpicName: "Refund Flow: v2"}
+
+	got, err := naming.RenderBranchName("payments/{{lower .Epic}}-{{slug .EpicName}}", d)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "payments/pay-42-refund-flow-v2" {
+		t.Errorf("branch = %q, want %q", got, "payments/pay-42-refund-flow-v2")
+	}
+
+	for _, tmpl := range []string{"", "feature/{{.EpicName}}", "feature/", "{{.Task}}"} {
+		if _, err := naming.RenderBranchName(tmpl, d); err == nil {
+			t.Errorf("RenderBranchName(%q): expected an error", tmpl)
+		}
+	}
+}
+
+func TestRenderCommitMessage(t *testing.T) {
+	d := naming.NameData{Epic: "PAY-42", Task: "PAY-42-001", Kind: "feat", Description: "Add refunds"}
+
+	got, err := naming.RenderCommitMessage("{{.Kind}}: {{.Description}}\n\nRefs: {{.Task}}\n", d)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "feat: Add refunds\n\nRefs: PAY-42-001" {
+		t.Errorf("message = %q", got)
+	}
+
+	if _, err := naming.RenderCommitMessage("{{.Changelog}}", d); err == nil {
+		t.Error("expected an error for an empty message")
+	}
+}
+
+func TestValidateTemplates_RejectUnknownVariables(t *testing.T) {
+	if err := naming.ValidateBranchTemplate("feature/{{.Ticket}}"); err == nil || !strings.Contains(err.Error(), "Ticket") {
+		t.Errorf("expected error naming the unknown field, got: %v", err)
+	}
+	if err := naming.ValidateCommitTemplate("{{.Kind}: oops"); err == nil {
+		t.Error("expected a parse error")
+	}
+	if err := naming.ValidateCommitTemplate("{{.Kind}}: {{.Task}}"); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+// Package notify publishes orchestrator run events (task done, task blocked,
+// epic complete, run aborted) to external sinks. Handlers publish through the
+// Sink interface; WebhookSink delivers events as signed HTTP POSTs.
+package notify
+
+// EventType names a run event.
+type EventType string
+
+// Run events published by the orchestrator.
+const (
+	TaskDone     EventType = "task_done"     // a task was committed
+	TaskBlocked  EventType = "task_blocked"  // a task exhausted max_retries
+	EpicComplete EventType = "epic_complete" // the epic was finalized
+	RunAborted   EventType = "run_aborted"   // doug run stopped with an error or signal
+)
+
+// EventTypes lists every event type a sink can subscribe to.
+var EventTypes = []EventType{TaskDone, TaskBlocked, EpicComplete, RunAborted}
+
+// Event describes one run event. Fields that do not apply are omitted from
+// the JSON encoding.
+type Event struct {
+	Type      EventType `json:"event"`
+	Timestamp string    `json:"timestamp"`
+	EpicID    string    `json:"epic_id"`
+	EpicName  string    `json:"epic_name,omitempty"`
+	TaskID    string    `json:"task_id,omitempty"`
+	TaskType  string    `json:"task_type,omitempty"`
+	Attempt   int       `json:"attempt,omitempty"`
+	Outcome   string    `json:"outcome,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	Metrics   Metrics   `json:"metrics"`
+}
+
+// Metrics summarizes run progress at the time of the event.
+type Metrics struct {
+	TasksCompleted       int `json:"tasks_completed"`
+	TotalDurationSeconds int `json:"total_duration_seconds"`
+	TaskDurationSeconds  int `json:"task_duration_seconds,omitempty"`
+}
+
+// Sink receives run events. Publish must not block the orchestration loop for
+// long; failures are the sink's to report, not the caller's.
+type Sink interface {
+	Publish(e Event)
+}
+
+// isEventType reports whether t is one of EventTypes.
+func isEventType(t EventType) bool {
+	for _, known := range EventTypes {
+		if known == t {
+			return true
+		}
+	}
+	return false
+}
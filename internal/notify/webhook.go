@@ -0,0 +1,181 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/robertgumeny/doug/internal/log"
+)
+
+// HTTP headers set on every webhook delivery.
+const (
+	EventHeader     = "X-Doug-Event"
+	SignatureHeader = "X-Doug-Signature"
+)
+
+// Defaults applied by NewWebhookSink to zero-valued WebhookOptions fields.
+const (
+	DefaultMaxAttempts = 3
+	DefaultDeadline    = 10 * time.Second
+	DefaultBackoff     = 500 * time.Millisecond
+)
+
+// WebhookOptions configures a WebhookSink.
+//
+// Secret, when non-empty, signs each body with HMAC-SHA256 (see Sign).
+// Events restricts delivery to the listed event types; empty means all.
+// MaxAttempts bounds deliveries per event, retrying network errors, 429 and
+// 5xx responses with exponential backoff starting at Backoff. Deadline caps
+// the total time one Publish may take, retries included.
+type WebhookOptions struct {
+	URL         string
+	Secret      string
+	Events      []string
+	MaxAttempts int
+	Deadline    time.Duration
+	Backoff     time.Duration
+}
+
+// WebhookSink POSTs events as JSON to a single endpoint.
+type WebhookSink struct {
+	url         string
+	secret      []byte
+	events      map[EventType]bool
+	maxAttempts int
+	deadline    time.Duration
+	backoff     time.Duration
+	client      *http.Client
+}
+
+// NewWebhookSink validates opts and returns a sink for opts.URL. The URL must
+// be absolute http or https, and every entry in opts.Events must be one of
+// EventTypes.
+func NewWebhookSink(opts WebhookOptions) (*WebhookSink, error) {
+	u, err := url.Parse(opts.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("webhook url %q must be an absolute http or https URL", opts.URL)
+	}
+	if opts.MaxAttempts < 0 {
+		return nil, fmt.Errorf("webhook max_attempts must not be negative")
+	}
+
+	events := make(map[EventType]bool, len(opts.Events))
+	for _, name := range opts.Events {
+		t := EventType(name)
+		if !isEventType(t) {
+			return nil, fmt.Errorf("unknown webhook event %q: must be one of: task_done, task_blocked, epic_complete, run_aborted", name)
+		}
+		events[t] = true
+	}
+
+	w := &WebhookSink{
+		url:         opts.URL,
+		secret:      []byte(opts.Secret),
+		events:      events,
+		maxAttempts: opts.MaxAttempts,
+		deadline:    opts.Deadline,
+		backoff:     opts.Backoff,
+		client:      &http.Client{},
+	}
+	if w.maxAttempts == 0 {
+		w.maxAttempts = DefaultMaxAttempts
+	}
+	if w.deadline <= 0 {
+		w.deadline = DefaultDeadline
+	}
+	if w.backoff <= 0 {
+		w.backoff = DefaultBackoff
+	}
+	return w, nil
+}
+
+// Publish delivers e unless the sink is not subscribed to its type. It
+// returns once the event is delivered, retries are exhausted, or the deadline
+// passes; delivery failures are logged as warnings.
+func (w *WebhookSink) Publish(e Event) {
+	if len(w.events) > 0 && !w.events[e.Type] {
+		return
+	}
+	if e.Timestamp == "" {
+		e.Timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	// Marshal cannot fail: Event holds only strings and ints.
+	body, _ := json.Marshal(e)
+
+	ctx, cancel := context.WithTimeout(context.Background(), w.deadline)
+	defer cancel()
+	if err := w.deliver(ctx, e.Type, body); err != nil {
+		log.Warning(fmt.Sprintf("webhook %s delivery failed: %v", e.Type, err))
+	}
+}
+
+// deliver POSTs body until it is accepted, a non-retryable response arrives,
+// maxAttempts is reached, or ctx expires.
+func (w *WebhookSink) deliver(ctx context.Context, t EventType, body []byte) error {
+	wait := w.backoff
+	var lastErr error
+	for attempt := 1; attempt <= w.maxAttempts; attempt++ {
+		retry, err := w.post(ctx, t, body)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || attempt == w.maxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("deadline of %s exceeded after %d attempt(s): %w", w.deadline, attempt, lastErr)
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+	return lastErr
+}
+
+// post makes one delivery attempt. retry reports whether a failure is worth
+// another attempt.
+func (w *WebhookSink) post(ctx context.Context, t EventType, body []byte) (retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "doug")
+	req.Header.Set(EventHeader, string(t))
+	if len(w.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(w.secret, body))
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		// A request cut short by the deadline is not worth retrying.
+		return ctx.Err() == nil, err
+	}
+	// Drain a bounded amount so the connection can be reused.
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("endpoint returned %s", resp.Status)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+// Sign returns the SignatureHeader value for body: "sha256=" followed by the
+// hex-encoded HMAC-SHA256 of body keyed with secret.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
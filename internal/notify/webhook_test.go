@@ -0,0 +1,165 @@
+package notify_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/robertgumeny/doug/internal/notify"
+)
+
+func newSink(t *testing.T, opts notify.WebhookOptions) *notify.WebhookSink {
+	t.Helper()
+	if opts.Backoff == 0 {
+		opts.Backoff = time.Millisecond
+	}
+	sink, err := notify.NewWebhookSink(opts)
+	if err != nil {
+		t.Fatalf("NewWebhookSink: %v", err)
+	}
+	return sink
+}
+
+func TestWebhookSink_DeliversSignedEvent(t *testing.T) {
+	var (
+		gotBody   []byte
+		gotHeader http.Header
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotHeader = r.Header
+	}))
+	defer srv.Close()
+
+	sink := newSink(t, notify.WebhookOptions{URL: srv.URL, Secret: "s3cret"})
+	sink.Publish(notify.Event{
+		Type:    notify.TaskDone,
+		EpicID:  "EPIC-1",
+		TaskID:  "EPIC-1-001",
+		Outcome: "SUCCESS",
+		Metrics: notify.Metrics{TasksCompleted: 1, TotalDurationSeconds: 42},
+	})
+
+	if gotHeader.Get(notify.EventHeader) != "task_done" {
+		t.Errorf("%s = %q, want task_done", notify.EventHeader, gotHeader.Get(notify.EventHeader))
+	}
+	if want := notify.Sign([]byte("s3cret"), gotBody); gotHeader.Get(notify.SignatureHeader) != want {
+		t.Errorf("%s = %q, want %q", notify.SignatureHeader, gotHeader.Get(notify.SignatureHeader), want)
+	}
+	var e notify.Event
+	if err := json.Unmarshal(gotBody, &e); err != nil {
+		t.Fatalf("body is not JSON: %v", err)
+	}
+	if e.TaskID != "EPIC-1-001" || e.Metrics.TotalDurationSeconds != 42 || e.Timestamp == "" {
+		t.Errorf("unexpected event: %+v", e)
+	}
+}
+
+func TestWebhookSink_UnsignedWithoutSecret(t *testing.T) {
+	var signature string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(notify.SignatureHeader)
+	}))
+	defer srv.Close()
+
+	newSink(t, notify.WebhookOptions{URL: srv.URL}).Publish(notify.Event{Type: notify.TaskDone})
+	if signature != "" {
+		t.Errorf("expected no signature header, got %q", signature)
+	}
+}
+
+func TestWebhookSink_Retries(t *testing.T) {
+	t.Run("5xx is retried until accepted", func(t *testing.T) {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if calls.Add(1) < 3 {
+				w.WriteHeader(http.StatusBadGateway)
+			}
+		}))
+		defer srv.Close()
+
+		newSink(t, notify.WebhookOptions{URL: srv.URL, MaxAttempts: 5}).Publish(notify.Event{Type: notify.TaskBlocked})
+		if got := calls.Load(); got != 3 {
+			t.Errorf("expected 3 attempts, got %d", got)
+		}
+	})
+
+	t.Run("attempts are bounded", func(t *testing.T) {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer srv.Close()
+
+		newSink(t, notify.WebhookOptions{URL: srv.URL, MaxAttempts: 2}).Publish(notify.Event{Type: notify.TaskBlocked})
+		if got := calls.Load(); got != 2 {
+			t.Errorf("expected 2 attempts, got %d", got)
+		}
+	})
+
+	t.Run("4xx is not retried", func(t *testing.T) {
+		var calls atomic.Int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls.Add(1)
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer srv.Close()
+
+		newSink(t, notify.WebhookOptions{URL: srv.URL, MaxAttempts: 5}).Publish(notify.Event{Type: notify.TaskBlocked})
+		if got := calls.Load(); got != 1 {
+			t.Errorf("expected 1 attempt, got %d", got)
+		}
+	})
+}
+
+func TestWebhookSink_Deadline(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	sink := newSink(t, notify.WebhookOptions{URL: srv.URL, Deadline: 200 * time.Millisecond})
+	start := time.Now()
+	sink.Publish(notify.Event{Type: notify.RunAborted})
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Publish blocked for %s despite a 200ms deadline", elapsed)
+	}
+}
+
+func TestWebhookSink_EventFilter(t *testing.T) {
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = append(got, r.Header.Get(notify.EventHeader))
+	}))
+	defer srv.Close()
+
+	sink := newSink(t, notify.WebhookOptions{URL: srv.URL, Events: []string{"epic_complete"}})
+	sink.Publish(notify.Event{Type: notify.TaskDone})
+	sink.Publish(notify.Event{Type: notify.EpicComplete})
+	if strings.Join(got, ",") != "epic_complete" {
+		t.Errorf("expected only epic_complete delivered, got %v", got)
+	}
+}
+
+func TestNewWebhookSink_Validation(t *testing.T) {
+	for _, opts := range []notify.WebhookOptions{
+		{URL: "hooks.example.com/doug"},
+		{URL: "ftp://example.com"},
+		{URL: "https://example.com", Events: []string{"task_started"}},
+	} {
+		if _, err := notify.NewWebhookSink(opts); err == nil {
+			t.Errorf("expected error for %+v", opts)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package orchestrator
+
+import "github.com/robertgumeny/doug/internal/types"
+
+// RecordAttempt appends a rejected attempt to state.PreviousAttempts.
+func RecordAttempt(state *types.ProjectState, rec types.AttemptRecord) {
+	state.PreviousAttempts = append(state.PreviousAttempts, rec)
+}
+
+// AttemptsFor returns the recorded rejected attempts for taskID, oldest first.
+func AttemptsFor(state *types.ProjectState, taskID string) []types.AttemptRecord {
+	var out []types.AttemptRecord
+	for _, rec := range state.PreviousAttempts {
+		if rec.TaskID == taskID {
+			out = append(out, rec)
+		}
+	}
+	return out
+}
+
+// ClearAttempts removes every recorded attempt for taskID. It is called once
+// the task is finished (DONE or BLOCKED) and the history is no longer useful.
+func ClearAttempts(state *types.ProjectState, taskID string) {
+	kept := state.PreviousAttempts[:0]
+	for _, rec := range state.PreviousAttempts {
+		if rec.TaskID != taskID {
+			kept = append(kept, rec)
+		}
+	}
+	if len(kept) == 0 {
+		kept = nil
+	}
+	state.PreviousAttempts = kept
+}
+
+// AddNote appends a human note to state.HumanNotes.
+func AddNote(state *types.ProjectState, note types.HumanNote) {
+	state.HumanNotes = append(state.HumanNotes, note)
+}
+
+// NotesFor returns the human notes attached to taskID, oldest first.
+func NotesFor(state *types.ProjectState, taskID string) []types.HumanNote {
+	var out []types.HumanNote
+	for _, n := range state.HumanNotes {
+		if n.TaskID == taskID {
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// ClearNotes removes every human note attached to taskID. Like ClearAttempts
+// it is called once the task is finished.
+func ClearNotes(state *types.ProjectState, taskID string) {
+	kept := state.HumanNotes[:0]
+	for _, n := range state.HumanNotes {
+		if n.TaskID != taskID {
+			kept = append(kept, n)
+		}
+	}
+	if len(kept) == 0 {
+		kept = nil
+	}
+	state.HumanNotes = kept
+}
@@ -0,0 +1,52 @@
+package orchestrator_test
+
+import (
+	"testing"
+
+	"github.com/robertgumeny/doug/internal/orchestrator"
+	"github.com/robertgumeny/doug/internal/types"
+)
+
+func TestAttemptHistory(t *testing.T) {
+	st := &types.ProjectState{}
+	orchestrator.RecordAttempt(st, types.AttemptRecord{TaskID: "T1", Attempt: 1, Reason: "build verification failed"})
+	orchestrator.RecordAttempt(st, types.AttemptRecord{TaskID: "T2", Attempt: 1, Reason: "agent reported FAILURE"})
+	orchestrator.RecordAttempt(st, types.AttemptRecord{TaskID: "T1", Attempt: 2, Reason: "test verification failed"})
+
+	got := orchestrator.AttemptsFor(st, "T1")
+	if len(got) != 2 || got[0].Attempt != 1 || got[1].Attempt != 2 {
+		t.Fatalf("AttemptsFor(T1) = %+v, want attempts 1 and 2 in order", got)
+	}
+
+	orchestrator.ClearAttempts(st, "T1")
+	if got := orchestrator.AttemptsFor(st, "T1"); len(got) != 0 {
+		t.Errorf("expected T1 history cleared, got %+v", got)
+	}
+	if got := orchestrator.AttemptsFor(st, "T2"); len(got) != 1 {
+		t.Errorf("expected T2 history kept, got %+v", got)
+	}
+
+	orchestrator.ClearAttempts(st, "T2")
+	if st.PreviousAttempts != nil {
+		t.Errorf("expected nil history once empty, got %+v", st.PreviousAttempts)
+	}
+}
+
+func TestHumanNotes(t *testing.T) {
+	st := &types.ProjectState{}
+	orchestrator.AddNote(st, types.HumanNote{TaskID: "T1", Note: "use the v2 API"})
+	orchestrator.AddNote(st, types.HumanNote{TaskID: "T2", Note: "skip the migration"})
+
+	if got := orchestrator.NotesFor(st, "T1"); len(got) != 1 || got[0].Note != "use the v2 API" {
+		t.Fatalf("NotesFor(T1) = %+v", got)
+	}
+
+	orchestrator.ClearNotes(st, "T1")
+	if got := orchestrator.NotesFor(st, "T1"); len(got) != 0 {
+		t.Errorf("expected T1 notes cleared, got %+v", got)
+	}
+	orchestrator.ClearNotes(st, "T2")
+	if st.HumanNotes != nil {
+		t.Errorf("expected HumanNotes nil once empty, got %+v", st.HumanNotes)
+	}
+}
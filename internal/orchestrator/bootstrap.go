@@ -4,8 +4,10 @@
 package orchestrator
 
 import (
+	"fmt"
 	"time"
 
+	"github.com/robertgumeny/doug/internal/naming"
 	"github.com/robertgumeny/doug/internal/types"
 )
 
@@ -13,17 +15,29 @@ import (
 // It is a no-op if state.CurrentEpic.ID is already set (already bootstrapped).
 //
 // On first run it populates:
-//   - current_epic: id, name, branch_name, started_at
+//   - current_epic: id, name, started_at, and branch_name rendered from
+//     branchTemplate (config.DefaultBranchTemplate gives feature/{epic})
 //   - active_task: first task in tasks.yaml
 //   - next_task: second task, or zero value if only one task exists
-func BootstrapFromTasks(state *types.ProjectState, tasks *types.Tasks) {
+//
+// An error is returned, and state left untouched, when the branch name cannot
+// be rendered.
+func BootstrapFromTasks(state *types.ProjectState, tasks *types.Tasks, branchTemplate string) error {
 	if state.CurrentEpic.ID != "" {
-		return
+		return nil
+	}
+
+	branch, err := naming.RenderBranchName(branchTemplate, naming.NameData{
+		Epic:     tasks.Epic.ID,
+		EpicName: tasks.Epic.Name,
+	})
+	if err != nil {
+		return fmt.Errorf("branch name for epic %s: %w", tasks.Epic.ID, err)
 	}
 
 	state.CurrentEpic.ID = tasks.Epic.ID
 	state.CurrentEpic.Name = tasks.Epic.Name
-	state.CurrentEpic.BranchName = "feature/" + tasks.Epic.ID
+	state.CurrentEpic.BranchName = branch
 	state.CurrentEpic.StartedAt = time.Now().UTC().Format(time.RFC3339)
 
 	if len(tasks.Epic.Tasks) > 0 {
@@ -41,6 +55,7 @@ func BootstrapFromTasks(state *types.ProjectState, tasks *types.Tasks) {
 			ID:   second.ID,
 		}
 	}
+	return nil
 }
 
 // NeedsKBSynthesis reports whether a KB synthesis (documentation) task should
@@ -49,7 +64,8 @@ func BootstrapFromTasks(state *types.ProjectState, tasks *types.Tasks) {
 // Returns false when:
 //   - kbEnabled is false
 //   - active task is already a documentation type (KB synthesis already running)
-//   - any user-defined task remains TODO or IN_PROGRESS
+//   - any user-defined task is not DONE; a BLOCKED task needs manual review,
+//     and synthesis would go on to finalize the unfinished epic
 //
 // Returns true only when all user-defined tasks are DONE and KB synthesis
 // has not yet been started.
@@ -61,7 +77,7 @@ func NeedsKBSynthesis(state *types.ProjectState, tasks *types.Tasks, kbEnabled b
 		return false
 	}
 	for _, t := range tasks.Epic.Tasks {
-		if t.Status == types.StatusTODO || t.Status == types.StatusInProgress {
+		if t.Status != types.StatusDone {
 			return false
 		}
 	}
@@ -4,6 +4,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/robertgumeny/doug/internal/config"
 	"github.com/robertgumeny/doug/internal/orchestrator"
 	"github.com/robertgumeny/doug/internal/types"
 )
@@ -49,7 +50,9 @@ func TestBootstrapFromTasks_FreshState(t *testing.T) {
 	state := freshState()
 	tasks := twoTaskTasks()
 
-	orchestrator.BootstrapFromTasks(state, tasks)
+	if err := orchestrator.BootstrapFromTasks(state, tasks, config.DefaultBranchTemplate); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	if state.CurrentEpic.ID != "EPIC-3" {
 		t.Errorf("CurrentEpic.ID: got %q, want %q", state.CurrentEpic.ID, "EPIC-3")
@@ -115,7 +118,9 @@ func TestBootstrapFromTasks_AlreadyBootstrapped(t *testing.T) {
 	origActiveID := state.ActiveTask.ID
 	origAttempts := state.ActiveTask.Attempts
 
-	orchestrator.BootstrapFromTasks(state, tasks)
+	if err := orchestrator.BootstrapFromTasks(state, tasks, config.DefaultBranchTemplate); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	if state.CurrentEpic.ID != origID {
 		t.Errorf("CurrentEpic.ID changed: got %q, want %q", state.CurrentEpic.ID, origID)
@@ -141,7 +146,9 @@ func TestBootstrapFromTasks_SingleTaskEpic(t *testing.T) {
 	state := freshState()
 	tasks := singleTaskTasks()
 
-	orchestrator.BootstrapFromTasks(state, tasks)
+	if err := orchestrator.BootstrapFromTasks(state, tasks, config.DefaultBranchTemplate); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
 
 	if state.ActiveTask.ID != "EPIC-1-001" {
 		t.Errorf("ActiveTask.ID: got %q, want %q", state.ActiveTask.ID, "EPIC-1-001")
@@ -155,6 +162,31 @@ func TestBootstrapFromTasks_SingleTaskEpic(t *testing.T) {
 	}
 }
 
+func TestBootstrapFromTasks_BranchTemplate(t *testing.T) {
+	state := freshState()
+	tasks := twoTaskTasks()
+
+	if err := orchestrator.BootstrapFromTasks(state, tasks, "platform/{{lower .Epic}}-{{slug .EpicName}}"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.CurrentEpic.BranchName != "platform/epic-3-state-management" {
+		t.Errorf("CurrentEpic.BranchName: got %q, want %q", state.CurrentEpic.BranchName, "platform/epic-3-state-management")
+	}
+}
+
+func TestBootstrapFromTasks_InvalidBranchLeavesStateUntouched(t *testing.T) {
+	state := freshState()
+	tasks := twoTaskTasks()
+
+	// The epic name contains spaces, which are not allowed in a branch name.
+	if err := orchestrator.BootstrapFromTasks(state, tasks, "feature/{{.EpicName}}"); err == nil {
+		t.Fatal("expected an error for an invalid branch name")
+	}
+	if state.CurrentEpic.ID != "" || state.ActiveTask.ID != "" {
+		t.Errorf("expected state untouched, got %+v", state)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // NeedsKBSynthesis
 // ---------------------------------------------------------------------------
@@ -225,6 +257,23 @@ func TestNeedsKBSynthesis_AllDoneKBEnabled(t *testing.T) {
 	}
 }
 
+func TestNeedsKBSynthesis_BlockedTask(t *testing.T) {
+	state := &types.ProjectState{
+		ActiveTask: types.TaskPointer{Type: types.TaskTypeFeature, ID: "EPIC-3-002"},
+	}
+	tasks := &types.Tasks{
+		Epic: types.EpicDefinition{
+			Tasks: []types.Task{
+				{ID: "EPIC-3-001", Status: types.StatusBlocked},
+				{ID: "EPIC-3-002", Status: types.StatusDone},
+			},
+		},
+	}
+	if orchestrator.NeedsKBSynthesis(state, tasks, true) {
+		t.Error("NeedsKBSynthesis: want false while a task is BLOCKED")
+	}
+}
+
 // ---------------------------------------------------------------------------
 // IsEpicAlreadyComplete
 // ---------------------------------------------------------------------------
@@ -4,10 +4,13 @@
 package orchestrator
 
 import (
+	"os"
 	"time"
 
 	"github.com/robertgumeny/doug/internal/build"
 	"github.com/robertgumeny/doug/internal/config"
+	"github.com/robertgumeny/doug/internal/log"
+	"github.com/robertgumeny/doug/internal/notify"
 	"github.com/robertgumeny/doug/internal/types"
 )
 
@@ -34,12 +37,37 @@ type LoopContext struct {
 	// Agent output parsed from the session file
 	SessionResult *types.SessionResult
 
+	// AgentTimedOut is true when the agent was killed for exceeding its
+	// timeout. The loop treats this as a FAILURE outcome.
+	AgentTimedOut bool
+
+	// FailureReason explains a FAILURE the orchestrator inferred on the
+	// agent's behalf (timeout, unreadable session file). Empty when the
+	// agent reported FAILURE itself.
+	FailureReason string
+
+	// TranscriptPath is the captured agent output for this attempt; empty
+	// when no transcript was recorded.
+	TranscriptPath string
+
+	// SessionPath is the session result file the agent writes for this
+	// attempt; empty when no attempt ran (e.g. epic finalization at startup).
+	SessionPath string
+
 	// Orchestrator configuration (from doug.yaml + CLI flag overrides)
 	Config *config.OrchestratorConfig
 
 	// Build system for the project (Go or npm)
 	BuildSystem build.BuildSystem
 
+	// Interrupt delivers the SIGINT/SIGTERM doug receives during the
+	// iteration; nil when the caller does not handle signals.
+	Interrupt <-chan os.Signal
+
+	// Events receives run events (task done/blocked, epic complete); nil
+	// when no sink is configured.
+	Events notify.Sink
+
 	// Absolute path to the project root directory
 	ProjectRoot string
 
@@ -55,5 +83,16 @@ type LoopContext struct {
 	TasksPath     string // path to tasks.yaml
 	DougDir       string // path to .doug/ directory (ACTIVE_TASK.md, ACTIVE_BUG.md, ACTIVE_FAILURE.md)
 	LogsDir       string // path to .doug/logs/ directory (session/bug/failure archives)
+	HistoryDir    string // path to .doug/history/ directory (archived epic metrics)
 	ChangelogPath string // path to CHANGELOG.md
 }
+
+// LogContext returns the log.TaskContext that attributes log events to this
+// iteration's epic, task, and attempt.
+func (c *LoopContext) LogContext() log.TaskContext {
+	return log.TaskContext{
+		EpicID:  c.CurrentEpic.ID,
+		TaskID:  c.TaskID,
+		Attempt: c.Attempts,
+	}
+}
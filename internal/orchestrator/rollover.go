@@ -37,6 +37,7 @@ func PrepareForEpicRollover(state *types.ProjectState, tasks *types.Tasks) (bool
 	state.ActiveTask = types.TaskPointer{}
 	state.NextTask = types.TaskPointer{}
 	state.Metrics = types.Metrics{}
+	state.PreviousAttempts = nil
 
 	return true, nil
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/robertgumeny/doug/internal/build"
 	"github.com/robertgumeny/doug/internal/config"
 	"github.com/robertgumeny/doug/internal/log"
+	"github.com/robertgumeny/doug/internal/shellargs"
 )
 
 // CheckDependencies verifies that all binaries required by the orchestrator
@@ -15,20 +16,39 @@ import (
 //   - The agent command (e.g., "claude") from cfg.AgentCommand
 //   - "git"
 //   - The language toolchain: "go" when cfg.BuildSystem is "go" (default),
-//     or "npm" when cfg.BuildSystem is "npm"
+//     "npm" or "cargo" when cfg.BuildSystem names them, the Python interpreter
+//     plus the python_type_check executable for "python" (resolved in the
+//     projectRoot virtualenv first, see build.PythonTool), or the executable
+//     of every configured build_commands line when cfg.BuildSystem is "command"
+//   - The lint_command executable when lint is enabled and lint_command is set
 //
 // Returns a descriptive error listing every missing binary; nil if all are
 // present.
-func CheckDependencies(cfg *config.OrchestratorConfig) error {
+func CheckDependencies(cfg *config.OrchestratorConfig, projectRoot string) error {
 	required := []string{strings.Fields(cfg.AgentCommand)[0], "git"}
 
 	switch cfg.BuildSystem {
 	case "npm":
 		required = append(required, "npm")
+	case "cargo":
+		required = append(required, "cargo")
+	case "python":
+		required = append(required, build.PythonBinary())
+		if parts, err := shellargs.Split(strings.TrimSpace(cfg.PythonTypeCheck)); err == nil && len(parts) > 0 {
+			required = append(required, build.PythonTool(projectRoot, parts[0]))
+		}
+	case "command":
+		required = append(required, build.CommandBinaries(cfg.BuildCommands)...)
 	default:
 		required = append(required, "go")
 	}
 
+	if config.LintEnabled(cfg.Lint) {
+		if parts, err := shellargs.Split(strings.TrimSpace(cfg.LintCommand)); err == nil && len(parts) > 0 {
+			required = append(required, parts[0])
+		}
+	}
+
 	var missing []string
 	for _, bin := range required {
 		if _, err := exec.LookPath(bin); err != nil {
@@ -53,7 +73,8 @@ func CheckDependencies(cfg *config.OrchestratorConfig) error {
 //
 // Any build or test failure returns an error that already includes the last 50
 // lines of output (embedded by the BuildSystem implementations). The caller
-// must treat this as a fatal-level error and exit.
+// must treat this as a fatal-level error and exit. Lint runs last according to
+// cfg.Lint: a failure is fatal under "enforce" and only logged under "warn".
 func EnsureProjectReady(buildSys build.BuildSystem, cfg *config.OrchestratorConfig) error {
 	if !buildSys.IsInitialized() {
 		log.Warning(fmt.Sprintf("project is not initialized (build system: %s) — "+
@@ -73,5 +94,17 @@ func EnsureProjectReady(buildSys build.BuildSystem, cfg *config.OrchestratorConf
 	}
 	log.Success("pre-flight tests passed")
 
+	if config.LintEnabled(cfg.Lint) {
+		log.Info("running pre-flight lint check")
+		if err := buildSys.Lint(); err != nil {
+			if cfg.Lint == config.LintEnforce {
+				return fmt.Errorf("pre-flight lint failed (last 50 lines of output above):\n%w", err)
+			}
+			log.Warning(fmt.Sprintf("pre-flight lint failed (lint: warn — continuing):\n%v", err))
+		} else {
+			log.Success("pre-flight lint passed")
+		}
+	}
+
 	return nil
 }
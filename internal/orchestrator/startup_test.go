@@ -2,9 +2,14 @@ package orchestrator_test
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 
+	"github.com/robertgumeny/doug/internal/build"
 	"github.com/robertgumeny/doug/internal/config"
 	"github.com/robertgumeny/doug/internal/orchestrator"
 )
@@ -17,11 +22,13 @@ type mockBuildSys struct {
 	initialized bool
 	buildErr    error
 	testErr     error
+	lintErr     error
 }
 
 func (m *mockBuildSys) Install() error      { return nil }
 func (m *mockBuildSys) Build() error        { return m.buildErr }
 func (m *mockBuildSys) Test() error         { return m.testErr }
+func (m *mockBuildSys) Lint() error         { return m.lintErr }
 func (m *mockBuildSys) IsInitialized() bool { return m.initialized }
 
 // ---------------------------------------------------------------------------
@@ -35,7 +42,7 @@ func TestCheckDependencies_MissingBinary_ReturnsError(t *testing.T) {
 		BuildSystem:  "go",
 	}
 
-	err := orchestrator.CheckDependencies(cfg)
+	err := orchestrator.CheckDependencies(cfg, t.TempDir())
 
 	if err == nil {
 		t.Fatal("expected non-nil error for missing binary, got nil")
@@ -48,7 +55,7 @@ func TestCheckDependencies_MissingBinary_ErrorContainsBinaryName(t *testing.T) {
 		BuildSystem:  "go",
 	}
 
-	err := orchestrator.CheckDependencies(cfg)
+	err := orchestrator.CheckDependencies(cfg, t.TempDir())
 
 	if err == nil {
 		t.Fatal("expected non-nil error")
@@ -69,7 +76,7 @@ func TestCheckDependencies_GitAlwaysRequired(t *testing.T) {
 
 	// git is on PATH; go is on PATH (we're in a Go test); so error should be nil
 	// UNLESS the test machine lacks "go" — in that case skip.
-	err := orchestrator.CheckDependencies(cfg)
+	err := orchestrator.CheckDependencies(cfg, t.TempDir())
 	if err != nil && strings.Contains(err.Error(), "go") {
 		t.Skip("go toolchain not on PATH in this test environment")
 	}
@@ -82,7 +89,7 @@ func TestCheckDependencies_NpmBuildSystem_ChecksNpm(t *testing.T) {
 		BuildSystem:  "npm",
 	}
 
-	err := orchestrator.CheckDependencies(cfg)
+	err := orchestrator.CheckDependencies(cfg, t.TempDir())
 	// npm may or may not be present; what matters is the function doesn't panic.
 	// If npm is missing the error should mention it.
 	if err != nil && !strings.Contains(err.Error(), "npm") {
@@ -90,6 +97,85 @@ func TestCheckDependencies_NpmBuildSystem_ChecksNpm(t *testing.T) {
 	}
 }
 
+func TestCheckDependencies_CargoBuildSystem_ChecksCargo(t *testing.T) {
+	cfg := &config.OrchestratorConfig{
+		AgentCommand: "git",
+		BuildSystem:  "cargo",
+	}
+
+	err := orchestrator.CheckDependencies(cfg, t.TempDir())
+	// cargo may or may not be present; if it is missing the error must name it.
+	if err != nil && !strings.Contains(err.Error(), "cargo") {
+		t.Errorf("expected error to mention cargo when cargo is missing, got: %q", err.Error())
+	}
+}
+
+func TestCheckDependencies_PythonBuildSystem_ChecksTypeChecker(t *testing.T) {
+	cfg := &config.OrchestratorConfig{
+		AgentCommand:    "git",
+		BuildSystem:     "python",
+		PythonTypeCheck: "missing-typechecker-333 src",
+	}
+
+	err := orchestrator.CheckDependencies(cfg, t.TempDir())
+
+	if err == nil {
+		t.Fatal("expected non-nil error for missing type checker")
+	}
+	if !strings.Contains(err.Error(), "missing-typechecker-333") {
+		t.Errorf("error should list the missing type checker, got: %q", err.Error())
+	}
+}
+
+func TestCheckDependencies_PythonBuildSystem_FindsTypeCheckerInVirtualenv(t *testing.T) {
+	if _, err := exec.LookPath(build.PythonBinary()); err != nil {
+		t.Skip("python not on PATH")
+	}
+	root := t.TempDir()
+	tool := filepath.Join(root, ".venv", "bin", "venv-typechecker-444")
+	if runtime.GOOS == "windows" {
+		tool = filepath.Join(root, ".venv", "Scripts", "venv-typechecker-444.exe")
+	}
+	if err := os.MkdirAll(filepath.Dir(tool), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(tool, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.OrchestratorConfig{
+		AgentCommand:    "git",
+		BuildSystem:     "python",
+		PythonTypeCheck: "venv-typechecker-444 src",
+	}
+
+	if err := orchestrator.CheckDependencies(cfg, root); err != nil {
+		t.Errorf("type checker installed in .venv should satisfy the check, got: %v", err)
+	}
+}
+
+func TestCheckDependencies_CommandBuildSystem_ChecksConfiguredBinaries(t *testing.T) {
+	cfg := &config.OrchestratorConfig{
+		AgentCommand: "git",
+		BuildSystem:  "command",
+		BuildCommands: config.BuildCommandsConfig{
+			Build: "missing-builder-222 build",
+			Test:  "git --version",
+		},
+	}
+
+	err := orchestrator.CheckDependencies(cfg, t.TempDir())
+
+	if err == nil {
+		t.Fatal("expected non-nil error for missing build command binary")
+	}
+	if !strings.Contains(err.Error(), "missing-builder-222") {
+		t.Errorf("error should list the missing build binary, got: %q", err.Error())
+	}
+	if strings.Contains(err.Error(), "go") {
+		t.Errorf("command build system must not require go, got: %q", err.Error())
+	}
+}
+
 func TestCheckDependencies_MultipleMissing_ErrorListsAll(t *testing.T) {
 	cfg := &config.OrchestratorConfig{
 		AgentCommand: "missing-agent-111",
@@ -98,7 +184,7 @@ func TestCheckDependencies_MultipleMissing_ErrorListsAll(t *testing.T) {
 
 	// Inject a known-missing agent — we can't guarantee go is missing too,
 	// but we can at least verify the agent is listed.
-	err := orchestrator.CheckDependencies(cfg)
+	err := orchestrator.CheckDependencies(cfg, t.TempDir())
 
 	if err == nil {
 		t.Fatal("expected non-nil error")
@@ -138,6 +224,30 @@ func TestEnsureProjectReady_NotInitialized_DoesNotRunBuild(t *testing.T) {
 	}
 }
 
+func TestEnsureProjectReady_LintModes(t *testing.T) {
+	lintErr := fmt.Errorf("golangci-lint: 3 issues")
+	tests := []struct {
+		mode    string
+		wantErr bool
+	}{
+		{config.LintOff, false},
+		{config.LintWarn, false},
+		{config.LintEnforce, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.mode, func(t *testing.T) {
+			bs := &mockBuildSys{initialized: true, lintErr: lintErr}
+			cfg := &config.OrchestratorConfig{BuildSystem: "go", Lint: tc.mode}
+
+			err := orchestrator.EnsureProjectReady(bs, cfg)
+
+			if (err != nil) != tc.wantErr {
+				t.Errorf("lint %s: err = %v, wantErr %v", tc.mode, err, tc.wantErr)
+			}
+		})
+	}
+}
+
 func TestEnsureProjectReady_BuildFails_ReturnsError(t *testing.T) {
 	bs := &mockBuildSys{
 		initialized: true,
@@ -1,7 +1,9 @@
 package orchestrator
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/robertgumeny/doug/internal/types"
 )
@@ -11,13 +13,16 @@ import (
 //
 // Selection order for active task:
 //  1. First IN_PROGRESS task (orchestrator was interrupted mid-task)
-//  2. First TODO task (normal forward progress)
+//  2. First TODO task whose depends_on entries are all DONE
 //
-// If no user tasks remain (all DONE or BLOCKED) and kbEnabled is true,
-// a synthetic KB_UPDATE documentation task is injected as the active task.
+// If no user task can run and some are BLOCKED, the epic is not complete: the
+// active task becomes a manual_review pointer at the first BLOCKED task (see
+// BlockedTasks), which doug run refuses to start on. If every user task is
+// DONE and kbEnabled is true, a synthetic KB_UPDATE documentation task is
+// injected as the active task.
 //
-// next_task is set to the first TODO task that appears after the selected
-// active task in the list.
+// next_task is set to the first TODO task after the selected active task in
+// the list that will be ready once the active task is DONE.
 func InitializeTaskPointers(state *types.ProjectState, tasks *types.Tasks, kbEnabled bool) {
 	// Don't re-initialize when a synthetic task is already active.
 	// Synthetic tasks (bugfix, documentation) are never in tasks.yaml;
@@ -26,27 +31,19 @@ func InitializeTaskPointers(state *types.ProjectState, tasks *types.Tasks, kbEna
 		return
 	}
 
-	// 1. Find active: prefer IN_PROGRESS, then first TODO.
-	var activeTask *types.Task
-	for i := range tasks.Epic.Tasks {
-		if tasks.Epic.Tasks[i].Status == types.StatusInProgress {
-			activeTask = &tasks.Epic.Tasks[i]
-			break
-		}
-	}
-	if activeTask == nil {
-		for i := range tasks.Epic.Tasks {
-			if tasks.Epic.Tasks[i].Status == types.StatusTODO {
-				activeTask = &tasks.Epic.Tasks[i]
-				break
-			}
-		}
-	}
+	// 1. Find active: prefer IN_PROGRESS, then first ready TODO.
+	id, taskType := FindNextActiveTask(tasks)
 
-	// 2. No user tasks remain — inject KB_UPDATE if enabled.
-	if activeTask == nil {
+	// 2. No user tasks can run — stop for review of BLOCKED work, or inject
+	// KB_UPDATE if enabled and every task is DONE.
+	if id == "" {
 		state.NextTask = types.TaskPointer{}
-		if kbEnabled {
+		if blocked := BlockedTasks(tasks); len(blocked) > 0 {
+			state.ActiveTask = types.TaskPointer{
+				Type: types.TaskTypeManualReview,
+				ID:   blocked[0],
+			}
+		} else if kbEnabled && len(StalledTasks(tasks)) == 0 {
 			state.ActiveTask = types.TaskPointer{
 				Type: types.TaskTypeDocumentation,
 				ID:   "KB_UPDATE",
@@ -56,64 +53,59 @@ func InitializeTaskPointers(state *types.ProjectState, tasks *types.Tasks, kbEna
 	}
 
 	state.ActiveTask = types.TaskPointer{
-		Type: activeTask.Type,
-		ID:   activeTask.ID,
+		Type: taskType,
+		ID:   id,
 	}
 
-	// 3. Find next: first TODO task that appears after the active task.
-	foundActive := false
-	state.NextTask = types.TaskPointer{}
-	for _, t := range tasks.Epic.Tasks {
-		if foundActive && t.Status == types.StatusTODO {
-			state.NextTask = types.TaskPointer{Type: t.Type, ID: t.ID}
-			break
-		}
-		if t.ID == activeTask.ID {
-			foundActive = true
-		}
-	}
+	// 3. Find next: first TODO task after the active task that it unblocks.
+	state.NextTask = findNextAfter(tasks, id)
 }
 
 // AdvanceToNextTask promotes state.NextTask to state.ActiveTask and locates
 // the new NextTask from the remaining TODO tasks.
 //
-// Returns false immediately (without modifying state) if NextTask is empty —
-// meaning there is no task to advance to. Returns true after a successful
-// promotion, even if no further next task exists after the new active.
+// If NextTask is empty or is a user-defined task whose dependencies are not
+// all DONE, the first ready TODO task anywhere in the list is promoted
+// instead, so that independent tasks are still picked up around BLOCKED work.
+//
+// Returns false (without modifying state) when there is no task to advance
+// to. Returns true after a successful promotion, even if no further next task
+// exists after the new active.
 //
 // Attempts on the newly promoted active task is reset to 0; the caller must
 // call IncrementAttempts at the start of the next iteration.
 func AdvanceToNextTask(state *types.ProjectState, tasks *types.Tasks) bool {
-	if state.NextTask.ID == "" {
+	candidate := state.NextTask
+	if candidate.ID == "" || !canPromote(tasks, candidate) {
+		candidate = types.TaskPointer{}
+		for _, t := range tasks.Epic.Tasks {
+			if t.ID != state.ActiveTask.ID && t.Status == types.StatusTODO && dependenciesMet(tasks, t) {
+				candidate = types.TaskPointer{Type: t.Type, ID: t.ID}
+				break
+			}
+		}
+	}
+	if candidate.ID == "" {
 		return false
 	}
 
-	// Promote next → active; reset attempt counter.
+	// Promote candidate → active; reset attempt counter.
 	state.ActiveTask = types.TaskPointer{
-		Type:     state.NextTask.Type,
-		ID:       state.NextTask.ID,
+		Type:     candidate.Type,
+		ID:       candidate.ID,
 		Attempts: 0,
 	}
 
-	// Find new next: first TODO task that appears after the newly active task.
-	foundActive := false
-	state.NextTask = types.TaskPointer{}
-	for _, t := range tasks.Epic.Tasks {
-		if foundActive && t.Status == types.StatusTODO {
-			state.NextTask = types.TaskPointer{Type: t.Type, ID: t.ID}
-			break
-		}
-		if t.ID == state.ActiveTask.ID {
-			foundActive = true
-		}
-	}
+	// Find new next: first TODO task after the newly active task that it unblocks.
+	state.NextTask = findNextAfter(tasks, state.ActiveTask.ID)
 
 	return true
 }
 
 // FindNextActiveTask returns the ID and TaskType of the next task that should
 // become active, scanning the task list in order. IN_PROGRESS tasks are
-// preferred over TODO tasks (supporting orchestrator-restart recovery).
+// preferred over TODO tasks (supporting orchestrator-restart recovery). A TODO
+// task is only eligible once every task in its depends_on list is DONE.
 //
 // Returns empty strings when no active task candidates remain.
 func FindNextActiveTask(tasks *types.Tasks) (string, types.TaskType) {
@@ -123,13 +115,116 @@ func FindNextActiveTask(tasks *types.Tasks) (string, types.TaskType) {
 		}
 	}
 	for _, t := range tasks.Epic.Tasks {
-		if t.Status == types.StatusTODO {
+		if t.Status == types.StatusTODO && dependenciesMet(tasks, t) {
 			return t.ID, t.Type
 		}
 	}
 	return "", ""
 }
 
+// StalledTasks returns the IDs of TODO tasks that cannot be activated because
+// no task is IN_PROGRESS and none of the remaining TODO tasks has all of its
+// dependencies DONE. In a valid dependency graph this only happens when the
+// remaining work depends (directly or transitively) on a BLOCKED task.
+//
+// Returns nil when progress is possible or no TODO tasks remain.
+func StalledTasks(tasks *types.Tasks) []string {
+	if id, _ := FindNextActiveTask(tasks); id != "" {
+		return nil
+	}
+	var stalled []string
+	for _, t := range tasks.Epic.Tasks {
+		if t.Status == types.StatusTODO {
+			stalled = append(stalled, t.ID)
+		}
+	}
+	return stalled
+}
+
+// BlockedTasks returns the IDs of BLOCKED tasks in list order, or nil when
+// there are none. An epic with BLOCKED tasks is never complete: once nothing
+// else can run it needs manual review (doug unblock).
+func BlockedTasks(tasks *types.Tasks) []string {
+	var blocked []string
+	for _, t := range tasks.Epic.Tasks {
+		if t.Status == types.StatusBlocked {
+			blocked = append(blocked, t.ID)
+		}
+	}
+	return blocked
+}
+
+// BlockedTasksError returns the fatal error for an epic where nothing can run
+// until its BLOCKED tasks are reviewed, naming the TODO tasks that wait on
+// them.
+func BlockedTasksError(tasks *types.Tasks) error {
+	msg := fmt.Sprintf("blocked tasks %s need manual review — fix the cause, then run doug unblock <task-id>",
+		strings.Join(BlockedTasks(tasks), ", "))
+	if stalled := StalledTasks(tasks); len(stalled) > 0 {
+		msg += fmt.Sprintf("; tasks %s depend on them and cannot run", strings.Join(stalled, ", "))
+	}
+	return errors.New(msg)
+}
+
+// findNextAfter returns a pointer to the first TODO task listed after activeID
+// whose dependencies are all DONE or satisfied by activeID itself, i.e. the
+// task that becomes ready once the active task completes. Returns the zero
+// value when no such task exists.
+func findNextAfter(tasks *types.Tasks, activeID string) types.TaskPointer {
+	foundActive := false
+	for _, t := range tasks.Epic.Tasks {
+		if foundActive && t.Status == types.StatusTODO && dependenciesMetAssuming(tasks, t, activeID) {
+			return types.TaskPointer{Type: t.Type, ID: t.ID}
+		}
+		if t.ID == activeID {
+			foundActive = true
+		}
+	}
+	return types.TaskPointer{}
+}
+
+// canPromote reports whether next may become the active task. Pointers that
+// are not found in tasks.yaml (synthetic tasks resumed after a bugfix) are
+// always promotable; user-defined tasks must be pending (TODO or IN_PROGRESS)
+// with all dependencies DONE.
+func canPromote(tasks *types.Tasks, next types.TaskPointer) bool {
+	for _, t := range tasks.Epic.Tasks {
+		if t.ID == next.ID {
+			pending := t.Status == types.StatusTODO || t.Status == types.StatusInProgress
+			return pending && dependenciesMet(tasks, t)
+		}
+	}
+	return true
+}
+
+// dependenciesMet reports whether every task in t.DependsOn is DONE.
+// Unknown dependency IDs are treated as unmet; ValidateTaskDependencies
+// rejects them before the loop starts.
+func dependenciesMet(tasks *types.Tasks, t types.Task) bool {
+	return dependenciesMetAssuming(tasks, t, "")
+}
+
+// dependenciesMetAssuming is dependenciesMet with doneID additionally treated
+// as DONE regardless of its current status.
+func dependenciesMetAssuming(tasks *types.Tasks, t types.Task, doneID string) bool {
+	for _, dep := range t.DependsOn {
+		if dep == doneID {
+			continue
+		}
+		met := false
+		for _, other := range tasks.Epic.Tasks {
+			if other.ID == dep {
+				met = other.Status == types.StatusDone
+				break
+			}
+		}
+		if !met {
+			return false
+		}
+	}
+	return true
+}
+
 // IncrementAttempts increments the Attempts counter on state.ActiveTask in
 // memory. The caller is responsible for persisting the updated state via
 // SaveState.
@@ -353,3 +353,123 @@ func TestUpdateTaskStatus_ToDone(t *testing.T) {
 		t.Errorf("T1 status: got %q, want DONE", tasks.Epic.Tasks[0].Status)
 	}
 }
+
+// ---------------------------------------------------------------------------
+// depends_on-aware selection
+// ---------------------------------------------------------------------------
+
+// dependencyTasks returns T1..T3 where T2 depends on T1 and T3 is independent.
+func dependencyTasks(statuses ...types.Status) *types.Tasks {
+	tasks := threeTaskTasks(statuses...)
+	tasks.Epic.Tasks[1].DependsOn = []string{"T1"}
+	return tasks
+}
+
+func TestFindNextActiveTask_SkipsTaskWithUnmetDependency(t *testing.T) {
+	tasks := dependencyTasks(types.StatusBlocked, types.StatusTODO, types.StatusTODO)
+
+	id, _ := orchestrator.FindNextActiveTask(tasks)
+
+	if id != "T3" {
+		t.Errorf("got %q, want %q (T2 waits on BLOCKED T1)", id, "T3")
+	}
+}
+
+func TestInitializeTaskPointers_NextIsUnblockedByActive(t *testing.T) {
+	state := &types.ProjectState{}
+	tasks := dependencyTasks(types.StatusTODO, types.StatusTODO, types.StatusTODO)
+
+	orchestrator.InitializeTaskPointers(state, tasks, true)
+
+	if state.ActiveTask.ID != "T1" {
+		t.Errorf("ActiveTask.ID: got %q, want %q", state.ActiveTask.ID, "T1")
+	}
+	if state.NextTask.ID != "T2" {
+		t.Errorf("NextTask.ID: got %q, want %q (T2 only depends on the active task)", state.NextTask.ID, "T2")
+	}
+}
+
+func TestInitializeTaskPointers_StalledDoesNotInjectKB(t *testing.T) {
+	state := &types.ProjectState{
+		ActiveTask: types.TaskPointer{Type: types.TaskTypeManualReview, ID: "T1"},
+	}
+	tasks := dependencyTasks(types.StatusBlocked, types.StatusTODO, types.StatusDone)
+
+	orchestrator.InitializeTaskPointers(state, tasks, true)
+
+	if state.ActiveTask.ID == "KB_UPDATE" {
+		t.Error("KB_UPDATE must not be injected while TODO tasks wait on BLOCKED dependencies")
+	}
+	if got := orchestrator.StalledTasks(tasks); len(got) != 1 || got[0] != "T2" {
+		t.Errorf("StalledTasks: got %v, want [T2]", got)
+	}
+}
+
+func TestInitializeTaskPointers_OnlyBlockedLeft_SetsManualReview(t *testing.T) {
+	state := &types.ProjectState{}
+	tasks := threeTaskTasks(types.StatusDone, types.StatusBlocked, types.StatusDone)
+
+	orchestrator.InitializeTaskPointers(state, tasks, true)
+
+	if state.ActiveTask.Type != types.TaskTypeManualReview || state.ActiveTask.ID != "T2" {
+		t.Errorf("ActiveTask: got %s/%s, want manual_review/T2", state.ActiveTask.Type, state.ActiveTask.ID)
+	}
+	if state.NextTask.ID != "" {
+		t.Errorf("NextTask.ID: got %q, want empty", state.NextTask.ID)
+	}
+}
+
+func TestStalledTasks_NilWhenProgressPossible(t *testing.T) {
+	tasks := dependencyTasks(types.StatusTODO, types.StatusTODO, types.StatusTODO)
+
+	if got := orchestrator.StalledTasks(tasks); got != nil {
+		t.Errorf("StalledTasks: got %v, want nil", got)
+	}
+}
+
+func TestAdvanceToNextTask_FallsBackToEarlierReadyTask(t *testing.T) {
+	// T1 depends on T3, so T2 → T3 run first and T1 becomes ready last.
+	tasks := threeTaskTasks(types.StatusTODO, types.StatusDone, types.StatusDone)
+	tasks.Epic.Tasks[0].DependsOn = []string{"T3"}
+	state := &types.ProjectState{
+		ActiveTask: types.TaskPointer{Type: types.TaskTypeFeature, ID: "T3", Attempts: 1},
+	}
+
+	ok := orchestrator.AdvanceToNextTask(state, tasks)
+
+	if !ok {
+		t.Fatal("AdvanceToNextTask: expected true when an earlier task became ready")
+	}
+	if state.ActiveTask.ID != "T1" {
+		t.Errorf("ActiveTask.ID: got %q, want %q", state.ActiveTask.ID, "T1")
+	}
+}
+
+func TestAdvanceToNextTask_SkipsNextWithUnmetDependency(t *testing.T) {
+	tasks := dependencyTasks(types.StatusBlocked, types.StatusTODO, types.StatusTODO)
+	state := &types.ProjectState{
+		ActiveTask: types.TaskPointer{Type: types.TaskTypeFeature, ID: "T1"},
+		NextTask:   types.TaskPointer{Type: types.TaskTypeFeature, ID: "T2"},
+	}
+
+	orchestrator.AdvanceToNextTask(state, tasks)
+
+	if state.ActiveTask.ID != "T3" {
+		t.Errorf("ActiveTask.ID: got %q, want %q", state.ActiveTask.ID, "T3")
+	}
+}
+
+func TestAdvanceToNextTask_PromotesSyntheticNext(t *testing.T) {
+	tasks := allDoneTasks()
+	state := &types.ProjectState{
+		ActiveTask: types.TaskPointer{Type: types.TaskTypeBugfix, ID: "BUG-KB_UPDATE"},
+		NextTask:   types.TaskPointer{Type: types.TaskTypeDocumentation, ID: "KB_UPDATE"},
+	}
+
+	if !orchestrator.AdvanceToNextTask(state, tasks) {
+		t.Fatal("AdvanceToNextTask: expected true for synthetic next task")
+	}
+	if state.ActiveTask.ID != "KB_UPDATE" {
+		t.Errorf("ActiveTask.ID: got %q, want %q", state.ActiveTask.ID, "KB_UPDATE")
+	}
+}
@@ -0,0 +1,51 @@
+package orchestrator
+
+import (
+	"fmt"
+
+	"github.com/robertgumeny/doug/internal/types"
+)
+
+// UnblockTask returns the BLOCKED task taskID to TODO so the orchestrator can
+// try it again, discarding its retry history and recomputing the task
+// pointers with InitializeTaskPointers.
+//
+// A manual_review or KB_UPDATE pointer is replaced, since the unblocked task
+// must run before the epic can be reviewed or documented. Any other active
+// user task keeps its attempt count when it stays active.
+//
+// Returns an error if the epic is already complete, or taskID is unknown or
+// not BLOCKED. State and tasks are updated in memory only; the caller
+// persists them.
+func UnblockTask(state *types.ProjectState, tasks *types.Tasks, taskID string, kbEnabled bool) error {
+	if state.CurrentEpic.CompletedAt != nil && *state.CurrentEpic.CompletedAt != "" {
+		return fmt.Errorf("epic %s is already complete", state.CurrentEpic.ID)
+	}
+
+	var task *types.Task
+	for i := range tasks.Epic.Tasks {
+		if tasks.Epic.Tasks[i].ID == taskID {
+			task = &tasks.Epic.Tasks[i]
+			break
+		}
+	}
+	if task == nil {
+		return fmt.Errorf("task %q not found in tasks.yaml", taskID)
+	}
+	if task.Status != types.StatusBlocked {
+		return fmt.Errorf("task %s is %s, not %s", taskID, task.Status, types.StatusBlocked)
+	}
+
+	task.Status = types.StatusTODO
+	ClearAttempts(state, taskID)
+
+	prev := state.ActiveTask
+	if prev.Type == types.TaskTypeManualReview || prev.Type == types.TaskTypeDocumentation {
+		state.ActiveTask = types.TaskPointer{}
+	}
+	InitializeTaskPointers(state, tasks, kbEnabled)
+	if state.ActiveTask.ID == prev.ID && state.ActiveTask.Type == prev.Type && prev.ID != taskID {
+		state.ActiveTask.Attempts = prev.Attempts
+	}
+	return nil
+}
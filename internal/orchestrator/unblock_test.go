@@ -0,0 +1,88 @@
+package orchestrator_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/robertgumeny/doug/internal/orchestrator"
+	"github.com/robertgumeny/doug/internal/types"
+)
+
+func TestUnblockTask_FromManualReview(t *testing.T) {
+	tasks := threeTaskTasks(types.StatusDone, types.StatusBlocked, types.StatusTODO)
+	tasks.Epic.Tasks[2].DependsOn = []string{"T2"}
+	st := &types.ProjectState{
+		ActiveTask: types.TaskPointer{Type: types.TaskTypeManualReview, ID: "T2"},
+		PreviousAttempts: []types.AttemptRecord{
+			{TaskID: "T2", Attempt: 1, Reason: "agent reported FAILURE"},
+		},
+	}
+
+	if err := orchestrator.UnblockTask(st, tasks, "T2", true); err != nil {
+		t.Fatalf("UnblockTask: %v", err)
+	}
+	if tasks.Epic.Tasks[1].Status != types.StatusTODO {
+		t.Errorf("T2 status = %s, want TODO", tasks.Epic.Tasks[1].Status)
+	}
+	want := types.TaskPointer{Type: types.TaskTypeFeature, ID: "T2"}
+	if st.ActiveTask != want {
+		t.Errorf("ActiveTask = %+v, want %+v", st.ActiveTask, want)
+	}
+	if st.NextTask.ID != "T3" {
+		t.Errorf("NextTask = %+v, want T3", st.NextTask)
+	}
+	if len(st.PreviousAttempts) != 0 {
+		t.Errorf("PreviousAttempts = %+v, want cleared", st.PreviousAttempts)
+	}
+}
+
+func TestUnblockTask_ReplacesKBUpdate(t *testing.T) {
+	tasks := threeTaskTasks(types.StatusDone, types.StatusBlocked, types.StatusDone)
+	st := &types.ProjectState{
+		ActiveTask: types.TaskPointer{Type: types.TaskTypeDocumentation, ID: "KB_UPDATE", Attempts: 1},
+	}
+
+	if err := orchestrator.UnblockTask(st, tasks, "T2", true); err != nil {
+		t.Fatalf("UnblockTask: %v", err)
+	}
+	if st.ActiveTask.ID != "T2" || st.ActiveTask.Attempts != 0 {
+		t.Errorf("ActiveTask = %+v, want T2 with no attempts", st.ActiveTask)
+	}
+}
+
+func TestUnblockTask_KeepsAttemptsOfOtherActiveTask(t *testing.T) {
+	tasks := threeTaskTasks(types.StatusBlocked, types.StatusInProgress, types.StatusTODO)
+	st := &types.ProjectState{
+		ActiveTask: types.TaskPointer{Type: types.TaskTypeFeature, ID: "T2", Attempts: 2},
+	}
+
+	if err := orchestrator.UnblockTask(st, tasks, "T1", false); err != nil {
+		t.Fatalf("UnblockTask: %v", err)
+	}
+	if st.ActiveTask.ID != "T2" || st.ActiveTask.Attempts != 2 {
+		t.Errorf("ActiveTask = %+v, want T2 with 2 attempts", st.ActiveTask)
+	}
+}
+
+func TestUnblockTask_Errors(t *testing.T) {
+	completed := "2026-03-01T00:00:00Z"
+	tests := []struct {
+		name    string
+		state   *types.ProjectState
+		taskID  string
+		wantErr string
+	}{
+		{"unknown task", &types.ProjectState{}, "T9", "not found"},
+		{"not blocked", &types.ProjectState{}, "T1", "is DONE, not BLOCKED"},
+		{"epic complete", &types.ProjectState{CurrentEpic: types.EpicState{ID: "EPIC-X", CompletedAt: &completed}}, "T2", "already complete"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks := threeTaskTasks(types.StatusDone, types.StatusBlocked, types.StatusTODO)
+			err := orchestrator.UnblockTask(tt.state, tasks, tt.taskID, false)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("err = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package orchestrator
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/robertgumeny/doug/internal/types"
 )
@@ -101,6 +102,83 @@ func ValidateTaskTypes(tasks *types.Tasks) error {
 	return nil
 }
 
+// ---------------------------------------------------------------------------
+// ValidateTaskDependencies
+// ---------------------------------------------------------------------------
+
+// ValidateTaskDependencies checks the depends_on graph declared in tasks.yaml.
+//
+// It returns an error when:
+//   - a task lists its own ID in depends_on
+//   - a depends_on entry does not match any task ID in the epic
+//   - the dependencies form a cycle (the error names every task in the cycle)
+//
+// A valid graph guarantees that, unless a task is BLOCKED, at least one TODO
+// task is always ready to run.
+func ValidateTaskDependencies(tasks *types.Tasks) error {
+	index := make(map[string]types.Task, len(tasks.Epic.Tasks))
+	for _, t := range tasks.Epic.Tasks {
+		index[t.ID] = t
+	}
+
+	for _, t := range tasks.Epic.Tasks {
+		for _, dep := range t.DependsOn {
+			if dep == t.ID {
+				return fmt.Errorf("tasks.yaml: task %q depends on itself", t.ID)
+			}
+			if _, ok := index[dep]; !ok {
+				return fmt.Errorf("tasks.yaml: task %q depends on unknown task %q", t.ID, dep)
+			}
+		}
+	}
+
+	// Depth-first search with three colours: unvisited, on the current path,
+	// and finished. Reaching a task already on the path closes a cycle.
+	const (
+		unvisited = iota
+		onPath
+		finished
+	)
+	color := make(map[string]int, len(index))
+	var path []string
+
+	var visit func(id string) error
+	visit = func(id string) error {
+		color[id] = onPath
+		path = append(path, id)
+		for _, dep := range index[id].DependsOn {
+			switch color[dep] {
+			case onPath:
+				cycleStart := 0
+				for i, p := range path {
+					if p == dep {
+						cycleStart = i
+						break
+					}
+				}
+				cycle := append(append([]string{}, path[cycleStart:]...), dep)
+				return fmt.Errorf("tasks.yaml: dependency cycle detected: %s", strings.Join(cycle, " -> "))
+			case unvisited:
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		path = path[:len(path)-1]
+		color[id] = finished
+		return nil
+	}
+
+	for _, t := range tasks.Epic.Tasks {
+		if color[t.ID] == unvisited {
+			if err := visit(t.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // ---------------------------------------------------------------------------
 // ValidateStateSync
 // ---------------------------------------------------------------------------
@@ -1,6 +1,7 @@
 package orchestrator_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/robertgumeny/doug/internal/orchestrator"
@@ -165,6 +166,63 @@ func TestValidateTaskTypes_DocumentationTypeReturnsError(t *testing.T) {
 	}
 }
 
+// ---------------------------------------------------------------------------
+// ValidateTaskDependencies
+// ---------------------------------------------------------------------------
+
+func dependencyGraph(deps map[string][]string, ids ...string) *types.Tasks {
+	tasks := &types.Tasks{}
+	for _, id := range ids {
+		tasks.Epic.Tasks = append(tasks.Epic.Tasks, types.Task{
+			ID: id, Type: types.TaskTypeFeature, Status: types.StatusTODO, DependsOn: deps[id],
+		})
+	}
+	return tasks
+}
+
+func TestValidateTaskDependencies_ValidDAG(t *testing.T) {
+	tasks := dependencyGraph(map[string][]string{
+		"T2": {"T1"},
+		"T3": {"T1", "T2"},
+	}, "T1", "T2", "T3")
+	if err := orchestrator.ValidateTaskDependencies(tasks); err != nil {
+		t.Errorf("ValidateTaskDependencies: unexpected error: %v", err)
+	}
+}
+
+func TestValidateTaskDependencies_UnknownID(t *testing.T) {
+	tasks := dependencyGraph(map[string][]string{"T2": {"T9"}}, "T1", "T2")
+	err := orchestrator.ValidateTaskDependencies(tasks)
+	if err == nil {
+		t.Fatal("expected error for unknown dependency, got nil")
+	}
+	if !strings.Contains(err.Error(), "T9") {
+		t.Errorf("error should name the unknown ID; got: %v", err)
+	}
+}
+
+func TestValidateTaskDependencies_SelfDependency(t *testing.T) {
+	tasks := dependencyGraph(map[string][]string{"T1": {"T1"}}, "T1")
+	if err := orchestrator.ValidateTaskDependencies(tasks); err == nil {
+		t.Error("expected error for self-dependency, got nil")
+	}
+}
+
+func TestValidateTaskDependencies_Cycle(t *testing.T) {
+	tasks := dependencyGraph(map[string][]string{
+		"T1": {"T3"},
+		"T2": {"T1"},
+		"T3": {"T2"},
+	}, "T1", "T2", "T3")
+	err := orchestrator.ValidateTaskDependencies(tasks)
+	if err == nil {
+		t.Fatal("expected error for dependency cycle, got nil")
+	}
+	if !strings.Contains(err.Error(), "cycle") {
+		t.Errorf("error should mention cycle; got: %v", err)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // ValidateStateSync
 // ---------------------------------------------------------------------------
@@ -0,0 +1,90 @@
+package orchestrator
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/robertgumeny/doug/internal/git"
+	"github.com/robertgumeny/doug/internal/log"
+)
+
+// DirtyTreePolicy selects what GuardWorkingTree does with uncommitted changes.
+type DirtyTreePolicy int
+
+const (
+	// DirtyTreeRefuse refuses to start and lists the changes (default).
+	DirtyTreeRefuse DirtyTreePolicy = iota
+	// DirtyTreeStash stashes the changes until the run ends (--stash).
+	DirtyTreeStash
+	// DirtyTreeCommitBaseline commits the changes before the run (--commit-baseline).
+	DirtyTreeCommitBaseline
+)
+
+// BaselineCommitMessage is the message of the commit DirtyTreeCommitBaseline
+// creates.
+const BaselineCommitMessage = "chore: commit baseline before doug run"
+
+// GuardWorkingTree protects uncommitted work outside .doug/ from the
+// rollbacks of the orchestration loop, which reset and clean the working
+// tree. When the tree is clean it does nothing. Otherwise, by policy:
+//   - DirtyTreeRefuse returns an error listing the changes.
+//   - DirtyTreeStash stashes them under a "doug run" stash and returns a
+//     restore func that pops it; the caller defers it until the run ends.
+//     The run may leave another branch checked out (on_epic_complete
+//     integration, a backlog rollover), so restore first checks out the
+//     branch the changes were stashed on.
+//   - DirtyTreeCommitBaseline commits them with BaselineCommitMessage.
+//
+// restore is nil unless changes were stashed. A restore that fails (e.g. on
+// a conflict, or when that branch cannot be checked out) logs the stash to
+// recover by hand; git keeps it.
+func GuardWorkingTree(projectRoot string, policy DirtyTreePolicy) (restore func(), err error) {
+	changes, err := git.UncommittedChanges(projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	if len(changes) == 0 {
+		return nil, nil
+	}
+
+	switch policy {
+	case DirtyTreeStash:
+		branch, err := git.CurrentBranch(projectRoot)
+		if err != nil {
+			return nil, fmt.Errorf("stash uncommitted changes: %w", err)
+		}
+		message := "doug run: stashed at " + time.Now().Format(time.RFC3339)
+		hash, err := git.StashChanges(projectRoot, message)
+		if err != nil {
+			return nil, fmt.Errorf("stash uncommitted changes: %w", err)
+		}
+		log.Info(fmt.Sprintf("stashed %d uncommitted change(s) as %q; they are restored when doug exits", len(changes), message))
+		return func() {
+			current, err := git.CurrentBranch(projectRoot)
+			if err == nil && current != branch {
+				if err = git.CheckoutBranch(projectRoot, branch); err == nil {
+					log.Info(fmt.Sprintf("checked out %s, where the changes were stashed", branch))
+				}
+			}
+			if err != nil {
+				log.Warning(fmt.Sprintf("could not return to %s to restore stashed changes — recover them there with: git stash apply %s\n%v", branch, hash, err))
+				return
+			}
+			if err := git.PopStash(projectRoot, hash); err != nil {
+				log.Warning(fmt.Sprintf("could not restore stashed changes — recover them with: git stash apply %s\n%v", hash, err))
+				return
+			}
+			log.Success("restored changes stashed before the run")
+		}, nil
+	case DirtyTreeCommitBaseline:
+		if err := git.CommitOutsideDoug(projectRoot, BaselineCommitMessage); err != nil {
+			return nil, fmt.Errorf("commit baseline: %w", err)
+		}
+		log.Info(fmt.Sprintf("committed %d uncommitted change(s) as a baseline", len(changes)))
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("working tree has uncommitted changes outside .doug/ that a rollback would discard:\n  %s\ncommit or stash them, or re-run with --stash or --commit-baseline",
+			strings.Join(changes, "\n  "))
+	}
+}
@@ -0,0 +1,146 @@
+package orchestrator_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/robertgumeny/doug/internal/orchestrator"
+)
+
+// dirtyRepo returns a git repository with one commit, a modified README.md
+// and an untracked notes.txt.
+func dirtyRepo(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	write := func(name, contents string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init")
+	git("config", "user.email", "test@example.com")
+	git("config", "user.name", "Test Agent")
+	write("README.md", "# test\n")
+	git("add", ".")
+	git("commit", "-m", "initial commit")
+	write("README.md", "# edited\n")
+	write("notes.txt", "notes\n")
+	return dir
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestGuardWorkingTree_Refuse_ListsChanges(t *testing.T) {
+	dir := dirtyRepo(t)
+
+	restore, err := orchestrator.GuardWorkingTree(dir, orchestrator.DirtyTreeRefuse)
+	if err == nil {
+		t.Fatal("expected an error for a dirty working tree")
+	}
+	if restore != nil {
+		t.Error("restore should be nil when refusing")
+	}
+	for _, want := range []string{"README.md", "notes.txt", "--stash", "--commit-baseline"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+	if got := readFile(t, filepath.Join(dir, "README.md")); got != "# edited\n" {
+		t.Errorf("README.md = %q; refusing must not touch the tree", got)
+	}
+}
+
+func TestGuardWorkingTree_Stash_RestoresOnExit(t *testing.T) {
+	dir := dirtyRepo(t)
+
+	restore, err := orchestrator.GuardWorkingTree(dir, orchestrator.DirtyTreeStash)
+	if err != nil {
+		t.Fatalf("GuardWorkingTree: %v", err)
+	}
+	if restore == nil {
+		t.Fatal("restore is nil after stashing")
+	}
+	if got := readFile(t, filepath.Join(dir, "README.md")); got != "# test\n" {
+		t.Errorf("README.md = %q, want the committed version while stashed", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); !os.IsNotExist(err) {
+		t.Error("notes.txt should be stashed")
+	}
+
+	restore()
+	if got := readFile(t, filepath.Join(dir, "README.md")); got != "# edited\n" {
+		t.Errorf("README.md = %q after restore, want the edit back", got)
+	}
+	if got := readFile(t, filepath.Join(dir, "notes.txt")); got != "notes\n" {
+		t.Errorf("notes.txt = %q after restore", got)
+	}
+}
+
+func TestGuardWorkingTree_Stash_RestoresOnStartingBranch(t *testing.T) {
+	dir := dirtyRepo(t)
+	git := func(args ...string) string {
+		t.Helper()
+		out, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	start := git("rev-parse", "--abbrev-ref", "HEAD")
+
+	restore, err := orchestrator.GuardWorkingTree(dir, orchestrator.DirtyTreeStash)
+	if err != nil {
+		t.Fatalf("GuardWorkingTree: %v", err)
+	}
+	// The run ends on another branch, e.g. after a rollover.
+	git("checkout", "-b", "feature/EPIC-2")
+	git("commit", "--allow-empty", "-m", "epic work")
+
+	restore()
+	if got := git("rev-parse", "--abbrev-ref", "HEAD"); got != start {
+		t.Errorf("checked out %q after restore, want the starting branch %q", got, start)
+	}
+	if got := readFile(t, filepath.Join(dir, "README.md")); got != "# edited\n" {
+		t.Errorf("README.md = %q after restore, want the edit back", got)
+	}
+	if got := git("stash", "list"); got != "" {
+		t.Errorf("stash should be dropped after restore, got %q", got)
+	}
+}
+
+func TestGuardWorkingTree_CommitBaseline(t *testing.T) {
+	dir := dirtyRepo(t)
+
+	restore, err := orchestrator.GuardWorkingTree(dir, orchestrator.DirtyTreeCommitBaseline)
+	if err != nil {
+		t.Fatalf("GuardWorkingTree: %v", err)
+	}
+	if restore != nil {
+		t.Error("restore should be nil after a baseline commit")
+	}
+	// The tree is clean now, so even the refuse policy passes.
+	if _, err := orchestrator.GuardWorkingTree(dir, orchestrator.DirtyTreeRefuse); err != nil {
+		t.Errorf("tree still dirty after baseline commit: %v", err)
+	}
+}
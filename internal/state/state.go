@@ -1,5 +1,7 @@
 // Package state provides atomic load and save operations for the two
-// orchestrator state files: project-state.yaml and tasks.yaml.
+// orchestrator state files, project-state.yaml and tasks.yaml, for the
+// per-epic archives under .doug/history/, and for the epic backlog queued in
+// .doug/epics/.
 //
 // All writes are atomic: data is marshalled to a .tmp file in the same
 // directory, then os.Rename replaces the target in a single kernel call.
@@ -10,6 +12,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sort"
 
 	"gopkg.in/yaml.v3"
 
@@ -95,6 +99,58 @@ func SaveTasks(path string, tasks *types.Tasks) error {
 	return AtomicWrite(path, data)
 }
 
+// SaveEpicHistory atomically writes h to {dir}/{epic ID}.yaml, creating dir
+// if needed. An existing archive for the same epic is replaced.
+func SaveEpicHistory(dir string, h *types.EpicHistory) error {
+	if h.Epic.ID == "" {
+		return errors.New("save epic history: epic ID is empty")
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create history directory %s: %w", dir, err)
+	}
+	data, err := yaml.Marshal(h)
+	if err != nil {
+		return fmt.Errorf("marshal epic history: %w", err)
+	}
+	return AtomicWrite(filepath.Join(dir, h.Epic.ID+".yaml"), data)
+}
+
+// LoadEpicHistory reads every {dir}/*.yaml archive, oldest epic first
+// (ordered by started_at, then ID). A missing dir yields an empty result.
+func LoadEpicHistory(dir string) ([]types.EpicHistory, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	history := make([]types.EpicHistory, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var h types.EpicHistory
+		if err := yaml.Unmarshal(data, &h); err != nil {
+			return nil, &ParseError{Path: path, Err: err}
+		}
+		history = append(history, h)
+	}
+	sort.SliceStable(history, func(i, j int) bool {
+		a, b := history[i].Epic, history[j].Epic
+		if a.StartedAt != b.StartedAt {
+			return a.StartedAt < b.StartedAt
+		}
+		return a.ID < b.ID
+	})
+	return history, nil
+}
+
+// ListBacklog returns the epic files queued in dir, in the order doug runs
+// them: every *.yaml file, sorted by name. Each file has the tasks.yaml format
+// and is read with LoadTasks. A missing dir yields an empty result.
+func ListBacklog(dir string) ([]string, error) {
+	return filepath.Glob(filepath.Join(dir, "*.yaml"))
+}
+
 // AtomicWrite writes data to path by first writing to path+".tmp",
 // then calling os.Rename to replace the final target atomically.
 func AtomicWrite(path string, data []byte) error {
@@ -301,3 +301,45 @@ func TestTasksRoundTrip(t *testing.T) {
 		})
 	}
 }
+
+// ---------------------------------------------------------------------------
+// EpicHistory tests
+// ---------------------------------------------------------------------------
+
+func TestEpicHistoryRoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "history")
+	completed := "2026-03-02T00:00:00Z"
+	older := &types.EpicHistory{
+		Epic: types.EpicState{ID: "EPIC-2", StartedAt: "2026-03-01T00:00:00Z", CompletedAt: &completed},
+		Metrics: types.Metrics{
+			TotalTasksCompleted:  1,
+			TotalDurationSeconds: 42,
+			Tasks:                []types.TaskMetric{{TaskID: "EPIC-2-001", Outcome: "success", DurationSeconds: 42}},
+		},
+	}
+	newer := &types.EpicHistory{Epic: types.EpicState{ID: "EPIC-10", StartedAt: "2026-03-05T00:00:00Z"}}
+
+	for _, h := range []*types.EpicHistory{newer, older} {
+		if err := state.SaveEpicHistory(dir, h); err != nil {
+			t.Fatalf("SaveEpicHistory(%s): %v", h.Epic.ID, err)
+		}
+	}
+
+	got, err := state.LoadEpicHistory(dir)
+	if err != nil {
+		t.Fatalf("LoadEpicHistory: %v", err)
+	}
+	if len(got) != 2 || got[0].Epic.ID != "EPIC-2" || got[1].Epic.ID != "EPIC-10" {
+		t.Fatalf("got %+v, want EPIC-2 then EPIC-10", got)
+	}
+	if got[0].Metrics.TotalDurationSeconds != 42 || len(got[0].Metrics.Tasks) != 1 {
+		t.Errorf("metrics not preserved: %+v", got[0].Metrics)
+	}
+}
+
+func TestLoadEpicHistoryMissingDir(t *testing.T) {
+	got, err := state.LoadEpicHistory(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || len(got) != 0 {
+		t.Errorf("LoadEpicHistory = %v, %v; want empty, nil", got, err)
+	}
+}
@@ -27,6 +27,16 @@ const (
 	OutcomeEpicComplete Outcome = "EPIC_COMPLETE"
 )
 
+// Confidence is how sure an agent says it is of a SUCCESS, reported in the
+// optional confidence field of a session result.
+type Confidence string
+
+const (
+	ConfidenceLow    Confidence = "low"
+	ConfidenceMedium Confidence = "medium"
+	ConfidenceHigh   Confidence = "high"
+)
+
 // TaskType classifies a task as user-defined or orchestrator-injected (synthetic).
 type TaskType string
 
@@ -49,11 +59,18 @@ func (t TaskType) IsSynthetic() bool {
 // ---------------------------------------------------------------------------
 
 // ProjectState mirrors the full structure of project-state.yaml.
+//
+// PreviousAttempts holds the rejected attempts of tasks that have not yet
+// completed, so a retry can be briefed on what went wrong last time.
+// HumanNotes holds guidance attached with doug unblock, kept for the same
+// lifetime.
 type ProjectState struct {
-	CurrentEpic EpicState   `yaml:"current_epic"`
-	ActiveTask  TaskPointer `yaml:"active_task"`
-	NextTask    TaskPointer `yaml:"next_task"`
-	Metrics     Metrics     `yaml:"metrics"`
+	CurrentEpic      EpicState       `yaml:"current_epic"`
+	ActiveTask       TaskPointer     `yaml:"active_task"`
+	NextTask         TaskPointer     `yaml:"next_task"`
+	Metrics          Metrics         `yaml:"metrics"`
+	PreviousAttempts []AttemptRecord `yaml:"previous_attempts,omitempty"`
+	HumanNotes       []HumanNote     `yaml:"human_notes,omitempty"`
 }
 
 // EpicState is the current_epic block in project-state.yaml.
@@ -76,17 +93,67 @@ type TaskPointer struct {
 
 // Metrics is the metrics block in project-state.yaml.
 type Metrics struct {
-	TotalTasksCompleted  int          `yaml:"total_tasks_completed"`
-	TotalDurationSeconds int          `yaml:"total_duration_seconds"`
-	Tasks                []TaskMetric `yaml:"tasks"`
+	TotalTasksCompleted  int            `yaml:"total_tasks_completed"`
+	TotalDurationSeconds int            `yaml:"total_duration_seconds"`
+	Tasks                []TaskMetric   `yaml:"tasks"`
+	Interventions        []Intervention `yaml:"interventions,omitempty"`
+}
+
+// Intervention records a manual change to task state made outside the
+// orchestration loop, such as doug unblock.
+type Intervention struct {
+	TaskID     string `yaml:"task_id"`
+	Action     string `yaml:"action"`
+	Note       string `yaml:"note,omitempty"`
+	RecordedAt string `yaml:"recorded_at"`
+}
+
+// EpicHistory is the archived record of a finished epic, written to
+// .doug/history/{epic}.yaml before an epic rollover resets project state.
+type EpicHistory struct {
+	Epic    EpicState `yaml:"epic"`
+	Metrics Metrics   `yaml:"metrics"`
 }
 
 // TaskMetric records the outcome of a single completed task.
+//
+// The remaining fields come from the agent's optional session self-report and
+// are empty when it gave none. FilesChanged is what git saw change, not what
+// the agent claimed; CriteriaMet and CriteriaTotal count the acceptance
+// criteria it reported.
 type TaskMetric struct {
 	TaskID          string `yaml:"task_id"`
 	Outcome         string `yaml:"outcome"`
 	DurationSeconds int    `yaml:"duration_seconds"`
 	CompletedAt     string `yaml:"completed_at"`
+
+	FilesChanged     []string   `yaml:"files_changed,omitempty"`
+	TestsAdded       []string   `yaml:"tests_added,omitempty"`
+	CriteriaMet      int        `yaml:"criteria_met,omitempty"`
+	CriteriaTotal    int        `yaml:"criteria_total,omitempty"`
+	Confidence       Confidence `yaml:"confidence,omitempty"`
+	NotesForNextTask string     `yaml:"notes_for_next_task,omitempty"`
+}
+
+// AttemptRecord describes one rejected attempt at a task.
+//
+// Reason is a one-line summary (e.g. "test verification failed"). Output is
+// the tail of the failing build/test/lint command, and FailureReport is the
+// agent's own .doug/ACTIVE_FAILURE.md; either may be empty.
+type AttemptRecord struct {
+	TaskID        string `yaml:"task_id"`
+	Attempt       int    `yaml:"attempt"`
+	Reason        string `yaml:"reason"`
+	Output        string `yaml:"output,omitempty"`
+	FailureReport string `yaml:"failure_report,omitempty"`
+}
+
+// HumanNote is guidance a person attached to a task with doug unblock --note.
+// It is shown in ACTIVE_TASK.md until the task is finished.
+type HumanNote struct {
+	TaskID  string `yaml:"task_id"`
+	Note    string `yaml:"note"`
+	AddedAt string `yaml:"added_at"`
 }
 
 // ---------------------------------------------------------------------------
@@ -111,13 +178,21 @@ type EpicDefinition struct {
 // loader for every task read from tasks.yaml, establishing the UserDefined vs
 // Synthetic distinction at the type level. Synthetic tasks (bugfix,
 // documentation) are orchestrator-injected; they never appear as Task values.
+//
+// DependsOn lists the IDs of tasks that must be DONE before this task can be
+// activated. An empty list means the task is ready as soon as it is TODO.
+//
+// AgentTimeoutSeconds, when > 0, overrides agent_timeout_seconds from
+// doug.yaml for this task only.
 type Task struct {
-	ID                 string   `yaml:"id"`
-	Type               TaskType `yaml:"type"`
-	Status             Status   `yaml:"status"`
-	Description        string   `yaml:"description"`
-	AcceptanceCriteria []string `yaml:"acceptance_criteria"`
-	UserDefined        bool     `yaml:"-"`
+	ID                  string   `yaml:"id"`
+	Type                TaskType `yaml:"type"`
+	Status              Status   `yaml:"status"`
+	Description         string   `yaml:"description"`
+	AcceptanceCriteria  []string `yaml:"acceptance_criteria"`
+	DependsOn           []string `yaml:"depends_on,omitempty"`
+	AgentTimeoutSeconds int      `yaml:"agent_timeout_seconds,omitempty"`
+	UserDefined         bool     `yaml:"-"`
 }
 
 // ---------------------------------------------------------------------------
@@ -125,11 +200,31 @@ type Task struct {
 // ---------------------------------------------------------------------------
 
 // SessionResult is parsed from the YAML front-matter of the agent's session
-// file. The orchestrator requires exactly these three fields; all other session
-// metadata (timestamps, file lists, test counts, etc.) is managed by the
-// orchestrator itself and is not part of the Go type contract.
+// file, or from JSON with the same field names (see agent.ReadSessionResult).
+// The orchestrator requires the first three fields; timestamps and
+// other session metadata are managed by the orchestrator itself and are not
+// part of the Go type contract.
+//
+// The rest is an optional self-report: the files the agent changed and the
+// tests it added (paths relative to the project root), how it fared against
+// each acceptance criterion, notes for whoever picks up the next task, and
+// its confidence. A SUCCESS that lists an unmet criterion is rejected.
 type SessionResult struct {
-	Outcome           Outcome  `yaml:"outcome"`
-	ChangelogEntry    string   `yaml:"changelog_entry"`
-	DependenciesAdded []string `yaml:"dependencies_added"`
+	Outcome           Outcome  `yaml:"outcome" json:"outcome"`
+	ChangelogEntry    string   `yaml:"changelog_entry" json:"changelog_entry"`
+	DependenciesAdded []string `yaml:"dependencies_added" json:"dependencies_added"`
+
+	FilesChanged       []string          `yaml:"files_changed,omitempty" json:"files_changed,omitempty"`
+	TestsAdded         []string          `yaml:"tests_added,omitempty" json:"tests_added,omitempty"`
+	AcceptanceCriteria []CriterionResult `yaml:"acceptance_criteria,omitempty" json:"acceptance_criteria,omitempty"`
+	NotesForNextTask   string            `yaml:"notes_for_next_task,omitempty" json:"notes_for_next_task,omitempty"`
+	Confidence         Confidence        `yaml:"confidence,omitempty" json:"confidence,omitempty"`
+}
+
+// CriterionResult is the agent's verdict on one acceptance criterion. A
+// criterion whose met field is omitted counts as unmet.
+type CriterionResult struct {
+	Criterion string `yaml:"criterion" json:"criterion"`
+	Met       bool   `yaml:"met" json:"met"`
+	Evidence  string `yaml:"evidence,omitempty" json:"evidence,omitempty"`
 }